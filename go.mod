@@ -5,4 +5,5 @@ go 1.22
 require (
     github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
     github.com/xuri/excelize/v2 v2.8.1
+    golang.org/x/crypto v0.19.0
 )
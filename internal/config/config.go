@@ -0,0 +1,98 @@
+// Package config — секреты: из окружения, файлов и каталога секретов.
+//
+// Токен бота, ключи и пароли берутся функцией Secret(name) по порядку:
+//
+//	NAME        — значение в переменной окружения;
+//	NAME_FILE   — путь к файлу со значением (Docker/Kubernetes secrets);
+//	SECRETS_DIR — каталог, где значение лежит в файле NAME
+//	              (по умолчанию /run/secrets, если он есть).
+//
+// Пробелы и перевод строки по краям файла отбрасываются. Загруженные
+// значения вычёркиваются из лога и из отчётов об ошибках: клиент Telegram
+// пишет токен в URL запроса, и он попадает в тексты сетевых ошибок.
+package config
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const defaultSecretsDir = "/run/secrets"
+
+// Каталог запуска: относительные пути к секретам считаются от него, даже
+// если песочница потом сменила рабочий каталог
+var startDir, _ = os.Getwd()
+
+func secretPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(startDir, path)
+}
+
+var (
+	secretsMu     sync.Mutex
+	secretsCache  = make(map[string]string)
+	secretsLoaded []string // значения для вычёркивания
+)
+
+func Secret(name string) string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if v, ok := secretsCache[name]; ok {
+		return v
+	}
+	v := lookupSecret(name)
+	secretsCache[name] = v
+	// Короткие значения не вычёркиваем — они совпадали бы с обычным текстом
+	if len(v) >= 8 {
+		secretsLoaded = append(secretsLoaded, v)
+	}
+	return v
+}
+
+func lookupSecret(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		b, err := os.ReadFile(secretPath(path))
+		if err != nil {
+			log.Printf("secrets: %s_FILE: %v", name, err)
+			return ""
+		}
+		return strings.TrimSpace(string(b))
+	}
+	dir := os.Getenv("SECRETS_DIR")
+	if dir == "" {
+		dir = defaultSecretsDir
+	}
+	if b, err := os.ReadFile(filepath.Join(secretPath(dir), name)); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	return ""
+}
+
+// Заменяет загруженные секреты в тексте на ***
+func Redact(s string) string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range secretsLoaded {
+		s = strings.ReplaceAll(s, v, "***")
+	}
+	return s
+}
+
+// Пишет в w, вычёркивая секреты
+type RedactingWriter struct{ W io.Writer }
+
+func (r RedactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.W.Write([]byte(Redact(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
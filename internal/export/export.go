@@ -0,0 +1,71 @@
+// Package export — сборка Excel-файлов для выгрузок.
+//
+// Пакет знает только о таблицах: что писать в строки и каким цветом их
+// заливать, решает вызывающий код.
+package export
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Строка отчёта по отметкам; Fill — цвет заливки (#RRGGBB) или пусто
+type ReportRow struct {
+	Date, Time, Name, Action, Location, Note, Unit string
+	Fill                                           string
+}
+
+var reportHeaders = []string{"Дата", "Время", "ФИО", "Действие", "Локация", "Примечание", "Подразделение"}
+
+// Отчёт по отметкам — один лист, строка на отметку
+func Report(rows []ReportRow) *excelize.File {
+	f := excelize.NewFile()
+	sheet := "Отчёт"
+	f.SetSheetName("Sheet1", sheet)
+	for i, h := range reportHeaders {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+	styles := make(map[string]int)
+	for idx, r := range rows {
+		values := []string{r.Date, r.Time, r.Name, r.Action, r.Location, r.Note, r.Unit}
+		for j, v := range values {
+			cell, _ := excelize.CoordinatesToCellName(j+1, idx+2)
+			f.SetCellValue(sheet, cell, v)
+		}
+		if r.Fill == "" {
+			continue
+		}
+		style, ok := styles[r.Fill]
+		if !ok {
+			style, _ = f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{r.Fill}, Pattern: 1}})
+			styles[r.Fill] = style
+		}
+		f.SetCellStyle(sheet, fmt.Sprintf("A%d", idx+2), fmt.Sprintf("G%d", idx+2), style)
+	}
+	f.SetColWidth(sheet, "A", "G", 18)
+	return f
+}
+
+// Простая таблица на одном листе: заголовок и строки; widths — ширина
+// колонок слева направо, недостающие остаются по умолчанию
+func Table(sheet string, header []string, rows [][]interface{}, widths ...float64) *excelize.File {
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", sheet)
+	for i, h := range header {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+	for i, row := range rows {
+		for j, v := range row {
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+2)
+			f.SetCellValue(sheet, cell, v)
+		}
+	}
+	for i, w := range widths {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		f.SetColWidth(sheet, col, col, w)
+	}
+	return f
+}
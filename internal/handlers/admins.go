@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Снятие админских прав (только главный админ) ---
+
+func handleDemoteAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	if !isRootAdmin(ctx, query.From.ID) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Только для главного админа"))
+		return
+	}
+	parts := strings.SplitN(query.Data, "_", 2)
+	uid, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || uid == rootAdminID(ctx) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	name := getUserName(ctx, uid, nil)
+	switch parts[0] {
+	case "demote":
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Снять все админские права с %s?", name))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Снять", fmt.Sprintf("demoteok_%d", uid)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+		))
+		bot.Send(msg)
+	case "demoteok":
+		before := getAdminRights(ctx, uid)
+		removeUserRows(ctx, adminsFile, uid)
+		writeAudit(ctx, query.From.ID, "demote_admin", fmt.Sprintf("%d %s, права: %s", uid, name, rightsList(before)))
+		recordRightsChange(ctx, query.From.ID, uid, before, nil)
+		updateUserCommands(ctx, bot, uid)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %s больше не админ.", name)))
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+// Коды включённых прав через запятую
+func rightsList(rights map[string]bool) string {
+	var codes []string
+	for _, r := range adminRights {
+		if rights[r.Code] {
+			codes = append(codes, r.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return "-"
+	}
+	return strings.Join(codes, ",")
+}
+
+// --- История изменения прав ---
+
+const rightsHistoryFile = "rights_history.csv" // время, кто, кому, было, стало
+
+const rightsHistoryShown = 20
+
+func init() {
+	backupFiles = append(backupFiles, rightsHistoryFile)
+}
+
+// Выбор в меню прав до нажатия «Сохранить»: ID пользователя -> права
+var pendingRights = make(map[int]map[string]bool)
+
+func editedRights(ctx context.Context, userID int) map[string]bool {
+	if r, ok := pendingRights[userID]; ok {
+		return r
+	}
+	r := getAdminRights(ctx, userID)
+	pendingRights[userID] = r
+	return r
+}
+
+func recordRightsChange(ctx context.Context, actorID, targetID int, before, after map[string]bool) {
+	appendCSV(ctx, rightsHistoryFile, []string{
+		clock.Now().Format(dateFormat), strconv.Itoa(actorID), strconv.Itoa(targetID),
+		rightsList(before), rightsList(after),
+	})
+}
+
+func sendRightsHistory(ctx context.Context, bot Sender, chatID int64) {
+	rows := readCSV(ctx, rightsHistoryFile)
+	if len(rows) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "📜 Изменений прав ещё не было."))
+		return
+	}
+	var b strings.Builder
+	b.WriteString("📜 История прав (последние записи):\n\n")
+	shown := 0
+	for i := len(rows) - 1; i >= 0 && shown < rightsHistoryShown; i-- {
+		row := rows[i]
+		if len(row) < 5 {
+			continue
+		}
+		actor, _ := strconv.Atoi(row[1])
+		target, _ := strconv.Atoi(row[2])
+		b.WriteString(fmt.Sprintf("%s\n👤 %s → %s\nбыло: %s\nстало: %s\n\n",
+			row[0], getUserName(ctx, actor, nil), getUserName(ctx, target, nil), row[3], row[4]))
+		shown++
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
+
+// --- Шаблоны ролей для меню прав ---
+
+var rolePresets = []struct {
+	Code   string
+	Name   string
+	Rights []string
+}{
+	{"duty", "🪖 Дежурный", []string{"summary", "notifications"}},
+	{"sergeant", "🎖 Старшина", []string{"summary", "manage_users", "edit_records", "notifications"}},
+	{"commander", "⭐️ Командир", []string{"summary", "export", "manage_users", "settings", "edit_records", "notifications"}},
+}
+
+func rolePresetRow(userID int) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, p := range rolePresets {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(p.Name, fmt.Sprintf("rpreset_%s_%d", p.Code, userID)))
+	}
+	return row
+}
+
+// rpreset_<шаблон>_<ID>: заменяет выбор в меню, сохраняется обычной кнопкой
+func handleRolePresetAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	parts := strings.Split(query.Data, "_")
+	if len(parts) != 3 {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	uid, _ := strconv.Atoi(parts[2])
+	for _, p := range rolePresets {
+		if p.Code != parts[1] {
+			continue
+		}
+		selected := make(map[string]bool)
+		for _, r := range p.Rights {
+			selected[r] = true
+		}
+		pendingRights[uid] = selected
+		sendRightsCheckboxMenu(ctx, bot, query.Message.Chat.ID, uid, selected)
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, p.Name+": не забудьте сохранить"))
+		return
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
@@ -0,0 +1,560 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/validate"
+)
+
+// --- Веб-админка /admin ---
+//
+// Личный состав, правка отметок и выгрузки в браузере. Вход по
+// одноразовому коду из команды /weblogin или через Telegram Login
+// (TELEGRAM_LOGIN=1, домен привязывается у @BotFather командой /setdomain).
+// Сессия живёт в памяти процесса, права те же, что и в боте.
+
+const (
+	webLoginCodeTTL    = 5 * time.Minute
+	webSessionTTL      = 12 * time.Hour
+	webLoginMaxFailed  = 10
+	webSessionCookie   = "tabel_session"
+	webTelegramAuthTTL = 24 * time.Hour
+)
+
+type webLoginCode struct {
+	AdminID int
+	Expires time.Time
+}
+
+type webSession struct {
+	AdminID int
+	CSRF    string
+	Expires time.Time
+}
+
+var (
+	webAuthMu      sync.Mutex
+	webLoginCodes  = make(map[string]webLoginCode)
+	webSessions    = make(map[string]webSession)
+	webLoginFailed int
+)
+
+func init() {
+	handleHTTP("/admin", webAdminAuth(webAdminUsers))
+	handleHTTP("/admin/user", webAdminAuth(webAdminUserAction))
+	handleHTTP("/admin/records", webAdminAuth(webAdminRecords))
+	handleHTTP("/admin/record", webAdminAuth(webAdminRecordAction))
+	handleHTTP("/admin/export.xlsx", webAdminAuth(webAdminExport))
+	handleHTTP("/admin/login", restrictIP(webAdminLogin))
+	handleHTTP("/admin/tglogin", restrictIP(webAdminTelegramLogin))
+	handleHTTP("/admin/logout", restrictIP(webAdminLogout))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// /weblogin — код для входа в веб-админку
+func sendWebLoginCode(ctx context.Context, bot Sender, chatID int64, adminID int) {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000000))
+	code := fmt.Sprintf("%06d", n.Int64())
+	webAuthMu.Lock()
+	for c, lc := range webLoginCodes {
+		if lc.AdminID == adminID || clock.Now().After(lc.Expires) {
+			delete(webLoginCodes, c)
+		}
+	}
+	webLoginCodes[code] = webLoginCode{adminID, clock.Now().Add(webLoginCodeTTL)}
+	webAuthMu.Unlock()
+	writeAudit(ctx, adminID, "web_login_code", "")
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"🔐 Код для входа в веб-админку: %s\n\nДействует %d минут, одноразовый. Страница входа: /admin",
+		code, int(webLoginCodeTTL.Minutes()))))
+}
+
+// Неверные коды считаются вместе: после webLoginMaxFailed ошибок все
+// выданные коды сгорают, перебор не успевает
+func redeemWebLoginCode(code string) (int, bool) {
+	webAuthMu.Lock()
+	defer webAuthMu.Unlock()
+	lc, ok := webLoginCodes[code]
+	if !ok || clock.Now().After(lc.Expires) {
+		webLoginFailed++
+		if webLoginFailed >= webLoginMaxFailed {
+			webLoginCodes = make(map[string]webLoginCode)
+			webLoginFailed = 0
+		}
+		return 0, false
+	}
+	delete(webLoginCodes, code)
+	return lc.AdminID, true
+}
+
+func startWebSession(w http.ResponseWriter, r *http.Request, adminID int) {
+	ctx := r.Context()
+	id := randomHex(32)
+	webAuthMu.Lock()
+	for sid, s := range webSessions {
+		if clock.Now().After(s.Expires) {
+			delete(webSessions, sid)
+		}
+	}
+	webSessions[id] = webSession{adminID, randomHex(16), clock.Now().Add(webSessionTTL)}
+	webAuthMu.Unlock()
+	http.SetCookie(w, &http.Cookie{
+		Name:     webSessionCookie,
+		Value:    id,
+		Path:     httpPath("/admin"),
+		MaxAge:   int(webSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	writeAudit(ctx, adminID, "web_login", r.RemoteAddr)
+	http.Redirect(w, r, httpPath("/admin"), http.StatusSeeOther)
+}
+
+func currentWebSession(r *http.Request) (webSession, bool) {
+	c, err := r.Cookie(webSessionCookie)
+	if err != nil {
+		return webSession{}, false
+	}
+	webAuthMu.Lock()
+	defer webAuthMu.Unlock()
+	s, ok := webSessions[c.Value]
+	if !ok || clock.Now().After(s.Expires) {
+		delete(webSessions, c.Value)
+		return webSession{}, false
+	}
+	return s, true
+}
+
+type webAdminHandler func(w http.ResponseWriter, r *http.Request, s webSession)
+
+// Сессия обязательна, админ должен оставаться админом; POST — только с CSRF-токеном
+func webAdminAuth(next webAdminHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ipAllowed(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		ctx := r.Context()
+		s, ok := currentWebSession(r)
+		if !ok || !hasRight(ctx, s.AdminID, rightAnyAdmin) || isBanned(ctx, s.AdminID) {
+			http.Redirect(w, r, httpPath("/admin/login"), http.StatusSeeOther)
+			return
+		}
+		if r.Method == http.MethodPost && !hmac.Equal([]byte(r.FormValue("csrf")), []byte(s.CSRF)) {
+			http.Error(w, "bad csrf token", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Frame-Options", "DENY")
+		next(w, r, s)
+	}
+}
+
+func webAdminLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method == http.MethodPost {
+		code := strings.TrimSpace(r.FormValue("code"))
+		if adminID, ok := redeemWebLoginCode(code); ok && hasRight(ctx, adminID, rightAnyAdmin) && !isBanned(ctx, adminID) {
+			startWebSession(w, r, adminID)
+			return
+		}
+		renderWebAdmin(w, "login", webLoginPage{Error: "Код неверный или устарел. Запросите новый: /weblogin"})
+		return
+	}
+	page := webLoginPage{}
+	if os.Getenv("TELEGRAM_LOGIN") == "1" && webAppBot != nil {
+		page.BotName = webAppBot.BotUsername()
+	}
+	renderWebAdmin(w, "login", page)
+}
+
+func webAdminTelegramLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	adminID, ok := validate.Login(r.URL.Query(), botToken, clock.Now(), webTelegramAuthTTL)
+	if !ok || !hasRight(ctx, adminID, rightAnyAdmin) || isBanned(ctx, adminID) {
+		renderWebAdmin(w, "login", webLoginPage{Error: "Вход через Telegram не удался или у вас нет прав администратора."})
+		return
+	}
+	startWebSession(w, r, adminID)
+}
+
+func webAdminLogout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(webSessionCookie); err == nil {
+		webAuthMu.Lock()
+		delete(webSessions, c.Value)
+		webAuthMu.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: webSessionCookie, Path: httpPath("/admin"), MaxAge: -1})
+	http.Redirect(w, r, httpPath("/admin/login"), http.StatusSeeOther)
+}
+
+// --- Страницы ---
+
+type webLoginPage struct {
+	Error   string
+	BotName string
+}
+
+type webAdminPage struct {
+	Admin   string
+	CSRF    string
+	Notice  string
+	Rights  map[string]bool
+	Users   []webAdminUser
+	Units   []string
+	User    *webAdminUser
+	Records []webAdminRecord
+	Actions []string
+	Days    int
+}
+
+type webAdminUser struct {
+	ID       int
+	Name     string
+	Unit     string
+	Status   string
+	Archived bool
+	Banned   bool
+}
+
+type webAdminRecord struct {
+	DT       string
+	Time     string
+	Action   string
+	Location string
+}
+
+func newWebAdminPage(s webSession, r *http.Request) webAdminPage {
+	ctx := r.Context()
+	return webAdminPage{
+		Admin:  capitalizeName(getUserName(ctx, s.AdminID, nil)),
+		CSRF:   s.CSRF,
+		Notice: r.URL.Query().Get("notice"),
+		Rights: map[string]bool{
+			"manage_users": hasRight(ctx, s.AdminID, "manage_users"),
+			"edit_records": hasRight(ctx, s.AdminID, "edit_records"),
+			"export":       hasRight(ctx, s.AdminID, "export"),
+		},
+	}
+}
+
+// Весь личный состав в зоне админа, включая архивных
+func webAdminVisibleUsers(ctx context.Context, adminID int) []webAdminUser {
+	units := userUnits(ctx)
+	scope := adminScope(ctx, adminID)
+	var out []webAdminUser
+	for _, u := range getAllUsers(ctx) {
+		id := strconv.Itoa(u.ID)
+		if scope != "" && units[id] != scope {
+			continue
+		}
+		status := "нет отметок"
+		if row := findLastRow(ctx, id); row != nil {
+			status = row[3] + " " + row[0]
+			if row[3] == "Убыл" {
+				status += ", " + cleanLocation(row[4])
+			}
+		}
+		out = append(out, webAdminUser{u.ID, capitalizeName(u.Name), units[id], status, u.Archived, isBanned(ctx, u.ID)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func webAdminFindUser(ctx context.Context, adminID, uid int) *webAdminUser {
+	for _, u := range webAdminVisibleUsers(ctx, adminID) {
+		if u.ID == uid {
+			return &u
+		}
+	}
+	return nil
+}
+
+func webAdminUsers(w http.ResponseWriter, r *http.Request, s webSession) {
+	ctx := r.Context()
+	page := newWebAdminPage(s, r)
+	page.Users = webAdminVisibleUsers(ctx, s.AdminID)
+	page.Units = loadUnits(ctx)
+	renderWebAdmin(w, "users", page)
+}
+
+func webAdminRedirect(w http.ResponseWriter, r *http.Request, path, notice string) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	http.Redirect(w, r, httpPath(path)+sep+"notice="+template.URLQueryEscaper(notice), http.StatusSeeOther)
+}
+
+// POST /admin/user: op = rename | unit | archive | unarchive | ban | unban
+func webAdminUserAction(w http.ResponseWriter, r *http.Request, s webSession) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost || !hasRight(ctx, s.AdminID, "manage_users") {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	uid, _ := strconv.Atoi(r.FormValue("uid"))
+	u := webAdminFindUser(ctx, s.AdminID, uid)
+	if u == nil || uid == rootAdminID(ctx) {
+		webAdminRedirect(w, r, "/admin", "Пользователь недоступен")
+		return
+	}
+	notice := ""
+	switch r.FormValue("op") {
+	case "rename":
+		name, ok := validate.Name(r.FormValue("name"))
+		if !ok {
+			notice = "ФИО в формате: Иванов И.И."
+			break
+		}
+		if old, ok := renameUser(ctx, uid, name); ok {
+			writeAudit(ctx, s.AdminID, "rename_user", fmt.Sprintf("%d: %s → %s", uid, old, name))
+			notice = "ФИО изменено: " + name
+		}
+	case "unit":
+		unit := findUnit(ctx, r.FormValue("unit"))
+		if r.FormValue("unit") == "" || unit != "" {
+			setUserUnit(ctx, uid, unit)
+			writeAudit(ctx, s.AdminID, "set_unit", fmt.Sprintf("%d: %s", uid, unit))
+			notice = u.Name + ": подразделение сохранено"
+		}
+	case "archive", "unarchive":
+		archived := r.FormValue("op") == "archive"
+		if setUserArchived(ctx, uid, archived) {
+			if archived {
+				writeAudit(ctx, s.AdminID, "archive_user", fmt.Sprintf("%d %s", uid, u.Name))
+				notice = u.Name + " переведён в архив"
+			} else {
+				writeAudit(ctx, s.AdminID, "unarchive_user", fmt.Sprintf("%d %s", uid, u.Name))
+				notice = u.Name + " возвращён из архива"
+			}
+		}
+	case "ban":
+		if banUser(ctx, s.AdminID, uid, strings.TrimSpace(r.FormValue("reason"))) {
+			notice = u.Name + " заблокирован"
+		}
+	case "unban":
+		if unbanUser(ctx, s.AdminID, uid) {
+			notice = u.Name + " разблокирован"
+		}
+	}
+	webAdminRedirect(w, r, "/admin", notice)
+}
+
+func webAdminRecords(w http.ResponseWriter, r *http.Request, s webSession) {
+	ctx := r.Context()
+	uid, _ := strconv.Atoi(r.URL.Query().Get("uid"))
+	page := newWebAdminPage(s, r)
+	page.User = webAdminFindUser(ctx, s.AdminID, uid)
+	if page.User == nil {
+		webAdminRedirect(w, r, "/admin", "Пользователь недоступен")
+		return
+	}
+	page.Days = apiDays(r)
+	if r.URL.Query().Get("days") == "" {
+		page.Days = 7
+	}
+	for _, row := range getUserHistory(ctx, strconv.Itoa(uid), daysAgo(page.Days-1)) {
+		t, _ := time.ParseInLocation(dateFormat, row[0], time.Local)
+		page.Records = append(page.Records, webAdminRecord{row[0], t.Format("02.01.2006 15:04"), row[3], row[4]})
+	}
+	page.Actions = []string{"Прибыл", "Убыл"}
+	for _, st := range statuses {
+		page.Actions = append(page.Actions, st.Action)
+	}
+	renderWebAdmin(w, "records", page)
+}
+
+// POST /admin/record: op = save | delete
+func webAdminRecordAction(w http.ResponseWriter, r *http.Request, s webSession) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost || !hasRight(ctx, s.AdminID, "edit_records") {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	uid, _ := strconv.Atoi(r.FormValue("uid"))
+	dt := r.FormValue("dt")
+	back := fmt.Sprintf("/admin/records?uid=%d&days=%s", uid, r.FormValue("days"))
+	if webAdminFindUser(ctx, s.AdminID, uid) == nil {
+		webAdminRedirect(w, r, "/admin", "Пользователь недоступен")
+		return
+	}
+	if r.FormValue("op") == "delete" {
+		if _, ok := updateRecord(ctx, s.AdminID, uid, dt, func([]string) []string { return nil }); ok {
+			webAdminRedirect(w, r, back, "Запись перенесена в корзину")
+		} else {
+			webAdminRedirect(w, r, back, "Запись не найдена")
+		}
+		return
+	}
+	t, err := time.ParseInLocation("02.01.2006 15:04", strings.TrimSpace(r.FormValue("time")), time.Local)
+	if err != nil {
+		webAdminRedirect(w, r, back, "Время в формате ДД.ММ.ГГГГ ЧЧ:ММ")
+		return
+	}
+	action := r.FormValue("action")
+	if action != "Прибыл" && action != "Убыл" {
+		if _, ok := findStatus(action); !ok {
+			webAdminRedirect(w, r, back, "Неизвестное действие")
+			return
+		}
+	}
+	location := strings.TrimSpace(r.FormValue("location"))
+	if action == "Прибыл" {
+		location = "-"
+	} else if len([]rune(location)) < 3 {
+		webAdminRedirect(w, r, back, "Локация — не менее 3 символов")
+		return
+	}
+	old, _ := time.ParseInLocation(dateFormat, dt, time.Local)
+	if t.Format("02.01.2006 15:04") == old.Format("02.01.2006 15:04") {
+		t = old
+	}
+	_, ok := updateRecord(ctx, s.AdminID, uid, dt, func(row []string) []string {
+		row[0], row[3], row[4] = t.Format(dateFormat), action, location
+		return row
+	})
+	if ok {
+		webAdminRedirect(w, r, back, "Запись сохранена")
+	} else {
+		webAdminRedirect(w, r, back, "Запись не найдена")
+	}
+}
+
+// Выгрузка как в боте: за days дней, в зоне админа
+func webAdminExport(w http.ResponseWriter, r *http.Request, s webSession) {
+	ctx := r.Context()
+	if !hasRight(ctx, s.AdminID, "export") {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	since := daysAgo(apiDays(r) - 1)
+	rows := journal.ReadRange(ctx, since, daysAgo(-1))
+	var filtered [][]string
+	for _, row := range rows {
+		if len(row) > 1 && adminSeesUser(ctx, int64(s.AdminID), row[1]) {
+			filtered = append(filtered, row)
+		}
+	}
+	if len(filtered) > exportLimit {
+		http.Error(w, "too many records", http.StatusRequestEntityTooLarge)
+		return
+	}
+	writeAudit(ctx, s.AdminID, "web_export", fmt.Sprintf("%d дн., %d записей", apiDays(r), len(filtered)))
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="report.xlsx"`)
+	buildReportWorkbook(ctx, filtered, detectAnomalies(ctx, rows)).Write(w)
+	incMetric("tabel_exports_total", `via="web"`)
+}
+
+func renderWebAdmin(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webAdminTemplates.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var webAdminTemplates = template.Must(template.New("").Funcs(template.FuncMap{"path": httpPath}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Табель — админка</title>
+<style>
+body { font-family: sans-serif; margin: 0 auto; padding: 16px; max-width: 1200px; }
+nav a { margin-right: 16px; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 4px 6px; border-bottom: 1px solid #ddd; vertical-align: top; }
+form.inline { display: inline; }
+input, select, button { font-size: 14px; }
+.notice { background: #e8f5e9; padding: 8px 12px; border-radius: 6px; }
+.error { background: #ffebee; padding: 8px 12px; border-radius: 6px; }
+.muted { color: #888; }
+</style>
+</head>
+<body>{{end}}
+
+{{define "nav"}}<nav>
+<b>📋 Табель</b> · {{.Admin}} ·
+<a href="{{path "/admin"}}">Личный состав</a>
+{{if .Rights.export}}<a href="{{path "/admin/export.xlsx"}}?days=1">Excel за сегодня</a><a href="{{path "/admin/export.xlsx"}}?days=7">за 7 дней</a><a href="{{path "/admin/export.xlsx"}}?days=31">за 31 день</a>{{end}}
+<a href="{{path "/admin/logout"}}">Выйти</a>
+</nav>
+{{if .Notice}}<p class="notice">{{.Notice}}</p>{{end}}{{end}}
+
+{{define "login"}}{{template "head"}}
+<h2>🔐 Вход в админку</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="{{path "/admin/login"}}">
+<p>Отправьте боту команду /weblogin и введите код:</p>
+<input name="code" inputmode="numeric" autocomplete="one-time-code" maxlength="6" autofocus>
+<button>Войти</button>
+</form>
+{{if .BotName}}<p>или</p>
+<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-login="{{.BotName}}" data-size="large" data-auth-url="{{path "/admin/tglogin"}}" data-request-access="write"></script>{{end}}
+</body></html>{{end}}
+
+{{define "users"}}{{template "head"}}{{template "nav" .}}
+<h2>👥 Личный состав ({{len .Users}})</h2>
+<table>
+<tr><th>ФИО</th><th>Подразделение</th><th>Последняя отметка</th><th></th></tr>
+{{$p := .}}{{range .Users}}<tr>
+<td>{{if $p.Rights.manage_users}}<form class="inline" method="post" action="{{path "/admin/user"}}">
+<input type="hidden" name="csrf" value="{{$p.CSRF}}"><input type="hidden" name="uid" value="{{.ID}}"><input type="hidden" name="op" value="rename">
+<input name="name" value="{{.Name}}" size="18"><button title="Сохранить ФИО">✏️</button></form>{{else}}{{.Name}}{{end}}
+{{if .Archived}}<span class="muted">🗄 архив</span>{{end}}{{if .Banned}} ⛔{{end}}</td>
+<td>{{if and $p.Rights.manage_users $p.Units}}<form class="inline" method="post" action="{{path "/admin/user"}}">
+<input type="hidden" name="csrf" value="{{$p.CSRF}}"><input type="hidden" name="uid" value="{{.ID}}"><input type="hidden" name="op" value="unit">
+<select name="unit" onchange="this.form.submit()"><option value="">—</option>{{$unit := .Unit}}{{range $p.Units}}<option{{if eq . $unit}} selected{{end}}>{{.}}</option>{{end}}</select></form>{{else}}{{.Unit}}{{end}}</td>
+<td>{{.Status}}</td>
+<td>{{if $p.Rights.edit_records}}<a href="{{path "/admin/records"}}?uid={{.ID}}">Отметки</a>{{end}}
+{{if $p.Rights.manage_users}}<form class="inline" method="post" action="{{path "/admin/user"}}">
+<input type="hidden" name="csrf" value="{{$p.CSRF}}"><input type="hidden" name="uid" value="{{.ID}}">
+{{if .Archived}}<button name="op" value="unarchive">♻️ Из архива</button>{{else}}<button name="op" value="archive" onclick="return confirm('Перевести в архив?')">🗄 В архив</button>{{end}}
+{{if .Banned}}<button name="op" value="unban">Разблокировать</button>{{else}}<button name="op" value="ban" onclick="return confirm('Заблокировать?')">⛔</button>{{end}}
+</form>{{end}}</td>
+</tr>
+{{end}}</table>
+</body></html>{{end}}
+
+{{define "records"}}{{template "head"}}{{template "nav" .}}
+<h2>🕒 {{.User.Name}}: отметки за {{.Days}} дн.</h2>
+<p><a href="{{path "/admin/records"}}?uid={{.User.ID}}&days=1">сегодня</a> · <a href="{{path "/admin/records"}}?uid={{.User.ID}}&days=7">7 дней</a> · <a href="{{path "/admin/records"}}?uid={{.User.ID}}&days=31">31 день</a></p>
+{{if .Records}}<table>
+<tr><th>Время</th><th>Действие</th><th>Локация</th><th></th></tr>
+{{$p := .}}{{range $i, $r := .Records}}<tr>
+<td><input form="rec{{$i}}" name="time" value="{{$r.Time}}" size="16"></td>
+<td><select form="rec{{$i}}" name="action">{{range $p.Actions}}<option{{if eq . $r.Action}} selected{{end}}>{{.}}</option>{{end}}</select></td>
+<td><input form="rec{{$i}}" name="location" value="{{$r.Location}}"></td>
+<td><form id="rec{{$i}}" method="post" action="{{path "/admin/record"}}">
+<input type="hidden" name="csrf" value="{{$p.CSRF}}"><input type="hidden" name="uid" value="{{$p.User.ID}}">
+<input type="hidden" name="dt" value="{{$r.DT}}"><input type="hidden" name="days" value="{{$p.Days}}">
+<button name="op" value="save">💾</button> <button name="op" value="delete" onclick="return confirm('Удалить запись? Её можно вернуть из корзины.')">🗑</button>
+</form></td>
+</tr>
+{{end}}</table>{{else}}<p>Отметок нет.</p>{{end}}
+</body></html>{{end}}
+`))
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/xuri/excelize/v2"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Аналитика по части для админов ---
+
+const analyticsDays = 30
+
+// Сколько человек в части в каждый момент samples (samples по возрастанию)
+func presenceSamples(rows [][]string, samples []time.Time) []int {
+	inside := make(map[string]bool)
+	counts := make([]int, len(samples))
+	i := 0
+	for s, sample := range samples {
+		for ; i < len(rows); i++ {
+			row := rows[i]
+			if len(row) < 5 {
+				continue
+			}
+			t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+			if err != nil {
+				continue
+			}
+			if t.After(sample) {
+				break
+			}
+			inside[row[1]] = row[3] == "Прибыл"
+		}
+		for _, in := range inside {
+			if in {
+				counts[s]++
+			}
+		}
+	}
+	return counts
+}
+
+// Средняя численность в части по часам за рабочие дни периода
+func averagePresenceByHour(ctx context.Context, rows [][]string, from, to time.Time) [24]float64 {
+	cal := loadWorkCalendar(ctx)
+	var samples []time.Time
+	days := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if !cal.IsDutyDay(d) {
+			continue
+		}
+		days++
+		for h := 0; h < 24; h++ {
+			samples = append(samples, time.Date(d.Year(), d.Month(), d.Day(), h, 0, 0, 0, time.Local))
+		}
+	}
+	var avg [24]float64
+	if days == 0 {
+		return avg
+	}
+	counts := presenceSamples(rows, samples)
+	for i, c := range counts {
+		avg[i%24] += float64(c)
+	}
+	for h := range avg {
+		avg[h] /= float64(days)
+	}
+	return avg
+}
+
+func textBar(value, max float64, width int) string {
+	if max <= 0 {
+		return ""
+	}
+	n := int(value / max * float64(width))
+	return strings.Repeat("▇", n)
+}
+
+// Отлучки по дням: дата -> количество и суммарное время
+type dayStat struct {
+	Date       time.Time
+	Departures int
+	TimeOut    time.Duration
+	People     map[string]bool
+}
+
+func dailyStats(list []absence, from, to time.Time, now time.Time) []*dayStat {
+	var stats []*dayStat
+	index := make(map[string]*dayStat)
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		s := &dayStat{Date: d, People: make(map[string]bool)}
+		stats = append(stats, s)
+		index[d.Format("02.01.2006")] = s
+	}
+	for _, a := range list {
+		s, ok := index[a.Left.Format("02.01.2006")]
+		if !ok {
+			continue
+		}
+		s.Departures++
+		s.TimeOut += a.Duration(now)
+		s.People[a.UID] = true
+	}
+	return stats
+}
+
+func sendAnalytics(ctx context.Context, bot Sender, chatID int64) {
+	now := clock.Now()
+	to := daysAgo(-1)
+	from := daysAgo(analyticsDays - 1)
+	rows := scopedRows(ctx, chatID, journal.ReadSince(ctx, from.AddDate(0, -1, 0)))
+	list := collectAbsences(rows, "", from, to)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📈 Аналитика за %d дней\n\n", analyticsDays))
+
+	// Кривая присутствия
+	avg := averagePresenceByHour(ctx, rows, from, daysAgo(0))
+	maxAvg := 0.0
+	for _, v := range avg {
+		if v > maxAvg {
+			maxAvg = v
+		}
+	}
+	b.WriteString("👥 Средняя численность в части (рабочие дни):\n")
+	for h := 6; h <= 22; h += 2 {
+		b.WriteString(fmt.Sprintf("%02d:00 %s %.1f\n", h, textBar(avg[h], maxAvg, 10), avg[h]))
+	}
+
+	// Среднее время вне части на человека
+	perUser := make(map[string]time.Duration)
+	names := make(map[string]string)
+	for _, a := range list {
+		perUser[a.UID] += a.Duration(now)
+		names[a.UID] = a.Name
+	}
+	if len(perUser) > 0 {
+		var total time.Duration
+		for _, d := range perUser {
+			total += d
+		}
+		b.WriteString(fmt.Sprintf("\n⏱ Вне части в среднем на человека: %s\n", formatDuration(total/time.Duration(len(perUser)))))
+		type userTotal struct {
+			Name string
+			D    time.Duration
+		}
+		var top []userTotal
+		for uid, d := range perUser {
+			top = append(top, userTotal{names[uid], d})
+		}
+		sort.Slice(top, func(i, j int) bool { return top[i].D > top[j].D })
+		if len(top) > 3 {
+			top = top[:3]
+		}
+		b.WriteString("Больше всего вне части:\n")
+		for _, t := range top {
+			b.WriteString(fmt.Sprintf("— %s: %s\n", t.Name, formatDuration(t.D)))
+		}
+	}
+
+	// Локации
+	if len(list) > 0 {
+		b.WriteString("\n📍 Топ локаций:\n")
+		for _, l := range topLocations(list, 5) {
+			b.WriteString(fmt.Sprintf("— %s: %d\n", l.Name, l.Count))
+		}
+	}
+
+	// Тренд: убытия по неделям
+	stats := dailyStats(list, from, to, now)
+	b.WriteString("\n📉 Убытия по неделям:\n")
+	for i := 0; i < len(stats); i += 7 {
+		end := i + 7
+		if end > len(stats) {
+			end = len(stats)
+		}
+		count := 0
+		for _, s := range stats[i:end] {
+			count += s.Departures
+		}
+		b.WriteString(fmt.Sprintf("%s–%s: %d\n", stats[i].Date.Format("02.01"), stats[end-1].Date.Format("02.01"), count))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📥 Excel", "analytics_xlsx"),
+	))
+	bot.Send(msg)
+}
+
+func sendAnalyticsExcel(ctx context.Context, bot Sender, chatID int64) {
+	now := clock.Now()
+	to := daysAgo(-1)
+	from := daysAgo(analyticsDays - 1)
+	rows := scopedRows(ctx, chatID, journal.ReadSince(ctx, from.AddDate(0, -1, 0)))
+	list := collectAbsences(rows, "", from, to)
+	stats := dailyStats(list, from, to, now)
+
+	f := excelize.NewFile()
+	sheet := "По дням"
+	f.SetSheetName("Sheet1", sheet)
+	f.SetSheetRow(sheet, "A1", &[]interface{}{"Дата", "Убытий", "Человек", "Часов вне части"})
+	for i, s := range stats {
+		f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &[]interface{}{
+			s.Date.Format("02.01.2006"), s.Departures, len(s.People), fmt.Sprintf("%.1f", s.TimeOut.Hours()),
+		})
+	}
+	f.SetColWidth(sheet, "A", "D", 18)
+
+	hours := "Присутствие"
+	f.NewSheet(hours)
+	f.SetSheetRow(hours, "A1", &[]interface{}{"Час", "Среднее в части"})
+	avg := averagePresenceByHour(ctx, rows, from, daysAgo(0))
+	for h := 0; h < 24; h++ {
+		f.SetSheetRow(hours, fmt.Sprintf("A%d", h+2), &[]interface{}{fmt.Sprintf("%02d:00", h), fmt.Sprintf("%.1f", avg[h])})
+	}
+
+	locs := "Локации"
+	f.NewSheet(locs)
+	f.SetSheetRow(locs, "A1", &[]interface{}{"Локация", "Убытий"})
+	for i, l := range topLocations(list, len(list)) {
+		f.SetSheetRow(locs, fmt.Sprintf("A%d", i+2), &[]interface{}{l.Name, l.Count})
+	}
+	f.SetColWidth(locs, "A", "A", 24)
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		log.Printf("analytics: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания Excel файла"))
+		return
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: "Аналитика.xlsx", Bytes: buf.Bytes()})
+	doc.Caption = fmt.Sprintf("📈 Аналитика за %d дней", analyticsDays)
+	bot.Send(doc)
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Подозрительные отметки ---
+//
+// Набор правил, каждое смотрит на отметку и более ранние отметки того же
+// человека за тот же день. Сработавшие правила показываются в журнале и в
+// примечании выгрузки; если включено (/anomalies on), админы получают
+// оповещение сразу после отметки. Пороги хранятся в settings.csv.
+
+const (
+	anomalyAlertsKey = "anomaly_alerts"
+	anomalyNightKey  = "anomaly_night"   // "0-5" — часы ночного прибытия
+	anomalyShortKey  = "anomaly_short"   // секунды, короче — подозрительное убытие
+	anomalyMaxOutKey = "anomaly_max_out" // больше убытий за день — подозрительно
+)
+
+type anomalyRule struct {
+	Code  string
+	Title string
+	// prev — более ранние отметки того же человека за тот же день, от старых к новым
+	Check func(ctx context.Context, row []string, prev [][]string) bool
+}
+
+var anomalyRules = []anomalyRule{
+	{"night", "прибытие ночью", func(ctx context.Context, row []string, prev [][]string) bool {
+		if row[3] != "Прибыл" {
+			return false
+		}
+		from, to := anomalyNightHours(ctx)
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		return err == nil && t.Hour() >= from && t.Hour() < to
+	}},
+	{"short", "слишком короткое убытие", func(ctx context.Context, row []string, prev [][]string) bool {
+		if row[3] != "Прибыл" || len(prev) == 0 || prev[len(prev)-1][3] != "Убыл" {
+			return false
+		}
+		left, err1 := time.ParseInLocation(dateFormat, prev[len(prev)-1][0], time.Local)
+		back, err2 := time.ParseInLocation(dateFormat, row[0], time.Local)
+		return err1 == nil && err2 == nil && back.Sub(left) < anomalyShortDeparture(ctx)
+	}},
+	{"many", "много убытий за день", func(ctx context.Context, row []string, prev [][]string) bool {
+		if row[3] != "Убыл" {
+			return false
+		}
+		count := 1
+		for _, p := range prev {
+			if p[3] == "Убыл" {
+				count++
+			}
+		}
+		return count > anomalyMaxDepartures(ctx)
+	}},
+}
+
+func anomalyNightHours(ctx context.Context) (int, int) {
+	parts := strings.SplitN(getSetting(ctx, anomalyNightKey, "0-5"), "-", 2)
+	if len(parts) == 2 {
+		from, err1 := strconv.Atoi(parts[0])
+		to, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil && from >= 0 && to <= 24 && from < to {
+			return from, to
+		}
+	}
+	return 0, 5
+}
+
+func anomalyShortDeparture(ctx context.Context) time.Duration {
+	if s, err := strconv.Atoi(getSetting(ctx, anomalyShortKey, "")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return time.Minute
+}
+
+func anomalyMaxDepartures(ctx context.Context) int {
+	if n, err := strconv.Atoi(getSetting(ctx, anomalyMaxOutKey, "")); err == nil && n > 0 {
+		return n
+	}
+	return 5
+}
+
+func anomalyKey(row []string) string {
+	return row[0] + "|" + row[1]
+}
+
+// Сработавшие правила для каждой отметки; rows — от старых к новым,
+// ключ — anomalyKey
+func detectAnomalies(ctx context.Context, rows [][]string) map[string][]string {
+	found := make(map[string][]string)
+	byDay := make(map[string][][]string) // uid|дата -> отметки за день
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		date, _ := splitDateTime(row[0])
+		day := row[1] + "|" + date
+		prev := byDay[day]
+		for _, rule := range anomalyRules {
+			if rule.Check(ctx, row, prev) {
+				found[anomalyKey(row)] = append(found[anomalyKey(row)], rule.Title)
+			}
+		}
+		byDay[day] = append(prev, row)
+	}
+	return found
+}
+
+// --- Оповещения ---
+
+type anomalyAlert struct {
+	Row    []string
+	Titles []string
+}
+
+// Очередь оповещений; её раз в минуту разбирает задача anomalies (jobs.go)
+var anomalyAlerts = make(chan anomalyAlert, 64)
+
+func anomalyAlertsEnabled(ctx context.Context) bool {
+	return getSetting(ctx, anomalyAlertsKey, "") == "1"
+}
+
+// Проверка только что сохранённой отметки по её дню в журнале
+func checkNewMarkAnomalies(ctx context.Context, row []string) {
+	if !anomalyAlertsEnabled(ctx) || len(row) < 5 {
+		return
+	}
+	t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+	if err != nil {
+		return
+	}
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	var day [][]string
+	for _, r := range journal.ReadRange(ctx, dayStart, dayStart.AddDate(0, 0, 1)) {
+		if len(r) >= 5 && r[1] == row[1] {
+			day = append(day, r)
+		}
+	}
+	titles := detectAnomalies(ctx, day)[anomalyKey(row)]
+	if len(titles) == 0 {
+		return
+	}
+	select {
+	case anomalyAlerts <- anomalyAlert{row, titles}:
+	default:
+	}
+}
+
+// Рассылает всё, что накопилось в очереди, и возвращается
+func sendAnomalyAlerts(ctx context.Context, bot Sender) {
+	for {
+		var a anomalyAlert
+		select {
+		case a = <-anomalyAlerts:
+		default:
+			return
+		}
+		txt := fmt.Sprintf(
+			"⚠️ <b>Подозрительная отметка</b>\n"+
+				"👤 <b>ФИО:</b> %s\n"+
+				"⏰ <b>Время:</b> %s\n"+
+				"⚡ <b>Действие:</b> %s %s\n"+
+				"❓ <b>Причина:</b> %s",
+			a.Row[2], a.Row[0], actionEmoji(a.Row[3]), a.Row[3], strings.Join(a.Titles, ", "))
+		for _, chatID := range adminRecipients(ctx, "notifications") {
+			if adminSeesUser(ctx, chatID, a.Row[1]) {
+				sendAdminNotification(ctx, bot, chatID, txt)
+			}
+		}
+	}
+}
+
+// /anomalies — отчёт за 7 дней и пороги; on|off, night <с> <до>, short <сек>, maxout <n>
+func handleAnomaliesCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	bad := func() {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /anomalies on|off, /anomalies night 0 5, /anomalies short 60, /anomalies maxout 5"))
+	}
+	if len(fields) == 0 {
+		sendAnomalyReport(ctx, bot, chatID)
+		return
+	}
+	switch fields[0] {
+	case "on", "off":
+		value := ""
+		if fields[0] == "on" {
+			value = "1"
+		}
+		setSetting(ctx, anomalyAlertsKey, value)
+		writeAudit(ctx, adminID, "anomaly_alerts", fields[0])
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Оповещения о подозрительных отметках: "+fields[0]))
+	case "night":
+		if len(fields) != 3 {
+			bad()
+			return
+		}
+		from, err1 := strconv.Atoi(fields[1])
+		to, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || from < 0 || to > 24 || from >= to {
+			bad()
+			return
+		}
+		value := fmt.Sprintf("%d-%d", from, to)
+		setSetting(ctx, anomalyNightKey, value)
+		writeAudit(ctx, adminID, "anomaly_night", value)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Ночное прибытие: с %d:00 до %d:00", from, to)))
+	case "short", "maxout":
+		n := 0
+		if len(fields) == 2 {
+			n, _ = strconv.Atoi(fields[1])
+		}
+		if n <= 0 {
+			bad()
+			return
+		}
+		key := anomalyShortKey
+		if fields[0] == "maxout" {
+			key = anomalyMaxOutKey
+		}
+		setSetting(ctx, key, strconv.Itoa(n))
+		writeAudit(ctx, adminID, key, strconv.Itoa(n))
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Порог сохранён: "+strconv.Itoa(n)))
+	default:
+		bad()
+	}
+}
+
+func sendAnomalyReport(ctx context.Context, bot Sender, chatID int64) {
+	since := daysAgo(7)
+	rows := journal.ReadSince(ctx, since)
+	found := detectAnomalies(ctx, rows)
+	from, to := anomalyNightHours(ctx)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⚠️ Подозрительные отметки за 7 дней\n\n"+
+		"Правила: прибытие с %d:00 до %d:00, возвращение быстрее %d сек, больше %d убытий за день.\n",
+		from, to, int(anomalyShortDeparture(ctx).Seconds()), anomalyMaxDepartures(ctx)))
+	if anomalyAlertsEnabled(ctx) {
+		b.WriteString("Оповещения: включены\n\n")
+	} else {
+		b.WriteString("Оповещения: выключены (/anomalies on)\n\n")
+	}
+	shown := 0
+	for i := len(rows) - 1; i >= 0 && shown < 30; i-- {
+		row := rows[i]
+		if len(row) < 5 || !adminSeesUser(ctx, chatID, row[1]) {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		titles := found[anomalyKey(row)]
+		if len(titles) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s — %s %s: %s\n", actionEmoji(row[3]), row[0], capitalizeName(row[2]), row[3], strings.Join(titles, ", ")))
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString("Ничего подозрительного не найдено.")
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
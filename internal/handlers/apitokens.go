@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- API-токены и REST-эндпоинты /api/v1 ---
+//
+// Токены выдаёт главный админ командой /token. В tokens.csv хранится
+// только SHA-256 токена: ID, хэш, область, название, кто выдал, когда,
+// когда отозван. Области вложены: read < export < admin. Проверка
+// доступа — requireScope (httpauth.go).
+
+const (
+	tokensFile  = "tokens.csv"
+	tokenPrefix = "tb_"
+)
+
+var tokenScopes = map[string]int{"read": 1, "export": 2, "admin": 3}
+
+func init() {
+	backupFiles = append(backupFiles, tokensFile)
+	handleHTTP("/api/v1/presence", requireScope("read", apiPresence))
+	handleHTTP("/api/v1/marks", requireScope("read", apiMarks))
+	handleHTTP("/api/v1/export.xlsx", requireScope("export", apiExport))
+	handleHTTP("/api/v1/users", requireScope("admin", apiUsers))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Новый токен; возвращается один раз, в файле остаётся только хэш
+func issueToken(ctx context.Context, adminID int, scope, label string) (id, token string) {
+	b := make([]byte, 24)
+	rand.Read(b)
+	token = tokenPrefix + hex.EncodeToString(b)
+	idBytes := make([]byte, 3)
+	rand.Read(idBytes)
+	id = hex.EncodeToString(idBytes)
+	appendCSV(ctx, tokensFile, []string{
+		id, hashToken(token), scope, label, strconv.Itoa(adminID), clock.Now().Format(dateFormat), "",
+	})
+	writeAudit(ctx, adminID, "token_issue", id+" "+scope+" "+label)
+	return id, token
+}
+
+func revokeToken(ctx context.Context, adminID int, id string) bool {
+	revoked := false
+	updateCSV(ctx, tokensFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) >= 7 && row[0] == id && row[6] == "" {
+				rows[i][6] = clock.Now().Format(dateFormat)
+				revoked = true
+				break
+			}
+		}
+		return rows
+	})
+	if revoked {
+		writeAudit(ctx, adminID, "token_revoke", id)
+		return true
+	}
+	return false
+}
+
+// Область действующего токена; "" — токен неизвестен или отозван
+func tokenScope(ctx context.Context, token string) string {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return ""
+	}
+	hash := hashToken(token)
+	for _, row := range readCSV(ctx, tokensFile) {
+		if len(row) >= 7 && secureEqual(row[1], hash) && row[6] == "" {
+			return row[2]
+		}
+	}
+	return ""
+}
+
+// Токен из заголовка Authorization: Bearer или из ?token=
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return r.URL.Query().Get("token")
+}
+
+// days из запроса: 1..31, по умолчанию 1
+func apiDays(r *http.Request) int {
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days < 1 {
+		return 1
+	}
+	if days > 31 {
+		return 31
+	}
+	return days
+}
+
+type apiPresenceEntry struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Unit     string `json:"unit,omitempty"`
+	Action   string `json:"action"`
+	Location string `json:"location,omitempty"`
+	Since    string `json:"since,omitempty"`
+	Return   string `json:"expected_return,omitempty"`
+}
+
+func apiPresence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	units, last := userUnits(ctx), lastRows(ctx)
+	var out []apiPresenceEntry
+	for _, u := range getSortedUsers(ctx) {
+		e := apiPresenceEntry{ID: u.ID, Name: capitalizeName(u.Name), Unit: units[strconv.Itoa(u.ID)]}
+		if row := last[strconv.Itoa(u.ID)]; row != nil {
+			e.Action, e.Since = row[3], row[0]
+			if row[3] != "Прибыл" {
+				e.Location = cleanLocation(row[4])
+			}
+			if t, ok := expectedReturn(row); ok {
+				e.Return = t.Format(dateFormat)
+			}
+		}
+		out = append(out, e)
+	}
+	writeJSON(w, out)
+}
+
+type apiMark struct {
+	Time     string `json:"time"`
+	UserID   string `json:"user_id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Location string `json:"location"`
+}
+
+func apiMarks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	since := daysAgo(apiDays(r) - 1)
+	var out []apiMark
+	for _, row := range journal.ReadRange(ctx, since, daysAgo(-1)) {
+		if len(row) < 5 {
+			continue
+		}
+		out = append(out, apiMark{row[0], row[1], row[2], row[3], cleanLocation(row[4])})
+	}
+	writeJSON(w, out)
+}
+
+func apiExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	since := daysAgo(apiDays(r) - 1)
+	rows := journal.ReadRange(ctx, since, daysAgo(-1))
+	var filtered [][]string
+	for _, row := range rows {
+		if len(row) > 1 {
+			filtered = append(filtered, row)
+		}
+	}
+	if len(filtered) > exportLimit {
+		http.Error(w, "too many records", http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="report.xlsx"`)
+	buildReportWorkbook(ctx, filtered, detectAnomalies(ctx, rows)).Write(w)
+	incMetric("tabel_exports_total", `via="api"`)
+}
+
+type apiUser struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Unit     string `json:"unit,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Archived bool   `json:"archived"`
+	Banned   bool   `json:"banned"`
+}
+
+func apiUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	units, phones := userUnits(ctx), userPhones(ctx)
+	var out []apiUser
+	for _, u := range getAllUsers(ctx) {
+		id := strconv.Itoa(u.ID)
+		out = append(out, apiUser{u.ID, capitalizeName(u.Name), units[id], phones[id], u.Archived, isBanned(ctx, u.ID)})
+	}
+	writeJSON(w, out)
+}
+
+// /token — список, /token new <read|export|admin> [название], /token revoke <ID>
+func handleTokenCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) >= 2 && fields[0] == "new":
+		if tokenScopes[fields[1]] == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Область: read, export или admin"))
+			return
+		}
+		id, token := issueToken(ctx, adminID, fields[1], strings.Join(fields[2:], " "))
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🔑 Токен %s (%s):\n\n%s\n\nСохраните его — повторно он не показывается. Веб-панель: /?token=<токен>\nОтозвать: /token revoke %s", id, fields[1], token, id)))
+	case len(fields) == 2 && fields[0] == "revoke":
+		if revokeToken(ctx, adminID, fields[1]) {
+			bot.Send(tgbotapi.NewMessage(chatID, "✅ Токен "+fields[1]+" отозван."))
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Действующий токен с таким ID не найден."))
+		}
+	default:
+		var b strings.Builder
+		b.WriteString("🔑 API-токены:\n")
+		count := 0
+		for _, row := range readCSV(ctx, tokensFile) {
+			if len(row) < 7 || row[6] != "" {
+				continue
+			}
+			count++
+			b.WriteString(fmt.Sprintf("— %s: %s", row[0], row[2]))
+			if row[3] != "" {
+				b.WriteString(", " + row[3])
+			}
+			b.WriteString(", выдан " + row[5] + "\n")
+		}
+		if count == 0 {
+			b.WriteString("действующих нет\n")
+		}
+		b.WriteString("\nВыдать: /token new read|export|admin [название]\nОтозвать: /token revoke <ID>")
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+	}
+}
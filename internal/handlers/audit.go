@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+)
+
+// --- Журнал аудита изменений данных ---
+
+const auditFile = "audit.csv"
+
+func init() {
+	backupFiles = append(backupFiles, auditFile)
+}
+
+// Строка аудита: время, кто, действие, подробности
+func writeAudit(ctx context.Context, actorID int, action, details string) {
+	appendCSV(ctx, auditFile, []string{clock.Now().Format(dateFormat), strconv.Itoa(actorID), action, details})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Автоматическое прибытие по геозоне ---
+//
+// Пользователь включает режим (/autoarrive on) и делится с ботом
+// трансляцией геопозиции. Когда после «Убыл» он снова входит в радиус
+// геозоны, бот сам записывает «Прибыл» с пометкой auto в колонке источника.
+
+const (
+	autoArriveKeyPrefix = "autoarrive:"
+	autoSource          = "auto"
+)
+
+var (
+	geoInsideMu sync.Mutex
+	geoInside   = make(map[int]bool) // был ли пользователь в геозоне при прошлом обновлении
+)
+
+func autoArriveEnabled(ctx context.Context, userID int) bool {
+	return getSetting(ctx, autoArriveKeyPrefix+strconv.Itoa(userID), "") == "1"
+}
+
+func isAutoMark(row []string) bool {
+	return len(row) > colSource && row[colSource] == autoSource
+}
+
+// /autoarrive on|off
+func handleAutoArriveCommand(ctx context.Context, bot Sender, chatID int64, userID int, args string) {
+	switch strings.TrimSpace(args) {
+	case "on":
+		if _, ok := loadGeofence(ctx); !ok {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Геозона части не настроена, обратитесь к админу."))
+			return
+		}
+		setSetting(ctx, autoArriveKeyPrefix+strconv.Itoa(userID), "1")
+		writeAudit(ctx, userID, "autoarrive", "on")
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Автоотметка включена. Поделитесь с ботом трансляцией геопозиции "+
+			"(📎 → Геопозиция → Транслировать) — при возвращении в часть прибытие запишется само.\nОтключить: /autoarrive off"))
+	case "off":
+		setSetting(ctx, autoArriveKeyPrefix+strconv.Itoa(userID), "")
+		geoInsideMu.Lock()
+		delete(geoInside, userID)
+		geoInsideMu.Unlock()
+		writeAudit(ctx, userID, "autoarrive", "off")
+		bot.Send(tgbotapi.NewMessage(chatID, "Автоотметка выключена. Трансляцию геопозиции можно остановить."))
+	default:
+		state := "выключена"
+		if autoArriveEnabled(ctx, userID) {
+			state = "включена"
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, "🛰 Автоотметка прибытия по геопозиции: "+state+
+			"\nВключить: /autoarrive on\nВыключить: /autoarrive off"))
+	}
+}
+
+// Обновление трансляции геопозиции (новое или изменённое сообщение)
+func handleLiveLocation(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	fence, ok := loadGeofence(ctx)
+	if !ok || msg.Location == nil || !autoArriveEnabled(ctx, userID) {
+		return
+	}
+	dist := distanceM(fence.Lat, fence.Lon, msg.Location.Latitude, msg.Location.Longitude)
+	inside := dist <= fence.RadiusM
+	geoInsideMu.Lock()
+	wasInside, known := geoInside[userID]
+	geoInside[userID] = inside
+	geoInsideMu.Unlock()
+	// Только вход в геозону: первое обновление задаёт исходное положение
+	if !inside || !known || wasInside {
+		return
+	}
+	if last, _ := getLastAction(ctx, userID); last != "Убыл" {
+		return
+	}
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, userID, msg.From)
+	saveAttendanceRow(ctx, []string{now, strconv.Itoa(userID), name, "Прибыл", "-", autoSource, "",
+		fmt.Sprintf("%.6f,%.6f,%.0f", msg.Location.Latitude, msg.Location.Longitude, dist)})
+	notifyAdminAboutMark(ctx, bot, userID, name, "Прибыл", "-", now)
+	bot.Send(markConfirmation(msg.Chat.ID, "🛰 Вы вернулись в часть — прибытие отмечено автоматически.", now))
+}
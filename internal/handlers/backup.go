@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/storage"
+)
+
+// --- Резервные копии данных ---
+
+// Файлы, попадающие в архив. Новые хранилища добавляются сюда.
+var backupFiles = []string{dataFile, journal.WALFile, usersFile, adminsFile}
+
+// backupFiles и архивы журнала. backupFiles копируется: append к общему
+// срезу из двух горутин (выгрузка в S3 и снимок опасной зоны) писал бы в
+// один и тот же массив.
+func backupFileList() []string {
+	return append(append([]string(nil), backupFiles...), journal.Archives()...)
+}
+
+// Собирает ZIP со всеми существующими файлами данных
+func buildBackupArchive() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range backupFileList() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: clock.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sendBackup(bot Sender, chatID int64) {
+	data, err := buildBackupArchive()
+	if err != nil {
+		log.Printf("backup: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания резервной копии"))
+		return
+	}
+	stamp := clock.Now().Format("2006-01-02_15-04")
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("backup_%s.zip", stamp),
+		Bytes: data,
+	})
+	doc.Caption = "💾 Резервная копия данных от " + clock.Now().Format(dateFormat)
+	bot.Send(doc)
+}
+
+// --- Восстановление из архива ---
+
+// Загруженные главным админом архивы, ожидающие подтверждения
+var pendingRestore = make(map[int][]byte)
+
+const maxBackupSize = 20 << 20
+
+func handleBackupUpload(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	doc := msg.Document
+	if !strings.HasSuffix(strings.ToLower(doc.FileName), ".zip") {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Для восстановления пришлите ZIP-архив, созданный командой /backup."))
+		return
+	}
+	if doc.FileSize > maxBackupSize {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Архив слишком большой."))
+		return
+	}
+	data, err := downloadTelegramFile(ctx, bot, doc.FileID)
+	if err != nil {
+		log.Printf("restore: %v", err)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка загрузки архива"))
+		return
+	}
+	askRestoreConfirm(bot, msg.Chat.ID, userID, data)
+}
+
+// Проверяет архив и спрашивает подтверждение; сам архив ждёт в pendingRestore
+func askRestoreConfirm(bot Sender, chatID int64, userID int, data []byte) {
+	files, err := readBackupArchive(data)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Архив не прошёл проверку: "+err.Error()))
+		return
+	}
+	pendingRestore[userID] = data
+	var b strings.Builder
+	b.WriteString("📦 Архив проверен. Будут заменены файлы:\n")
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rows, _ := parseCSVBytes(files[name])
+		rows = storage.DropHeader(name, rows)
+		b.WriteString(fmt.Sprintf("— %s (%d строк)\n", name, len(rows)))
+	}
+	b.WriteString("\n⚠️ Текущие данные будут перезаписаны. Продолжить?")
+	reply := tgbotapi.NewMessage(chatID, b.String())
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Восстановить", "restore_confirm"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "restore_cancel"),
+		),
+	)
+	bot.Send(reply)
+}
+
+func downloadTelegramFile(ctx context.Context, bot Sender, fileID string) ([]byte, error) {
+	link, err := bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBackupSize+1))
+}
+
+// Проверяет структуру архива: только известные файлы, корректный CSV
+func readBackupArchive(data []byte) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("файл не является ZIP-архивом")
+	}
+	known := make(map[string]bool)
+	for _, name := range backupFiles {
+		known[name] = true
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		if !known[f.Name] && !journal.IsArchive(f.Name) {
+			return nil, fmt.Errorf("неизвестный файл %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxBackupSize))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		if _, err := parseCSVBytes(content); err != nil {
+			return nil, fmt.Errorf("%s: повреждён CSV", f.Name)
+		}
+		files[f.Name] = content
+	}
+	if len(files) == 0 {
+		return nil, errors.New("архив пуст")
+	}
+	return files, nil
+}
+
+func parseCSVBytes(data []byte) ([][]string, error) {
+	data, err := storage.Open(data)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// Файлы проверяются текущим ключом (storage.Open) и подменяются под
+// блокировками хранилища все разом: при ошибке остаются прежние данные.
+// Журнал заменяется целиком: месяцы, которых нет в копии, удаляются, иначе
+// старая копия смешалась бы с более новыми месяцами. journal.Lock — как
+// у остальных операций со всем журналом (wipeDataFiles, journal.Compact).
+func restoreBackup(ctx context.Context, data []byte) error {
+	files, err := readBackupArchive(data)
+	if err != nil {
+		return err
+	}
+	plain := make(map[string][]byte, len(files))
+	for name, content := range files {
+		if plain[name], err = storage.Open(content); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	unlock, err := journal.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	for _, name := range append([]string{dataFile}, journal.Archives()...) {
+		if _, ok := plain[name]; !ok {
+			plain[name] = nil
+		}
+	}
+	return storage.Replace(ctx, plain)
+}
+
+func handleRestoreAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	userID := query.From.ID
+	chatID := query.Message.Chat.ID
+	data, ok := pendingRestore[userID]
+	delete(pendingRestore, userID)
+	if !isRootAdmin(ctx, userID) || !ok {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нет архива для восстановления"))
+		return
+	}
+	if query.Data == "restore_cancel" {
+		bot.Send(tgbotapi.NewMessage(chatID, "Восстановление отменено."))
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Отменено"))
+		return
+	}
+	if err := restoreBackup(ctx, data); err != nil {
+		log.Printf("restore: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка восстановления: "+err.Error()))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ Данные восстановлены из архива."))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Восстановлено"))
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Блокировка пользователей ---
+//
+// bans.csv: ID, кто заблокировал, когда, причина. Заблокированный получает
+// вежливый отказ на любое действие, не может делать отметки и не получает
+// напоминаний. Главного админа заблокировать нельзя.
+
+const (
+	bansFile   = "bans.csv"
+	banRefusal = "🚫 Доступ к боту для вас закрыт. Если это ошибка — обратитесь к командиру."
+)
+
+func init() {
+	backupFiles = append(backupFiles, bansFile)
+}
+
+func isBanned(ctx context.Context, userID int) bool {
+	idStr := strconv.Itoa(userID)
+	for _, row := range readCSV(ctx, bansFile) {
+		if len(row) > 0 && row[0] == idStr {
+			return true
+		}
+	}
+	return false
+}
+
+func banUser(ctx context.Context, adminID, userID int, reason string) bool {
+	if isRootAdmin(ctx, userID) || isBanned(ctx, userID) {
+		return false
+	}
+	appendCSV(ctx, bansFile, []string{strconv.Itoa(userID), strconv.Itoa(adminID), clock.Now().Format(dateFormat), reason})
+	writeAudit(ctx, adminID, "ban", fmt.Sprintf("%d %s", userID, reason))
+	return true
+}
+
+func unbanUser(ctx context.Context, adminID, userID int) bool {
+	if !isBanned(ctx, userID) {
+		return false
+	}
+	removeUserRows(ctx, bansFile, userID)
+	writeAudit(ctx, adminID, "unban", strconv.Itoa(userID))
+	return true
+}
+
+// Ответ заблокированному; true — обработку надо прекратить
+func refuseBanned(ctx context.Context, bot Sender, update tgbotapi.Update) bool {
+	var from *tgbotapi.User
+	switch {
+	case update.Message != nil:
+		from = update.Message.From
+	case update.CallbackQuery != nil:
+		from = update.CallbackQuery.From
+	case update.EditedMessage != nil:
+		from = update.EditedMessage.From
+	case update.InlineQuery != nil:
+		from = update.InlineQuery.From
+	}
+	if from == nil || !isBanned(ctx, from.ID) {
+		return false
+	}
+	if update.CallbackQuery != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(update.CallbackQuery.ID, banRefusal))
+	} else if update.Message != nil && !isGroupChat(update.Message.Chat) {
+		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, banRefusal))
+	}
+	return true
+}
+
+func sendBanList(ctx context.Context, bot Sender, chatID int64) {
+	rows := readCSV(ctx, bansFile)
+	if len(rows) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "🚫 Заблокированных нет.\n\nЗаблокировать: /ban <ID> [причина]"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString("🚫 Заблокированные:\n")
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		id, _ := strconv.Atoi(row[0])
+		b.WriteString(fmt.Sprintf("— %s (%d), %s", capitalizeName(getUserName(ctx, id, nil)), id, row[2]))
+		if row[3] != "" {
+			b.WriteString(": " + row[3])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\nРазблокировать: /unban <ID>")
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
+
+// /ban <ID> [причина], /unban <ID>
+func handleBanCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string, ban bool) {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	userID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		sendBanList(ctx, bot, chatID)
+		return
+	}
+	if !ban {
+		if unbanUser(ctx, adminID, userID) {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %d разблокирован.", userID)))
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Пользователь не заблокирован."))
+		}
+		return
+	}
+	reason := ""
+	if len(fields) == 2 {
+		reason = strings.TrimSpace(fields[1])
+	}
+	if banUser(ctx, adminID, userID, reason) {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🚫 %d заблокирован.", userID)))
+	} else {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Нельзя заблокировать: пользователь уже в списке или это главный админ."))
+	}
+}
+
+// uban_<ID> — подтверждение, ubanok_<ID> — блокировка из карточки ЛС
+func handleBanAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, "_")
+	uid, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	name := capitalizeName(getUserName(ctx, uid, nil))
+	switch parts[0] {
+	case "uban":
+		msg := tgbotapi.NewMessage(chatID, "🚫 Заблокировать "+name+"? Он не сможет пользоваться ботом.")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 Да", fmt.Sprintf("ubanok_%d", uid)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+		))
+		bot.Send(msg)
+	case "ubanok":
+		if banUser(ctx, query.From.ID, uid, "") {
+			bot.Send(tgbotapi.NewMessage(chatID, "🚫 "+name+" заблокирован. Разблокировать: /unban "+strconv.Itoa(uid)))
+		}
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Закреплённое табло «в части / вне части» ---
+//
+// Одно сообщение в группе админов, которое бот правит после каждой отметки.
+// Где оно находится, хранится в settings.csv как "chatID:messageID".
+
+const boardSettingKey = "status_board"
+
+// Запросы на обновление; буфер 1 — пачка отметок даёт одну правку
+var boardRefresh = make(chan struct{}, 1)
+
+func refreshStatusBoard() {
+	select {
+	case boardRefresh <- struct{}{}:
+	default:
+	}
+}
+
+func statusBoardLocation(ctx context.Context) (chatID int64, msgID int, ok bool) {
+	parts := strings.SplitN(getSetting(ctx, boardSettingKey, ""), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	chatID, err1 := strconv.ParseInt(parts[0], 10, 64)
+	msgID, err2 := strconv.Atoi(parts[1])
+	return chatID, msgID, err1 == nil && err2 == nil
+}
+
+func statusBoardText(ctx context.Context) string {
+	return "📌 Табло\n\n" + presenceText(ctx) + "\n🔄 Обновлено: " + clock.Now().Format("02.01 15:04")
+}
+
+func statusBoardUpdater(ctx context.Context, bot Sender) {
+	for range boardRefresh {
+		chatID, msgID, ok := statusBoardLocation(ctx)
+		if !ok {
+			continue
+		}
+		if _, err := bot.Request(tgbotapi.NewEditMessageText(chatID, msgID, statusBoardText(ctx))); err != nil &&
+			!strings.Contains(err.Error(), "message is not modified") {
+			log.Printf("board: %v", err)
+		}
+		// Не чаще раза в 3 секунды — лимит Telegram на правки в группе
+		clock.Sleep(3 * time.Second)
+	}
+}
+
+// /board — создать и закрепить табло в текущем чате, /board off — убрать
+func handleBoardCommand(ctx context.Context, bot Sender, chatID int64, args string) {
+	if strings.TrimSpace(args) == "off" {
+		if oldChat, oldMsg, ok := statusBoardLocation(ctx); ok {
+			bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: oldChat, MessageID: oldMsg})
+		}
+		setSetting(ctx, boardSettingKey, "")
+		bot.Send(tgbotapi.NewMessage(chatID, "📌 Табло отключено."))
+		return
+	}
+	sent, err := bot.Send(tgbotapi.NewMessage(chatID, statusBoardText(ctx)))
+	if err != nil {
+		log.Printf("board: %v", err)
+		return
+	}
+	if oldChat, oldMsg, ok := statusBoardLocation(ctx); ok {
+		bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: oldChat, MessageID: oldMsg})
+	}
+	setSetting(ctx, boardSettingKey, fmt.Sprintf("%d:%d", chatID, sent.MessageID))
+	if _, err := bot.Request(tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: sent.MessageID, DisableNotification: true}); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Не удалось закрепить табло — дайте боту право закреплять сообщения."))
+	}
+}
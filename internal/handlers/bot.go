@@ -0,0 +1,1262 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/xuri/excelize/v2"
+
+	"tabel-go/internal/config"
+	"tabel-go/internal/export"
+	"tabel-go/internal/journal"
+	"tabel-go/internal/validate"
+)
+
+const (
+	defaultRootID  = 7973895358 // Главный админ, пока роль не передана (см. root.go)
+	dataFile       = journal.File
+	usersFile      = "users.csv"
+	adminsFile     = "admins.csv"
+	dateFormat     = journal.DateFormat
+	reportHour     = 19
+	reminderHour   = 18 // общее время напоминаний (личное — reminders.go)
+	reminderMinute = 30
+	exportLimit    = 10000 // максимум строк на экспорт
+	autoExportHour = 8     // час отправки автоэкспорта
+)
+
+var (
+	botToken             string
+	pendingNameInput     = make(map[int]bool)
+	pendingLocationInput = make(map[int]bool)
+	tempLocation         = make(map[int]string)
+	randText             = rand.New(rand.NewSource(time.Now().UnixNano()))
+	leaveLocations       = []string{
+		"🏥 Поликлиника", "⚓️ ОБРМП", "🌆 Калининград", "🛒 Магазин", "🍲 Столовая",
+		"🏨 Госпиталь", "⚙️ Хоз. Работы", "🩺 ВВК", "🏛 МФЦ", "🚓 Патруль", "📝 Другое",
+	}
+	reminderTexts = []string{
+		"🦉 Не забудь вернуться в часть! Солдат всегда возвращается домой.",
+		"🌚 Уже вечер — пора бы прибыть!",
+		"🚨 Командир волнуется — отметь прибытие!",
+		"🐻 Пора домой, жду тебя!",
+		"😜 Твои друзья уже здесь, а ты?",
+		"🎯 Не пропусти отметку 'Прибыл', а то придется угощать всех чаем!",
+		"🥟 Ужин стынет — прибудь, пока горячо!",
+		"📢 Объявление: пора отмечать прибытие!",
+	}
+	adminRights = []struct {
+		Code string
+		Name string
+	}{
+		{"summary", "📊 Сводка"},
+		{"export", "📥 Экспорт"},
+		{"manage_users", "👥 Управление ЛС"},
+		{"settings", "⚙️ Настройки"},
+		{"danger_zone", "⚠️ Опасная зона"},
+		{"edit_records", "✏️ Правка записей"},
+		{"notifications", "🔔 Уведомления об отметках"},
+	}
+	emojiRegex = regexp.MustCompile(`[\p{So}\p{Cn}\p{Sk}\p{Co}\p{Cs}\x{1F600}-\x{1F64F}\x{1F300}-\x{1F5FF}\x{1F680}-\x{1F6FF}\x{2600}-\x{26FF}\x{2700}-\x{27BF}\x{1F900}-\x{1F9FF}\x{1F1E6}-\x{1F1FF}]+`)
+)
+
+type User struct {
+	ID       int
+	Name     string
+	ChatID   int64
+	Archived bool
+}
+
+type Admin struct {
+	ID     int
+	Name   string
+	Rights map[string]bool
+}
+
+// Запуск бота: проверки и миграции, фоновые задачи, затем цикл апдейтов
+// до остановки
+func Run() {
+	setupSandbox()
+	botToken = config.Secret("TELEGRAM_TOKEN")
+	if sandboxMode {
+		botToken = sandboxBotToken()
+	}
+	if botToken == "" {
+		fmt.Println("Ошибка: TELEGRAM_TOKEN не найден (задать в Render Settings > Environment, TELEGRAM_TOKEN_FILE или SECRETS_DIR)!")
+		return
+	}
+	if err := checkDataEncryption(); err != nil {
+		log.Fatalf("шифрование данных: %v", err)
+	}
+	// Запуск и фоновые задачи, которым не нужно прерываться при остановке
+	ctx := context.Background()
+	if err := runMigrations(ctx); err != nil {
+		log.Fatalf("schema: %v", err)
+	}
+	journal.ReplayWAL(ctx)
+	loadStatusTable(ctx)
+	StartKeepAlive()
+
+	api, err := newBot(botToken)
+	if err != nil {
+		// Не log.Panic: текст паники печатается в обход вычёркивания секретов
+		log.Fatalf("не удалось подключиться к Telegram: %v", err)
+	}
+	api.Debug = false
+	bot := newSender(api)
+	fmt.Println("Бот Tabel-Go-Bot запущен! Версия:", versionString())
+	defer reportCrash(bot, "основной цикл")
+	notifyOnShutdown(ctx, bot)
+	setupWebAppMenuButton(bot)
+	setupBotCommands(ctx, bot)
+	notifyStartup(ctx, bot)
+
+	startJobs(ctx, bot)
+	go watched(shutdownCtx, bot, "тихие часы", quietQueueFlusher)
+	go watched(shutdownCtx, bot, "табло", statusBoardUpdater)
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := api.GetUpdatesChan(u)
+
+	for {
+		select {
+		case <-shutdownCtx.Done():
+			api.StopReceivingUpdates()
+			finishShutdown(bot)
+			return
+		case update := <-updates:
+			dispatchUpdate(bot, update)
+		}
+	}
+}
+
+func handleUpdate(ctx context.Context, bot Sender, update tgbotapi.Update) {
+	bot = WithContext(ctx, bot)
+	defer observeUpdate(clock.Now())
+	defer captureUpdatePanic(update)
+	if refuseBanned(ctx, bot, update) || throttled(bot, update) {
+		return
+	}
+	if update.Message != nil {
+		if update.Message.IsCommand() {
+			handleCommand(ctx, bot, update.Message)
+			bg := Detach(bot)
+			go func(chatID int64, msgID int) {
+				clock.Sleep(60 * time.Second)
+				bg.Request(tgbotapi.DeleteMessageConfig{
+					ChatID:    chatID,
+					MessageID: msgID,
+				})
+			}(update.Message.Chat.ID, update.Message.MessageID)
+			return
+		}
+		handleMessage(ctx, bot, update.Message)
+	}
+	if update.EditedMessage != nil {
+		if update.EditedMessage.Location != nil {
+			handleLiveLocation(ctx, bot, update.EditedMessage)
+		} else {
+			handleEditedMessage(ctx, bot, update.EditedMessage)
+		}
+	}
+	if update.CallbackQuery != nil {
+		handleAction(ctx, bot, update.CallbackQuery)
+	}
+	if update.InlineQuery != nil {
+		handleInlineQuery(ctx, bot, update.InlineQuery)
+	}
+}
+func handleCommand(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	if isGroupChat(msg.Chat) && handleGroupCommand(ctx, bot, msg) {
+		return
+	}
+	if msg.Command() == "start" {
+		if !isUserRegistered(ctx, userID) {
+			if !acceptInvite(ctx, bot, msg.Chat.ID, userID, strings.TrimSpace(msg.CommandArguments())) {
+				return
+			}
+			pendingNameInput[userID] = true
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✍️ Введите своё ФИО в формате: Фамилия И.О. (например: Иванов И.И.)"))
+			return
+		}
+		if quickKeyboardEnabled(ctx, userID) {
+			sendQuickKeyboard(bot, msg.Chat.ID)
+		}
+		sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+		return
+	}
+
+	if !isUserRegistered(ctx, userID) {
+		pendingNameInput[userID] = true
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✍️ Введите своё ФИО в формате: Фамилия И.О. (например: Иванов И.И.)"))
+		return
+	}
+
+	router.RunCommand(ctx, bot, msg)
+}
+
+func handleMessage(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+
+	if msg.Document != nil && strings.HasSuffix(strings.ToLower(msg.Document.FileName), ".xlsx") &&
+		isAdminWithRight(ctx, userID, "manage_users") {
+		handleRosterUpload(ctx, bot, msg)
+		return
+	}
+	if msg.Document != nil && isRootAdmin(ctx, userID) {
+		handleBackupUpload(ctx, bot, msg)
+		return
+	}
+	if !isGroupChat(msg.Chat) && isUserRegistered(ctx, userID) && handleQuickKeyboard(ctx, bot, msg) {
+		return
+	}
+	if pendingPhoneInput[userID] {
+		handlePhoneInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingComment[userID]; ok {
+		handleCommentInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingPhoto[userID]; ok {
+		handlePhotoInput(ctx, bot, msg)
+		return
+	}
+	if pendingGeoArrival[userID] {
+		handleGeoArrivalInput(ctx, bot, msg)
+		return
+	}
+	if msg.Location != nil && msg.Location.LivePeriod > 0 {
+		handleLiveLocation(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingDangerPhrase[userID]; ok {
+		handleDangerPhraseInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingRecordEdit[userID]; ok {
+		handleRecordEditInput(ctx, bot, msg)
+		return
+	}
+	if pendingPersonnelSearch[userID] {
+		handlePersonnelSearchInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingRename[userID]; ok {
+		handleRenameInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingMarkFor[userID]; ok {
+		handleMarkForInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingReturnInput[userID]; ok {
+		handleReturnInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingRemindFor[userID]; ok {
+		handleRemindInput(ctx, bot, msg)
+		return
+	}
+	if _, ok := pendingCustomJob[userID]; ok {
+		handleCustomJobInput(ctx, bot, msg)
+		return
+	}
+	if pendingNameInput[userID] {
+		name := strings.TrimSpace(msg.Text)
+		if claimRosterEntry(ctx, userID, name, msg.Chat.ID) {
+			delete(pendingNameInput, userID)
+			completeInvite(ctx, userID)
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Вы найдены в списке личного состава, ФИО сохранено!"))
+			if userPhones(ctx)[strconv.Itoa(userID)] == "" {
+				askPhone(bot, msg.Chat.ID, userID)
+			} else {
+				sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+			}
+		} else if normalized, ok := validate.Name(name); ok {
+			if !registrationAllowed(ctx, userID) {
+				delete(pendingNameInput, userID)
+				bot.Send(tgbotapi.NewMessage(msg.Chat.ID, inviteOnlyRefusal))
+				return
+			}
+			saveUserName(ctx, userID, normalized, msg.Chat.ID)
+			delete(pendingNameInput, userID)
+			completeInvite(ctx, userID)
+			rememberInput(msg, "name", "")
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ ФИО сохранено!"))
+			askPhone(bot, msg.Chat.ID, userID)
+		} else {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Формат неверный. Введите ФИО так: Иванов И.И."))
+		}
+		return
+	}
+	if pendingLocationInput[userID] {
+		manualLocation := strings.TrimSpace(msg.Text)
+		if manualLocation == "" || len([]rune(manualLocation)) < 3 {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Введите корректную локацию (не менее 3 символов)."))
+			return
+		}
+		if isDuplicateMark(ctx, userID, "Убыл", manualLocation, clock.Now()) {
+			delete(pendingLocationInput, userID)
+			return
+		}
+		now := clock.Now().Format(dateFormat)
+		name := getUserName(ctx, userID, msg.From)
+		saveAttendance(ctx, now, strconv.Itoa(userID), name, "Убыл", manualLocation)
+		notifyAdminAboutMark(ctx, bot, userID, name, "Убыл", manualLocation, now)
+		delete(pendingLocationInput, userID)
+		rememberInput(msg, "location", now)
+		bot.Send(departureConfirmation(msg.Chat.ID, "✅ Убытие отмечено!", now))
+		sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+		return
+	}
+}
+
+func sendMainMenu(ctx context.Context, bot Sender, chatID int64, user *tgbotapi.User) {
+	userID := user.ID
+	isAdmin := isRootAdmin(ctx, userID) || isAdminAny(ctx, userID)
+	row := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("🟢 Прибыл", "arrived"),
+		tgbotapi.NewInlineKeyboardButtonData("🔴 Убыл", "left"),
+		tgbotapi.NewInlineKeyboardButtonData("📖 Журнал", "journal"),
+	}
+	if isAdmin {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("⚙️ Админ-панель", "admin_panel"))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{row, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📋 Статус", "status_menu"),
+		tgbotapi.NewInlineKeyboardButtonData("⌨️ Кнопки внизу", "kbd_toggle"),
+	)}
+	if leaderUnit(ctx, userID) != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏷 Моё подразделение", "lead_menu"),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, "Главное меню")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+// Отметка прибытия с кнопки; возвращает текст ответа на нажатие
+func markArrived(ctx context.Context, bot Sender, chatID int64, user *tgbotapi.User) string {
+	userID := user.ID
+	if isDuplicateMark(ctx, userID, "Прибыл", "-", clock.Now()) {
+		return ""
+	}
+	lastAction, _ := getLastAction(ctx, userID)
+	if lastAction == "Прибыл" {
+		bot.Send(tgbotapi.NewMessage(chatID, "⚠️ Ты ещё не отмечал убытие — всё ок?"))
+		return "Сначала отметь убытие"
+	}
+	if geoRequired(ctx) {
+		askArrivalLocation(bot, chatID, userID)
+		return "Нужна геопозиция"
+	}
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, userID, user)
+	saveAttendance(ctx, now, strconv.Itoa(userID), name, "Прибыл", "-")
+	notifyAdminAboutMark(ctx, bot, userID, name, "Прибыл", "-", now)
+	bot.Send(markConfirmation(chatID, "✅ Прибытие отмечено!", now))
+	sendMainMenu(ctx, bot, chatID, user)
+	return "Записано!"
+}
+
+// Выбор локации для убытия; возвращает текст ответа на нажатие
+func askDeparture(ctx context.Context, bot Sender, chatID int64, userID int) string {
+	lastAction, _ := getLastAction(ctx, userID)
+	if lastAction == "Убыл" {
+		bot.Send(tgbotapi.NewMessage(chatID, "🔴 Ты уже отмечал убытие. Сначала отметь прибытие!"))
+		return "Сначала отметь прибытие"
+	}
+	msg := tgbotapi.NewMessage(chatID, "Выберите локацию, куда убыл:")
+	msg.ReplyMarkup = leaveMenu()
+	bot.Send(msg)
+	return "Выберите локацию"
+}
+
+func handleAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	user := query.From
+	userID := user.ID
+	chatID := query.Message.Chat.ID
+
+	if router.RunCallback(ctx, bot, query) {
+		return
+	}
+	if handleJournalDateCallback(ctx, bot, chatID, strconv.Itoa(userID), query.Data) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	// Кнопки выбора локации: callback — сама локация
+	for _, loc := range leaveLocations {
+		if query.Data == loc {
+			if loc == "📝 Другое" {
+				pendingLocationInput[userID] = true
+				bot.Send(tgbotapi.NewMessage(chatID, "Введите вручную, куда выбываете:"))
+				bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Жду текст"))
+			} else if photoRequired(ctx, loc) {
+				askDeparturePhoto(bot, chatID, userID, loc)
+				bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нужно фото"))
+			} else if isDuplicateMark(ctx, userID, "Убыл", loc, clock.Now()) {
+				bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+			} else {
+				now := clock.Now().Format(dateFormat)
+				name := getUserName(ctx, userID, user)
+				saveAttendance(ctx, now, strconv.Itoa(userID), name, "Убыл", loc)
+				notifyAdminAboutMark(ctx, bot, userID, name, "Убыл", loc, now)
+				bot.Send(departureConfirmation(chatID, "✅ Убытие отмечено!", now))
+				sendMainMenu(ctx, bot, chatID, user)
+				bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Записано!"))
+			}
+			return
+		}
+	}
+}
+
+// --- Админ-панель и листалки ---
+
+func sendAdminPanel(bot Sender, chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "⚙️ Админ-панель:")
+	kb := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Быстрая сводка", "summary"),
+			tgbotapi.NewInlineKeyboardButtonData("👥 Личный состав", "personnel"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📖 Журнал", "report"),
+			tgbotapi.NewInlineKeyboardButtonData("📥 Экспорт", "report"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👑 Управление админами", "manage_admins"),
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Опасная зона", "danger"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📈 Аналитика", "analytics"),
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Расписание", "cjobs"),
+		),
+	)
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+func sendPersonnelList(ctx context.Context, bot Sender, chatID int64, idx int) {
+	users := scopedUsers(ctx, chatID)
+	if len(users) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет данных о личном составе."))
+		return
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(users) {
+		idx = len(users) - 1
+	}
+	u := users[idx]
+	text := fmt.Sprintf("👤 <b>%s</b>\n🆔 <a href=\"tg://user?id=%d\">%d</a>", capitalizeName(u.Name), u.ID, u.ID)
+	if phone := userPhones(ctx)[strconv.Itoa(u.ID)]; phone != "" {
+		text += "\n📞 " + phone
+	}
+	text += personnelStatusLine(ctx, strconv.Itoa(u.ID))
+	btns := []tgbotapi.InlineKeyboardButton{}
+	if idx > 0 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("personnel_%d", idx-1)))
+	}
+	if idx < len(users)-1 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", fmt.Sprintf("personnel_%d", idx+1)))
+	}
+	// Действия с карточкой, по две кнопки в ряд
+	actions := []tgbotapi.InlineKeyboardButton{}
+	// Кнопка "Назначить админом" (только если не root)
+	if u.ID != rootAdminID(ctx) {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("👑 Назначить админом", fmt.Sprintf("makeadmin_%d", idx)))
+	}
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("📖 Журнал", fmt.Sprintf("ujpage_%d_all_0", u.ID)))
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("✏️ Записи", fmt.Sprintf("erec_%d", u.ID)))
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("📝 Отметить за...", fmt.Sprintf("markfor_%d", u.ID)))
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("✍️ Изменить ФИО", fmt.Sprintf("urename_%d", u.ID)))
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🏷 Подразделение", fmt.Sprintf("uunit_%d", u.ID)))
+	actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("⏰ Напоминание", fmt.Sprintf("uremind_%d", u.ID)))
+	if u.ID != rootAdminID(ctx) {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🗄 В архив", fmt.Sprintf("uarch_%d", u.ID)))
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🚫 Заблокировать", fmt.Sprintf("uban_%d", u.ID)))
+	}
+	if u.ID != rootAdminID(ctx) && isRootAdmin(ctx, int(chatID)) {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", fmt.Sprintf("udel_%d", u.ID)))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if len(btns) > 0 {
+		rows = append(rows, btns)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск", "psearch"),
+		tgbotapi.NewInlineKeyboardButtonData("🔤 А–Я", "palpha"),
+	))
+	for i := 0; i < len(actions); i += 2 {
+		end := i + 2
+		if end > len(actions) {
+			end = len(actions)
+		}
+		rows = append(rows, actions[i:end])
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+func sendAdminsList(ctx context.Context, bot Sender, chatID int64, idx int) {
+	admins := getAdmins(ctx)
+	if len(admins) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет других админов."))
+		return
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(admins) {
+		idx = len(admins) - 1
+	}
+	a := admins[idx]
+	text := fmt.Sprintf("👑 <b>%s</b>\n🆔 <a href=\"tg://user?id=%d\">%d</a>\nПрава:", a.Name, a.ID, a.ID)
+	for _, r := range adminRights {
+		check := "⬜️"
+		if a.Rights[r.Code] {
+			check = "✅"
+		}
+		text += fmt.Sprintf("\n%s %s", check, r.Name)
+	}
+	btns := []tgbotapi.InlineKeyboardButton{}
+	if idx > 0 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("adminlist_%d", idx-1)))
+	}
+	if idx < len(admins)-1 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", fmt.Sprintf("adminlist_%d", idx+1)))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if len(btns) > 0 {
+		rows = append(rows, btns)
+	}
+	if isRootAdmin(ctx, int(chatID)) && a.ID != rootAdminID(ctx) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Снять права", fmt.Sprintf("demote_%d", a.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📜 История прав", "rights_history"),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+func sendPersonnelForAdmin(ctx context.Context, bot Sender, chatID int64, idx int) {
+	users := getSortedUsers(ctx)
+	if len(users) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет данных о личном составе."))
+		return
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(users) {
+		idx = len(users) - 1
+	}
+	u := users[idx]
+	text := fmt.Sprintf("👤 <b>%s</b>\n🆔 <a href=\"tg://user?id=%d\">%d</a>", capitalizeName(u.Name), u.ID, u.ID)
+	btns := []tgbotapi.InlineKeyboardButton{}
+	if idx > 0 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("personnel_%d", idx-1)))
+	}
+	if idx < len(users)-1 {
+		btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", fmt.Sprintf("personnel_%d", idx+1)))
+	}
+	btns = append(btns, tgbotapi.NewInlineKeyboardButtonData("👑 Назначить админом", fmt.Sprintf("makeadmin_%d", idx)))
+	kb := tgbotapi.NewInlineKeyboardMarkup(btns)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+// Чекбокс-меню для назначения прав
+func sendRightsCheckboxMenu(ctx context.Context, bot Sender, chatID int64, userID int, selected map[string]bool) {
+	if selected == nil {
+		selected = getAdminRights(ctx, userID)
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{rolePresetRow(userID)}
+	for _, right := range adminRights {
+		check := "⬜️"
+		if selected[right.Code] {
+			check = "✅"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s %s", check, right.Name), fmt.Sprintf("right_%s_%d", right.Code, userID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("💾 Сохранить", fmt.Sprintf("save_rights_%d", userID)),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msg := tgbotapi.NewMessage(chatID, "Выберите роль-шаблон или отметьте права вручную:")
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+// --- Админ-фильтры, экспорт Excel ---
+
+func reportFilterMenu() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Сегодня", "export_today"),
+			tgbotapi.NewInlineKeyboardButtonData("📆 Вчера", "export_yesterday"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗓️ 7 дней", "export_7days"),
+			tgbotapi.NewInlineKeyboardButtonData("🗓️ 30 дней", "export_30days"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Опоздания 7 дней", "late_7"),
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Опоздания 30 дней", "late_30"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏷 По подразделению", "uexp"),
+		),
+	)
+}
+
+// since — начало периода: читаются только записи с этого дня по сегодня
+func sendFilteredExcel(ctx context.Context, bot Sender, chatID int64, since time.Time, filter func([]string) bool) {
+	rows := journal.ReadRange(ctx, since, daysAgo(-1))
+	anomalies := detectAnomalies(ctx, rows)
+	var filtered [][]string
+	for _, row := range rows {
+		if filter(row) && len(row) > 1 && adminSeesUser(ctx, chatID, row[1]) {
+			filtered = append(filtered, row)
+		}
+	}
+	if len(filtered) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет данных по выбранному фильтру."))
+		return
+	}
+	if len(filtered) > exportLimit {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Слишком большой экспорт! (>%d записей)", exportLimit)))
+		return
+	}
+	f := buildReportWorkbook(ctx, filtered, anomalies)
+	filename := fmt.Sprintf("report_%d.xlsx", clock.Now().Unix())
+	err := f.SaveAs(filename)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания Excel файла"))
+		return
+	}
+	defer os.Remove(filename)
+	excelFile, err := os.Open(filename)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка отправки отчёта"))
+		return
+	}
+	defer excelFile.Close()
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{
+		Name:   "Отчёт_Табель.xlsx",
+		Reader: excelFile,
+		Size:   -1,
+	})
+	doc.Caption = "📊 Отчёт по табелю"
+	bot.Send(doc)
+	incMetric("tabel_exports_total", `via="bot"`)
+}
+
+// Лист «Отчёт» по строкам журнала; anomalies — из detectAnomalies
+func buildReportWorkbook(ctx context.Context, filtered [][]string, anomalies map[string][]string) *excelize.File {
+	units := userUnits(ctx)
+	var out []export.ReportRow
+	for _, row := range filtered {
+		for len(row) < 5 {
+			row = append(row, "-")
+		}
+		action := row[3]
+		date, timePart := splitDateTime(row[0])
+		note := ""
+		if adminID := markEnteredBy(row); adminID != 0 {
+			note = "Внесено админом: " + getUserName(ctx, adminID, nil)
+		}
+		if isAutoMark(row) {
+			note = "Автоотметка по геозоне"
+		}
+		if c := markComment(row); c != "" {
+			note = strings.TrimSpace(note + " Комментарий: " + c)
+		}
+		if markIsFar(ctx, row) {
+			d, _ := markDistance(row)
+			note = strings.TrimSpace(note + fmt.Sprintf(" Вне геозоны: %.0f м", d))
+		}
+		if titles := anomalies[anomalyKey(row)]; len(titles) > 0 {
+			note = strings.TrimSpace(note + " Подозрительно: " + strings.Join(titles, ", "))
+		}
+		fill := ""
+		if action == "Прибыл" {
+			fill = "#D8F6CE"
+		} else if action == "Убыл" {
+			fill = "#FFD6D6"
+		} else if st, ok := findStatus(action); ok {
+			fill = st.Fill
+		}
+		out = append(out, export.ReportRow{
+			Date: date, Time: timePart, Name: row[2], Action: action, Location: cleanLocation(row[4]),
+			Note: note, Unit: units[row[1]], Fill: fill,
+		})
+	}
+	return export.Report(out)
+}
+
+// --- Логика фильтров даты ---
+
+func filterToday(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	today := clock.Now().Format("02.01.2006")
+	return strings.HasPrefix(row[0], today)
+}
+func filterYesterday(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	yesterday := clock.Now().AddDate(0, 0, -1).Format("02.01.2006")
+	return strings.HasPrefix(row[0], yesterday)
+}
+func filterLastNDays(n int) func([]string) bool {
+	return func(row []string) bool {
+		if len(row) == 0 {
+			return false
+		}
+		layout := "02.01.2006 15:04:05"
+		t, err := time.Parse(layout, row[0])
+		if err != nil {
+			return false
+		}
+		return t.After(clock.Now().AddDate(0, 0, -n-1))
+	}
+}
+
+// Начало дня n дней назад
+func daysAgo(n int) time.Time {
+	now := clock.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()-n, 0, 0, 0, 0, now.Location())
+}
+
+// Записи в интервале [from, to)
+func filterRange(from, to time.Time) func([]string) bool {
+	return func(row []string) bool {
+		if len(row) == 0 {
+			return false
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil {
+			return false
+		}
+		return !t.Before(from) && t.Before(to)
+	}
+}
+
+// --- Чистка эмодзи для Excel ---
+
+func cleanLocation(loc string) string {
+	s := emojiRegex.ReplaceAllString(loc, "")
+	return strings.TrimSpace(s)
+}
+
+// --- Поддержка меню локаций ---
+
+func leaveMenu() tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for i := 0; i < len(leaveLocations); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(leaveLocations[i], leaveLocations[i]),
+		}
+		if i+1 < len(leaveLocations) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(leaveLocations[i+1], leaveLocations[i+1]))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// --- Сводка для админа ---
+
+func adminSummary(ctx context.Context, bot Sender, chatID int64) {
+	adminSummaryAt(ctx, bot, chatID, clock.Now())
+}
+
+// Сводка на момент at: положение по отметкам до at и опоздавшие за тот день
+func adminSummaryAt(ctx context.Context, bot Sender, chatID int64, at time.Time) {
+	if unit := adminScope(ctx, int(chatID)); unit != "" {
+		deliver(bot, tgbotapi.NewMessage(chatID, unitSummaryAt(ctx, unit, at)), "сводка")
+		return
+	}
+	s := loadPresenceAt(ctx, at)
+	msg := tgbotapi.NewMessage(chatID, s.text(nil)+s.unitBreakdown()+lateSection(ctx, at))
+	if rows := unitPickerRows(ctx, "usum_"); len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	deliver(bot, msg, "сводка")
+}
+
+// Списки «в части / вне части» по последним отметкам
+func presenceText(ctx context.Context) string {
+	s := loadPresence(ctx)
+	return s.text(nil) + s.unitBreakdown()
+}
+
+// Всё, что нужно для сводки, читается один раз: пользователи, их
+// подразделения и таблица последних отметок
+type presenceSnapshot struct {
+	Users []User
+	Last  map[string][]string
+	Units map[string]string
+}
+
+func loadPresence(ctx context.Context) presenceSnapshot {
+	return presenceSnapshot{getSortedUsers(ctx), lastRows(ctx), userUnits(ctx)}
+}
+
+func loadPresenceAt(ctx context.Context, at time.Time) presenceSnapshot {
+	return presenceSnapshot{getSortedUsers(ctx), lastRowsAt(ctx, at), userUnits(ctx)}
+}
+
+// include == nil — все пользователи, иначе только те, для кого include(ID) вернул true
+func (s presenceSnapshot) text(include func(userID string) bool) string {
+	type OutUser struct {
+		Name     string
+		Location string
+		Return   string
+		Comment  string
+	}
+	var inList []string
+	var outUsers []OutUser
+	byStatus := make(map[string][]string)
+	for _, u := range s.Users {
+		userID := strconv.Itoa(u.ID)
+		if include != nil && !include(userID) {
+			continue
+		}
+		row := s.Last[userID]
+		if row == nil {
+			continue
+		}
+		action, loc := row[3], row[4]
+		cleanName := capitalizeName(u.Name)
+		if action == "Прибыл" {
+			inList = append(inList, cleanName)
+		} else if action == "Убыл" {
+			ret := ""
+			if t, ok := expectedReturn(row); ok {
+				ret = formatExpectedReturn(t)
+			}
+			outUsers = append(outUsers, OutUser{cleanName, cleanLocation(loc), ret, markComment(row)})
+		} else if _, ok := findStatus(action); ok {
+			byStatus[action] = append(byStatus[action], cleanName)
+		}
+	}
+	sort.Strings(inList)
+	sort.Slice(outUsers, func(i, j int) bool {
+		return outUsers[i].Name < outUsers[j].Name
+	})
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("👥 В части (%d):\n", len(inList)))
+	for _, name := range inList {
+		b.WriteString("— " + name + "\n")
+	}
+	if len(outUsers) > 0 {
+		b.WriteString(fmt.Sprintf("\n🚶 Вне части (%d):\n", len(outUsers)))
+		for _, ou := range outUsers {
+			comment := ""
+			if ou.Comment != "" {
+				comment = " 💬 " + ou.Comment
+			}
+			if ou.Return != "" {
+				b.WriteString(fmt.Sprintf("— %s (%s, вернётся %s)%s\n", ou.Name, ou.Location, ou.Return, comment))
+			} else {
+				b.WriteString(fmt.Sprintf("— %s (%s)%s\n", ou.Name, ou.Location, comment))
+			}
+		}
+	}
+	for _, st := range statuses {
+		names := byStatus[st.Action]
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		b.WriteString(fmt.Sprintf("\n%s %s (%d):\n", st.Emoji, st.Action, len(names)))
+		for _, name := range names {
+			b.WriteString("— " + name + "\n")
+		}
+	}
+	return b.String()
+}
+
+func getAllUserNames(ctx context.Context) []string {
+	var names []string
+	for _, row := range loadUserRegistry(ctx).Rows {
+		if len(row) > colUserArchived && row[colUserArchived] == "1" {
+			continue
+		}
+		if len(row) > 1 {
+			names = append(names, row[1])
+		}
+	}
+	return names
+}
+func getUserIDByName(ctx context.Context, name string) string {
+	return loadUserRegistry(ctx).ByName[name]
+}
+func getLastActionStr(ctx context.Context, userID string) (action, location string) {
+	if row := findLastRow(ctx, userID); row != nil {
+		return row[3], row[4]
+	}
+	return "", ""
+}
+func capitalizeName(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+// --- Проверки и валидации ---
+
+func isUserRegistered(ctx context.Context, userID int) bool {
+	_, ok := loadUserRegistry(ctx).ByID[strconv.Itoa(userID)]
+	return ok
+}
+
+func getUserName(ctx context.Context, userID int, u *tgbotapi.User) string {
+	if row, ok := loadUserRegistry(ctx).ByID[strconv.Itoa(userID)]; ok && len(row) > 1 {
+		return row[1]
+	}
+	if u != nil {
+		return fmt.Sprintf("%s %s.%s.", u.LastName, string([]rune(u.FirstName)[0]), string([]rune(u.UserName)[0]))
+	}
+	return "Неизвестно"
+}
+func saveUserName(ctx context.Context, userID int, name string, chatID int64) {
+	idStr := strconv.Itoa(userID)
+	updateCSV(ctx, usersFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) > 0 && row[0] == idStr {
+				rows[i][1] = name
+				return rows
+			}
+		}
+		return append(rows, []string{idStr, name, strconv.FormatInt(chatID, 10)})
+	})
+}
+
+// Записывает колонку col в строку пользователя users.csv; false — не найден
+func setUserField(ctx context.Context, userID, col int, value string) bool {
+	idStr := strconv.Itoa(userID)
+	found := false
+	updateCSV(ctx, usersFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) < 3 || row[0] != idStr {
+				continue
+			}
+			for len(rows[i]) <= col {
+				rows[i] = append(rows[i], "")
+			}
+			rows[i][col] = value
+			found = true
+			break
+		}
+		return rows
+	})
+	return found
+}
+func getLastAction(ctx context.Context, userID int) (action, location string) {
+	return getLastActionStr(ctx, strconv.Itoa(userID))
+}
+func getLastActions(ctx context.Context, userID string, n int) [][]string {
+	var filtered [][]string
+	// Рабочий файл, затем архивы от новых к старым
+	files := append([]string{dataFile}, reverseStrings(journal.Archives())...)
+	for _, f := range files {
+		rows := readCSV(ctx, f)
+		for i := len(rows) - 1; i >= 0 && len(filtered) < n; i-- {
+			if len(rows[i]) > 1 && rows[i][1] == userID {
+				filtered = append(filtered, rows[i])
+			}
+		}
+		if len(filtered) >= n {
+			break
+		}
+	}
+	for i, j := 0, len(filtered)-1; i < j; i, j = i+1, j-1 {
+		filtered[i], filtered[j] = filtered[j], filtered[i]
+	}
+	return filtered
+}
+func reverseStrings(s []string) []string {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+	return s
+}
+func splitDateTime(dt string) (string, string) {
+	parts := strings.SplitN(dt, " ", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return dt, ""
+}
+
+// --- CSV-файлы ---
+
+// --- Логика админов/прав ---
+
+func isRootAdmin(ctx context.Context, userID int) bool {
+	return userID == rootAdminID(ctx)
+}
+func isAdminAny(ctx context.Context, userID int) bool {
+	if isRootAdmin(ctx, userID) {
+		return true
+	}
+	idStr := strconv.Itoa(userID)
+	rows := readCSV(ctx, adminsFile)
+	for _, row := range rows {
+		if len(row) > 1 && row[0] == idStr {
+			return true
+		}
+	}
+	return false
+}
+func isAdminWithRight(ctx context.Context, userID int, code string) bool {
+	if isRootAdmin(ctx, userID) {
+		return true
+	}
+	idStr := strconv.Itoa(userID)
+	rows := readCSV(ctx, adminsFile)
+	for _, row := range rows {
+		if len(row) > 2 && row[0] == idStr {
+			for i, r := range adminRights {
+				if r.Code == code && len(row) > i+2 && row[i+2] == "1" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+func getAdmins(ctx context.Context) []Admin {
+	rows := readCSV(ctx, adminsFile)
+	var admins []Admin
+	for _, row := range rows {
+		if len(row) >= 3 {
+			id, _ := strconv.Atoi(row[0])
+			name := row[1]
+			rights := make(map[string]bool)
+			for i, r := range adminRights {
+				if len(row) > i+2 && row[i+2] == "1" {
+					rights[r.Code] = true
+				}
+			}
+			admins = append(admins, Admin{ID: id, Name: name, Rights: rights})
+		}
+	}
+	return admins
+}
+
+// Активный личный состав (без архивных)
+func getSortedUsers(ctx context.Context) []User {
+	var users []User
+	for _, u := range getAllUsers(ctx) {
+		if !u.Archived {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// Все пользователи, включая переведённых в архив
+func getAllUsers(ctx context.Context) []User {
+	var all []User
+	for _, row := range loadUserRegistry(ctx).Rows {
+		if len(row) >= 3 {
+			uid, _ := strconv.Atoi(row[0])
+			name := capitalizeName(row[1])
+			cid, _ := strconv.ParseInt(row[2], 10, 64)
+			archived := len(row) > colUserArchived && row[colUserArchived] == "1"
+			all = append(all, User{ID: uid, Name: name, ChatID: cid, Archived: archived})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+func getAdminRights(ctx context.Context, userID int) map[string]bool {
+	idStr := strconv.Itoa(userID)
+	rows := readCSV(ctx, adminsFile)
+	for _, row := range rows {
+		if len(row) > 1 && row[0] == idStr {
+			rights := make(map[string]bool)
+			for i, r := range adminRights {
+				if len(row) > i+2 && row[i+2] == "1" {
+					rights[r.Code] = true
+				}
+			}
+			return rights
+		}
+	}
+	return make(map[string]bool)
+}
+func saveAdminRights(ctx context.Context, userID int, name string, rights map[string]bool) {
+	idStr := strconv.Itoa(userID)
+	newRow := []string{idStr, name}
+	for _, r := range adminRights {
+		if rights[r.Code] {
+			newRow = append(newRow, "1")
+		} else {
+			newRow = append(newRow, "0")
+		}
+	}
+	updateCSV(ctx, adminsFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) > 0 && row[0] == idStr {
+				rows[i] = newRow
+				return rows
+			}
+		}
+		return append(rows, newRow)
+	})
+}
+
+// --- Сохранение и уведомление ---
+
+func saveAttendance(ctx context.Context, dt, uid, name, action, location string) {
+	saveAttendanceRow(ctx, []string{dt, uid, name, action, location})
+}
+
+// Отметка, внесённая админом за пользователя: в 6-й колонке admin:<ID>
+func saveAttendanceByAdmin(ctx context.Context, dt, uid, name, action, location string, adminID int) {
+	saveAttendanceRow(ctx, []string{dt, uid, name, action, location, fmt.Sprintf("admin:%d", adminID)})
+}
+
+func saveAttendanceRow(ctx context.Context, row []string) {
+	journal.Rotate(ctx, clock.Now())
+	fresh := statusTableFresh()
+	journal.WriteMark(ctx, row)
+	recordLastRow(ctx, fresh, row)
+	syncMarkToSheet(row[0], row[2], row[3], row[4])
+	refreshStatusBoard()
+	checkNewMarkAnomalies(ctx, row)
+	publishMark(row)
+	incMetric("tabel_marks_total", "")
+}
+
+// Кто внёс отметку: ID админа или 0, если сам пользователь
+func markEnteredBy(row []string) int {
+	if len(row) > colSource && strings.HasPrefix(row[colSource], "admin:") {
+		id, _ := strconv.Atoi(strings.TrimPrefix(row[colSource], "admin:"))
+		return id
+	}
+	return 0
+}
+
+// Уведомление о каждой отметке: главному админу и админам с правом notifications
+func notifyAdminAboutMark(ctx context.Context, bot Sender, userID int, fio string, action string, location string, datetime string) {
+	var emoji, locationLine string
+	if action == "Прибыл" {
+		emoji = "🟢"
+		locationLine = "📍 Локация: -"
+	} else {
+		emoji = actionEmoji(action)
+		locationLine = fmt.Sprintf("📍 Локация: %s", cleanLocation(location))
+	}
+	txt := fmt.Sprintf(
+		"📋 <b>Новая отметка</b>\n"+
+			"👤 <b>ФИО:</b> %s\n"+
+			"🆔 <b>ID:</b> %d\n"+
+			"⏰ <b>Время:</b> %s\n"+
+			"⚡ <b>Действие:</b> %s %s\n"+
+			"%s",
+		fio, userID, datetime, emoji, action, locationLine)
+	for _, chatID := range adminRecipients(ctx, "notifications") {
+		if adminSeesUser(ctx, chatID, strconv.Itoa(userID)) {
+			sendAdminNotification(ctx, bot, chatID, txt)
+		}
+	}
+}
+
+// --- Ежедневная сводка для командира (19:00) ---
+
+// Сводка за момент now; догнанный после простоя отчёт приходит с его
+// датой и временем и строится по отметкам до now, а не по текущим
+func sendDailyReport(ctx context.Context, bot Sender, now time.Time) {
+	adminSummaryAt(ctx, bot, int64(rootAdminID(ctx)), now)
+	sendDailyCharts(ctx, bot, int64(rootAdminID(ctx)), now)
+	// Закреплённым за подразделением — сводка по нему, если у
+	// подразделения нет своего расписания (задача report:<подразделение>)
+	for _, chatID := range scopedAdmins(ctx) {
+		if unitReportSpec(ctx, adminScope(ctx, int(chatID))) == "" {
+			adminSummaryAt(ctx, bot, chatID, now)
+		}
+	}
+	s := loadPresenceAt(ctx, now)
+	postToChannel(bot, "📊 Сводка на "+now.Format("02.01 15:04")+"\n\n"+s.text(nil)+s.unitBreakdown(), "")
+}
+
+// --- Автоэкспорт: неделя по понедельникам, месяц 1-го числа ---
+
+func sendAutoExports(ctx context.Context, bot Sender, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	chats := autoExportChats(ctx)
+	if today.Weekday() == time.Monday {
+		from := today.AddDate(0, 0, -7)
+		for _, chatID := range chats {
+			sendFilteredExcel(ctx, bot, chatID, from, filterRange(from, today))
+		}
+	}
+	if today.Day() == 1 {
+		from := today.AddDate(0, -1, 0)
+		for _, chatID := range chats {
+			sendFilteredExcel(ctx, bot, chatID, from, filterRange(from, today))
+		}
+	}
+}
+
+// Список чатов из AUTO_EXPORT_CHATS (через запятую), по умолчанию — главный админ
+func autoExportChats(ctx context.Context) []int64 {
+	var chats []int64
+	for _, s := range strings.Split(os.Getenv("AUTO_EXPORT_CHATS"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Printf("AUTO_EXPORT_CHATS: неверный ID %q", s)
+			continue
+		}
+		chats = append(chats, id)
+	}
+	if len(chats) == 0 {
+		chats = append(chats, int64(rootAdminID(ctx)))
+	}
+	return chats
+}
+
+// --- Конец bot.go ---
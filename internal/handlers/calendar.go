@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Производственный календарь части ---
+//
+// Единый источник типа дня для планировщика, учёта опозданий и табеля.
+// Выходные дни недели — настройка off_weekdays (1=Пн … 7=Вс, по умолчанию «6,7»
+// или OFF_WEEKDAYS). Государственные праздники РФ учитываются, пока настройка
+// public_holidays не выключена. В holidays.csv (дата ДД.ММ.ГГГГ, название, тип)
+// задаются свои дни: holiday — праздник, park — парковый день, work — рабочий
+// день вместо выходного (перенос).
+
+const holidaysFile = "holidays.csv"
+
+type DayType int
+
+const (
+	dayWork DayType = iota
+	dayPark
+	dayWeekend
+	dayHoliday
+)
+
+// Государственные праздники (ДД.ММ)
+var publicHolidays = map[string]string{
+	"01.01": "Новогодние каникулы", "02.01": "Новогодние каникулы", "03.01": "Новогодние каникулы",
+	"04.01": "Новогодние каникулы", "05.01": "Новогодние каникулы", "06.01": "Новогодние каникулы",
+	"07.01": "Рождество", "08.01": "Новогодние каникулы",
+	"23.02": "День защитника Отечества", "08.03": "Международный женский день",
+	"01.05": "Праздник Весны и Труда", "09.05": "День Победы",
+	"12.06": "День России", "04.11": "День народного единства",
+}
+
+func init() {
+	backupFiles = append(backupFiles, holidaysFile)
+}
+
+func (d DayType) String() string {
+	switch d {
+	case dayPark:
+		return "Парковый день"
+	case dayWeekend:
+		return "Выходной"
+	case dayHoliday:
+		return "Праздник"
+	}
+	return "Рабочий день"
+}
+
+// Краткое обозначение для табеля
+func (d DayType) Short() string {
+	switch d {
+	case dayPark:
+		return "ПД"
+	case dayWeekend:
+		return "В"
+	case dayHoliday:
+		return "П"
+	}
+	return ""
+}
+
+func offWeekdaysSetting(ctx context.Context) string {
+	def := os.Getenv("OFF_WEEKDAYS")
+	if def == "" {
+		def = "6,7"
+	}
+	return getSetting(ctx, "off_weekdays", def)
+}
+
+func offWeekdays(ctx context.Context) map[time.Weekday]bool {
+	days := make(map[time.Weekday]bool)
+	for _, s := range strings.Split(offWeekdaysSetting(ctx), ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err == nil && n >= 1 && n <= 7 {
+			days[time.Weekday(n%7)] = true
+		}
+	}
+	return days
+}
+
+// Календарь, загруженный один раз для расчётов по многим дням
+type workCalendar struct {
+	off    map[time.Weekday]bool
+	public bool
+	custom map[string][]string // ДД.ММ.ГГГГ -> строка holidays.csv
+}
+
+func loadWorkCalendar(ctx context.Context) *workCalendar {
+	c := &workCalendar{
+		off:    offWeekdays(ctx),
+		public: getSetting(ctx, "public_holidays", "on") != "off",
+		custom: make(map[string][]string),
+	}
+	for _, row := range readCSV(ctx, holidaysFile) {
+		if len(row) > 0 {
+			c.custom[row[0]] = row
+		}
+	}
+	return c
+}
+
+// Тип дня и его название (для праздников)
+func (c *workCalendar) Day(t time.Time) (DayType, string) {
+	if row, ok := c.custom[t.Format("02.01.2006")]; ok {
+		name := ""
+		if len(row) > 1 {
+			name = row[1]
+		}
+		kind := "holiday"
+		if len(row) > 2 {
+			kind = row[2]
+		}
+		switch kind {
+		case "park":
+			return dayPark, name
+		case "work":
+			return dayWork, name
+		default:
+			return dayHoliday, name
+		}
+	}
+	if c.public {
+		if name, ok := publicHolidays[t.Format("02.01")]; ok {
+			return dayHoliday, name
+		}
+	}
+	if c.off[t.Weekday()] {
+		return dayWeekend, ""
+	}
+	return dayWork, ""
+}
+
+func (c *workCalendar) IsDutyDay(t time.Time) bool {
+	d, _ := c.Day(t)
+	return d == dayWork || d == dayPark
+}
+
+func dayTypeOf(ctx context.Context, t time.Time) DayType {
+	d, _ := loadWorkCalendar(ctx).Day(t)
+	return d
+}
+
+func isDutyDay(ctx context.Context, t time.Time) bool {
+	return loadWorkCalendar(ctx).IsDutyDay(t)
+}
+
+// /holidays — список, /holidays add|park|work ДД.ММ.ГГГГ [Название],
+// /holidays del ДД.ММ.ГГГГ, /holidays weekdays 6,7, /holidays public on|off
+func handleHolidaysCommand(ctx context.Context, bot Sender, chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		rows := readCSV(ctx, holidaysFile)
+		sort.Slice(rows, func(i, j int) bool {
+			ti, _ := time.Parse("02.01.2006", rows[i][0])
+			tj, _ := time.Parse("02.01.2006", rows[j][0])
+			return ti.Before(tj)
+		})
+		cal := loadWorkCalendar(ctx)
+		var b strings.Builder
+		b.WriteString("📅 Выходные дни недели: " + offWeekdaysSetting(ctx) + "\n")
+		if cal.public {
+			b.WriteString("🇷🇺 Государственные праздники: учитываются\n")
+		} else {
+			b.WriteString("🇷🇺 Государственные праздники: не учитываются\n")
+		}
+		b.WriteString("\n🗓 Особые дни:\n")
+		if len(rows) == 0 {
+			b.WriteString("— нет\n")
+		}
+		for _, row := range rows {
+			t, err := time.ParseInLocation("02.01.2006", row[0], time.Local)
+			if err != nil {
+				continue
+			}
+			d, name := cal.Day(t)
+			b.WriteString(fmt.Sprintf("— %s %s: %s\n", row[0], d, name))
+		}
+		b.WriteString("\nПраздник: /holidays add 09.05.2025 День Победы\n" +
+			"Парковый день: /holidays park 14.03.2025\n" +
+			"Рабочий вместо выходного: /holidays work 01.11.2025\n" +
+			"Удалить: /holidays del 09.05.2025\n" +
+			"Выходные: /holidays weekdays 6,7\n" +
+			"Госпраздники: /holidays public on|off")
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+		return
+	}
+	switch fields[0] {
+	case "add", "park", "work":
+		if len(fields) < 2 {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /holidays "+fields[0]+" ДД.ММ.ГГГГ Название"))
+			return
+		}
+		if _, err := time.Parse("02.01.2006", fields[1]); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Дата в формате ДД.ММ.ГГГГ"))
+			return
+		}
+		kind := map[string]string{"add": "holiday", "park": "park", "work": "work"}[fields[0]]
+		name := strings.Join(fields[2:], " ")
+		if name == "" {
+			name = map[string]string{"holiday": "Выходной", "park": "Парковый день", "work": "Рабочий день"}[kind]
+		}
+		updateCSV(ctx, holidaysFile, func(rows [][]string) [][]string {
+			var keep [][]string
+			for _, row := range rows {
+				if len(row) > 0 && row[0] != fields[1] {
+					keep = append(keep, row)
+				}
+			}
+			return append(keep, []string{fields[1], name, kind})
+		})
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Добавлено: "+fields[1]+" "+name))
+	case "del":
+		if len(fields) < 2 {
+			return
+		}
+		removeRows(ctx, holidaysFile, func(row []string) bool { return row[0] == fields[1] })
+		bot.Send(tgbotapi.NewMessage(chatID, "🗑 Удалено: "+fields[1]))
+	case "weekdays":
+		if len(fields) < 2 {
+			return
+		}
+		for _, s := range strings.Split(fields[1], ",") {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > 7 {
+				bot.Send(tgbotapi.NewMessage(chatID, "❗ Дни недели числами 1-7 через запятую, например 6,7"))
+				return
+			}
+		}
+		setSetting(ctx, "off_weekdays", fields[1])
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Выходные дни недели: "+fields[1]))
+	case "public":
+		if len(fields) < 2 || (fields[1] != "on" && fields[1] != "off") {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /holidays public on|off"))
+			return
+		}
+		setSetting(ctx, "public_holidays", fields[1])
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Государственные праздники: "+fields[1]))
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Публикация в Telegram-канал ---
+//
+// SUMMARY_CHANNEL_ID — ID канала (-100...) или @username; бот должен быть
+// админом канала. CHANNEL_EVENTS=1 — публиковать ещё и важные события
+// (невозвращение в срок).
+
+func summaryChannel() string {
+	if sandboxMode {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv("SUMMARY_CHANNEL_ID"))
+}
+
+func channelEventsEnabled() bool {
+	return summaryChannel() != "" && os.Getenv("CHANNEL_EVENTS") == "1"
+}
+
+func channelMessage(channel, text string) tgbotapi.MessageConfig {
+	if id, err := strconv.ParseInt(channel, 10, 64); err == nil {
+		return tgbotapi.NewMessage(id, text)
+	}
+	return tgbotapi.NewMessageToChannel(channel, text)
+}
+
+func postToChannel(bot Sender, text, parseMode string) {
+	channel := summaryChannel()
+	if channel == "" {
+		return
+	}
+	msg := channelMessage(channel, text)
+	msg.ParseMode = parseMode
+	if _, err := bot.Send(msg); err != nil {
+		log.Printf("channel: %v", err)
+	}
+}
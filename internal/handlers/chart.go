@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Графики PNG для сводок ---
+//
+// Рисуются стандартной библиотекой без внешних зависимостей. Подписи на самом
+// графике — только цифры (встроенный шрифт 3x5), текст — в подписи к фото.
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	chartMargin = 40
+	glyphScale  = 2
+)
+
+var (
+	chartBg   = color.RGBA{255, 255, 255, 255}
+	chartAxis = color.RGBA{90, 90, 90, 255}
+	chartText = color.RGBA{40, 40, 40, 255}
+	chartIn   = color.RGBA{76, 175, 80, 255}
+	chartOut  = color.RGBA{229, 57, 53, 255}
+)
+
+// Шрифт 3x5: строки сверху вниз, биты слева направо
+var chartGlyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {7, 1, 7, 4, 7}, '3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1}, '5': {7, 4, 7, 1, 7}, '6': {7, 4, 7, 5, 7}, '7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7}, '9': {7, 5, 7, 1, 7}, ':': {0, 2, 0, 2, 0}, '.': {0, 0, 0, 0, 2},
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func textWidth(s string) int {
+	return len([]rune(s)) * 4 * glyphScale
+}
+
+// Рисует строку из цифр с левым верхним углом в (x, y)
+func drawText(img *image.RGBA, x, y int, s string, c color.Color) {
+	for _, ch := range s {
+		g, ok := chartGlyphs[ch]
+		if ok {
+			for row := 0; row < 5; row++ {
+				for col := 0; col < 3; col++ {
+					if g[row]&(1<<(2-col)) != 0 {
+						fillRect(img, image.Rect(x+col*glyphScale, y+row*glyphScale, x+(col+1)*glyphScale, y+(row+1)*glyphScale), c)
+					}
+				}
+			}
+		}
+		x += 4 * glyphScale
+	}
+}
+
+// Столбчатая диаграмма: подписи под столбцами и значения над ними
+func renderBarChart(values []int, labels []string, barColor color.Color) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	fillRect(img, img.Bounds(), chartBg)
+	left, right := chartMargin, chartWidth-chartMargin/2
+	top, bottom := chartMargin/2, chartHeight-chartMargin
+	fillRect(img, image.Rect(left, bottom, right, bottom+2), chartAxis)
+	fillRect(img, image.Rect(left-2, top, left, bottom+2), chartAxis)
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	if len(values) == 0 {
+		return encodePNG(img)
+	}
+	slot := (right - left) / len(values)
+	barW := slot * 2 / 3
+	if barW < 2 {
+		barW = 2
+	}
+	for i, v := range values {
+		x := left + i*slot + (slot-barW)/2
+		h := 0
+		if max > 0 {
+			h = v * (bottom - top - 20) / max
+		}
+		fillRect(img, image.Rect(x, bottom-h, x+barW, bottom), barColor)
+		if v > 0 {
+			s := strconv.Itoa(v)
+			drawText(img, x+(barW-textWidth(s))/2, bottom-h-14, s, chartText)
+		}
+		if i < len(labels) && textWidth(labels[i]) <= slot {
+			drawText(img, x+(barW-textWidth(labels[i]))/2, bottom+8, labels[i], chartText)
+		}
+	}
+	return encodePNG(img)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Численность в части по часам за день now, до часа now
+func presenceChartToday(ctx context.Context, now time.Time) ([]byte, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	rows := journal.ReadSince(ctx, today.AddDate(0, -1, 0))
+	var samples []time.Time
+	var labels []string
+	for h := 0; h <= now.Hour(); h++ {
+		samples = append(samples, time.Date(today.Year(), today.Month(), today.Day(), h, 0, 0, 0, time.Local))
+		labels = append(labels, strconv.Itoa(h))
+	}
+	return renderBarChart(presenceSamples(rows, samples), labels, chartIn)
+}
+
+// Убытия по локациям за период; подписи локаций — в legend
+func locationsChart(ctx context.Context, from, to time.Time) (data []byte, legend string, err error) {
+	rows := journal.ReadSince(ctx, from.AddDate(0, -1, 0))
+	locs := topLocations(collectAbsences(rows, "", from, to), 10)
+	var values []int
+	var labels []string
+	var b strings.Builder
+	for i, l := range locs {
+		values = append(values, l.Count)
+		labels = append(labels, strconv.Itoa(i+1))
+		b.WriteString(fmt.Sprintf("%d — %s\n", i+1, l.Name))
+	}
+	data, err = renderBarChart(values, labels, chartOut)
+	return data, b.String(), err
+}
+
+func sendChart(bot Sender, chatID int64, data []byte, caption string) {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: data})
+	photo.Caption = caption
+	bot.Send(photo)
+}
+
+// Графики к вечерней сводке
+// Графики к ежедневной сводке за день now
+func sendDailyCharts(ctx context.Context, bot Sender, chatID int64, now time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	when := "сегодня"
+	if !day.Equal(daysAgo(0)) {
+		when = day.Format("02.01")
+	}
+	if data, err := presenceChartToday(ctx, now); err == nil {
+		sendChart(bot, chatID, data, "👥 В части по часам, "+when)
+	} else {
+		log.Printf("chart: %v", err)
+	}
+	data, legend, err := locationsChart(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("chart: %v", err)
+		return
+	}
+	if legend != "" {
+		sendChart(bot, chatID, data, "📍 Убытия по локациям, "+when+"\n"+legend)
+	}
+}
+
+// Графики к недельному дайджесту
+func sendWeeklyCharts(ctx context.Context, bot Sender, chatID int64, from, to time.Time) {
+	data, legend, err := locationsChart(ctx, from, to)
+	if err != nil {
+		log.Printf("chart: %v", err)
+		return
+	}
+	if legend != "" {
+		sendChart(bot, chatID, data, "📍 Убытия по локациям за неделю\n"+legend)
+	}
+}
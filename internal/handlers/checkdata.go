@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/storage"
+)
+
+// --- Проверка целостности данных /checkdata ---
+//
+// Проверяет users.csv, admins.csv и все файлы журнала: строки с неверным
+// числом колонок, нечисловые и повторяющиеся ID, нечитаемые даты, отметки
+// неизвестных пользователей. /checkdata fix исправляет то, что можно
+// исправить без потерь: даты в старом формате приводятся к dateFormat,
+// битые строки и повторы ID уходят в корзину (/trash). Отметки удалённых
+// пользователей не трогаются — их могли оставить намеренно при удалении.
+
+type dataIssue struct {
+	File string
+	Line int
+	What string
+}
+
+type dataCheck struct {
+	Issues  []dataIssue
+	Orphans map[string]int // ID -> число отметок без пользователя
+	Fixed   int
+}
+
+// Файл реестра: ID в первой колонке, не меньше minCols колонок
+func checkRegistryFile(ctx context.Context, c *dataCheck, file string, minCols int, fix bool, trashID string, adminID int) {
+	var removed [][]string
+	scanCheckedFile(ctx, file, fix, func(rows [][]string) [][]string {
+		seen := make(map[string]bool)
+		var keep [][]string
+		for i, row := range rows {
+			problem := ""
+			switch {
+			case len(row) < minCols:
+				problem = fmt.Sprintf("колонок %d, нужно не меньше %d", len(row), minCols)
+			case !isNumericID(row[0]):
+				problem = "ID не число: " + row[0]
+			case seen[row[0]]:
+				problem = "повтор ID " + row[0]
+			}
+			if problem == "" {
+				seen[row[0]] = true
+				keep = append(keep, row)
+				continue
+			}
+			c.Issues = append(c.Issues, dataIssue{file, i + 1, problem})
+			removed = append(removed, row)
+		}
+		return keep
+	})
+	if fix && len(removed) > 0 {
+		moveToTrash(ctx, trashID, adminID, file, removed...)
+		c.Fixed += len(removed)
+	}
+}
+
+// Без fix — только просмотр; с fix — просмотр и запись под одной
+// блокировкой файла, чтобы не затереть то, что дописали между ними
+func scanCheckedFile(ctx context.Context, file string, fix bool, scan func(rows [][]string) [][]string) {
+	if fix {
+		storage.Rewrite(ctx, file, scan)
+		return
+	}
+	scan(readCSVUnfiltered(ctx, file))
+}
+
+func isNumericID(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func checkJournalFile(ctx context.Context, c *dataCheck, file string, known map[string][]string, fix bool, trashID string, adminID int) {
+	var removed [][]string
+	scanCheckedFile(ctx, file, fix, func(rows [][]string) [][]string {
+		var keep [][]string
+		for i, row := range rows {
+			problem := ""
+			if len(row) < 5 {
+				problem = fmt.Sprintf("колонок %d, нужно не меньше 5", len(row))
+			} else if !isNumericID(row[1]) {
+				problem = "ID не число: " + row[1]
+			} else if t, normalized, ok := journal.ParseTime(row[0]); !ok {
+				problem = "нечитаемая дата: " + row[0]
+			} else if normalized {
+				c.Issues = append(c.Issues, dataIssue{file, i + 1, "дата в старом формате: " + row[0]})
+				if fix {
+					row[0] = t.Format(dateFormat)
+					c.Fixed++
+				}
+			}
+			if problem != "" {
+				c.Issues = append(c.Issues, dataIssue{file, i + 1, problem})
+				removed = append(removed, row)
+				continue
+			}
+			if _, ok := known[row[1]]; !ok && row[1] != "0" {
+				c.Orphans[row[1]]++
+			}
+			keep = append(keep, row)
+		}
+		return keep
+	})
+	if fix && len(removed) > 0 {
+		moveToTrash(ctx, trashID, adminID, file, removed...)
+		c.Fixed += len(removed)
+	}
+}
+
+func checkData(ctx context.Context, adminID int, fix bool) dataCheck {
+	c := dataCheck{Orphans: make(map[string]int)}
+	trashID := newTrashID()
+	checkRegistryFile(ctx, &c, usersFile, 3, fix, trashID, adminID)
+	checkRegistryFile(ctx, &c, adminsFile, 2, fix, trashID, adminID)
+	known := loadUserRegistry(ctx).ByID
+	for _, f := range append(journal.Archives(), dataFile) {
+		checkJournalFile(ctx, &c, f, known, fix, trashID, adminID)
+	}
+	if fix && c.Fixed > 0 {
+		refreshStatusBoard()
+	}
+	return c
+}
+
+func handleCheckDataCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fix := strings.TrimSpace(args) == "fix"
+	start := clock.Now()
+	c := checkData(ctx, adminID, fix)
+	var b strings.Builder
+	b.WriteString("🩺 Проверка данных\n\n")
+	if len(c.Issues) == 0 && len(c.Orphans) == 0 {
+		b.WriteString("✅ Ошибок не найдено.")
+	}
+	for i, is := range c.Issues {
+		if i == 40 {
+			b.WriteString(fmt.Sprintf("…и ещё %d\n", len(c.Issues)-i))
+			break
+		}
+		b.WriteString(fmt.Sprintf("— %s:%d: %s\n", is.File, is.Line, is.What))
+	}
+	if len(c.Orphans) > 0 {
+		total := 0
+		for _, n := range c.Orphans {
+			total += n
+		}
+		b.WriteString(fmt.Sprintf("\n👻 Отметки без пользователя: %d (ID: %d). Обычно это удалённые с сохранением истории — не исправляются.\n", total, len(c.Orphans)))
+	}
+	switch {
+	case fix && c.Fixed > 0:
+		b.WriteString(fmt.Sprintf("\n🔧 Исправлено строк: %d. Удалённое — в /trash.", c.Fixed))
+		writeAudit(ctx, adminID, "checkdata_fix", strconv.Itoa(c.Fixed))
+	case !fix && len(c.Issues) > 0:
+		b.WriteString("\nИсправить: /checkdata fix")
+	}
+	b.WriteString(fmt.Sprintf("\n\n⏱ %.1f с", time.Since(start).Seconds()))
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
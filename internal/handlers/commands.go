@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Меню команд «/» ---
+//
+// Обычные пользователи видят команды из области по умолчанию. Админам и
+// командирам подразделений в их личный чат ставится свой список — только
+// те команды, на которые у них есть права. Список обновляется при старте
+// и при каждом изменении прав.
+
+// Команды и права на них: по этой таблице строится меню «/» и ею же
+// router (router.go) разбирает команды. /start и незарегистрированные
+// обрабатываются в handleCommand до таблицы.
+func registerCommands(r *Router) {
+	for _, c := range []Command{
+		{Name: "setname", Description: "Изменить ФИО", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			name, ok := validate.Name(msg.CommandArguments())
+			if !ok {
+				bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✏️ Введите: /setname Фамилия И.О. (например: Иванов И.И.)"))
+				return
+			}
+			saveUserName(ctx, msg.From.ID, name, msg.Chat.ID)
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ ФИО обновлено!"))
+			sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+		}},
+		{Name: "stats", Description: "Моя статистика", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendUserStats(ctx, bot, msg.Chat.ID, msg.From.ID)
+		}},
+		{Name: "remind", Description: "Время напоминаний", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleRemindCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "autoarrive", Description: "Автоотметка по геозоне", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleAutoArriveCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "keyboard", Description: "Постоянные кнопки отметки", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleKeyboardCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "handover", Description: "Передать дежурство", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleHandoverCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "help", Description: "Список команд", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendHelp(ctx, bot, msg.Chat.ID, msg.From.ID)
+		}},
+		{Name: "unit", Description: "Моё подразделение", Right: rightUnitLeader, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendLeaderMenu(ctx, bot, msg.Chat.ID, msg.From.ID)
+		}},
+		{Name: "admin", Description: "Админ-панель", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendAdminPanel(bot, msg.Chat.ID)
+		}},
+		{Name: "weblogin", Description: "Вход в веб-админку", Right: rightAnyAdmin, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			if !isGroupChat(msg.Chat) {
+				sendWebLoginCode(ctx, bot, msg.Chat.ID, msg.From.ID)
+			}
+		}},
+		{Name: "summary", Description: "Сводка", Right: "summary", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			if args := msg.CommandArguments(); args != "" {
+				if unit := findUnit(ctx, args); unit != "" && adminSeesUnit(ctx, msg.From.ID, unit) {
+					bot.Send(tgbotapi.NewMessage(msg.Chat.ID, unitSummaryText(ctx, unit)))
+				} else {
+					bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Подразделение не найдено. Список: /units"))
+				}
+				return
+			}
+			adminSummary(ctx, bot, msg.Chat.ID)
+		}},
+		{Name: "report", Description: "Экспорт в Excel", Right: "export", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			reply := tgbotapi.NewMessage(msg.Chat.ID, "Выберите период для экспорта:")
+			reply.ReplyMarkup = reportFilterMenu()
+			bot.Send(reply)
+		}},
+		{Name: "tabel", Description: "Табель за месяц", Right: "export", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleTabelCommand(ctx, bot, msg.Chat.ID, msg.CommandArguments())
+		}},
+		{Name: "list", Description: "Список сотрудников", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			if strings.TrimSpace(msg.CommandArguments()) == "xlsx" {
+				sendPersonnelExcel(ctx, bot, msg.Chat.ID)
+				return
+			}
+			list := getUserList(ctx)
+			if list == "" {
+				list = "Нет данных о сотрудниках."
+			}
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "👥 Список сотрудников:\n"+list))
+		}},
+		{Name: "units", Description: "Подразделения", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleUnitsCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "invite", Description: "Приглашения", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleInviteCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "roster", Description: "Штатный список", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendRosterList(ctx, bot, msg.Chat.ID)
+		}},
+		{Name: "import", Description: "Загрузить список из xlsx", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "📋 Пришлите xlsx-файл со столбцами: ФИО, Telegram ID, телефон (ID и телефон — необязательно). Люди без ID будут найдены по ФИО при /start."))
+		}},
+		{Name: "adduser", Description: "Добавить человека", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleAddUserCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "archived", Description: "Архив пользователей", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendArchivedUsers(ctx, bot, msg.Chat.ID)
+		}},
+		{Name: "bans", Description: "Заблокированные", Right: "manage_users", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendBanList(ctx, bot, msg.Chat.ID)
+		}},
+		{Name: "ban", Right: "manage_users", Hidden: true, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleBanCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments(), true)
+		}},
+		{Name: "unban", Right: "manage_users", Hidden: true, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleBanCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments(), false)
+		}},
+		{Name: "trash", Description: "Корзина", Right: "edit_records", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendTrash(ctx, bot, msg.Chat.ID, msg.From.ID, 0)
+		}},
+		{Name: "anomalies", Description: "Подозрительные отметки", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleAnomaliesCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "duty", Description: "График дежурств", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleDutyCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "geo", Description: "Геозона", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleGeoCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "photos", Description: "Локации с фото", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handlePhotosCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "quiet", Description: "Тихие часы", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleQuietCommand(ctx, bot, msg.Chat.ID, msg.CommandArguments())
+		}},
+		{Name: "limits", Description: "Нормы отсутствия по локациям", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleLimitsCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "workday", Description: "Рабочие дни", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleWorkdayCommand(ctx, bot, msg.Chat.ID, msg.CommandArguments())
+		}},
+		{Name: "holidays", Description: "Праздники", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleHolidaysCommand(ctx, bot, msg.Chat.ID, msg.CommandArguments())
+		}},
+		{Name: "board", Description: "Табло в чате", Right: "settings", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleBoardCommand(ctx, bot, msg.Chat.ID, msg.CommandArguments())
+		}},
+		{Name: "clear", Description: "Опасная зона", Right: "danger_zone", Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendDangerZone(bot, msg.Chat.ID)
+		}},
+		{Name: "backup", Description: "Резервная копия", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendBackup(bot, msg.Chat.ID)
+		}},
+		{Name: "restore", Description: "Восстановить из копии", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			if args := strings.Fields(msg.CommandArguments()); len(args) > 0 && args[0] == "s3" {
+				handleS3RestoreCommand(ctx, bot, msg.Chat.ID, msg.From.ID, strings.Join(args[1:], ""))
+				return
+			}
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "📦 Пришлите ZIP-архив, созданный командой /backup, документом в этот чат.\nКопия из S3: /restore s3"))
+		}},
+		{Name: "scope", Description: "Области видимости админов", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleScopeCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "checkdata", Description: "Проверка данных", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleCheckDataCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "compact", Description: "Чистка журнала", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleCompactCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "seed", Description: "Демо-данные", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleSeedCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "jobs", Description: "Задачи по расписанию", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleJobsCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "token", Description: "API-токены", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleTokenCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+		{Name: "version", Description: "Версия сборки", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			sendVersion(ctx, bot, msg.Chat.ID)
+		}},
+		{Name: "transferroot", Description: "Передать роль главного админа", Right: rightRoot, Run: func(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+			handleTransferRootCommand(ctx, bot, msg.Chat.ID, msg.From.ID, msg.CommandArguments())
+		}},
+	} {
+		r.Command(c)
+	}
+}
+
+func commandsFor(ctx context.Context, userID int) []tgbotapi.BotCommand {
+	// «start» в таблице нет: его разбирает handleCommand
+	cmds := []tgbotapi.BotCommand{{Command: "start", Description: "Главное меню"}}
+	for _, c := range router.Commands() {
+		if !c.Hidden && (c.Right == "" || hasRight(ctx, userID, c.Right)) {
+			cmds = append(cmds, tgbotapi.BotCommand{Command: c.Name, Description: c.Description})
+		}
+	}
+	return cmds
+}
+
+func defaultCommands(ctx context.Context) []tgbotapi.BotCommand {
+	return commandsFor(ctx, 0)
+}
+
+// Личный список команд; если прав нет — сброс к списку по умолчанию
+func updateUserCommands(ctx context.Context, bot Sender, userID int) {
+	if userID == 0 {
+		return
+	}
+	scope := tgbotapi.NewBotCommandScopeChat(int64(userID))
+	cmds := commandsFor(ctx, userID)
+	var err error
+	if len(cmds) == len(defaultCommands(ctx)) {
+		_, err = bot.Request(tgbotapi.NewDeleteMyCommandsWithScope(scope))
+	} else {
+		_, err = bot.Request(tgbotapi.NewSetMyCommandsWithScope(scope, cmds...))
+	}
+	if err != nil {
+		log.Printf("commands: %d: %v", userID, err)
+	}
+}
+
+func setupBotCommands(ctx context.Context, bot Sender) {
+	if _, err := bot.Request(tgbotapi.NewSetMyCommandsWithScope(tgbotapi.NewBotCommandScopeDefault(), defaultCommands(ctx)...)); err != nil {
+		log.Printf("commands: %v", err)
+	}
+	updateUserCommands(ctx, bot, rootAdminID(ctx))
+	for _, a := range getAdmins(ctx) {
+		updateUserCommands(ctx, bot, a.ID)
+	}
+	for _, leaderID := range unitLeaders(ctx) {
+		updateUserCommands(ctx, bot, leaderID)
+	}
+}
+
+func sendHelp(ctx context.Context, bot Sender, chatID int64, userID int) {
+	var b strings.Builder
+	b.WriteString("ℹ️ Команды:\n")
+	for _, c := range commandsFor(ctx, userID) {
+		b.WriteString("/" + c.Command + " — " + c.Description + "\n")
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
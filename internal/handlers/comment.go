@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Комментарий к отметке ---
+//
+// После отметки можно добавить комментарий кнопкой «💬». Он хранится в
+// 10-й колонке записи, уходит админам отдельным уведомлением и попадает
+// в сводку и выгрузку.
+
+const (
+	colComment       = 9
+	commentMaxLength = 200
+)
+
+// Пользователь -> время отметки, к которой пишется комментарий
+var pendingComment = make(map[int]string)
+
+func markComment(row []string) string {
+	if len(row) > colComment {
+		return row[colComment]
+	}
+	return ""
+}
+
+func setMarkComment(ctx context.Context, uid int, dt, comment string) bool {
+	if !setRecordField(ctx, uid, dt, colComment, comment) {
+		return false
+	}
+	refreshStatusBoard()
+	return true
+}
+
+func commentButton(ts int64) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("💬 Комментарий", fmt.Sprintf("mcomm_%d", ts))
+}
+
+// mcomm_<unix>
+func handleCommentAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	ts, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "mcomm_"), 10, 64)
+	if err != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	pendingComment[query.From.ID] = time.Unix(ts, 0).Format(dateFormat)
+	bot.Send(tgbotapi.NewMessage(query.Message.Chat.ID, "💬 Напишите комментарий к отметке (до 200 символов):"))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handleCommentInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	comment := strings.TrimSpace(strings.ReplaceAll(msg.Text, "\n", " "))
+	if comment == "" {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Комментарий пустой, напишите текст."))
+		return
+	}
+	if r := []rune(comment); len(r) > commentMaxLength {
+		comment = string(r[:commentMaxLength])
+	}
+	dt := pendingComment[userID]
+	delete(pendingComment, userID)
+	if !setMarkComment(ctx, userID, dt, comment) {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Отметка не найдена."))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Комментарий сохранён."))
+	txt := fmt.Sprintf("💬 <b>Комментарий к отметке</b>\n👤 %s\n⏰ %s\n%s",
+		getUserName(ctx, userID, msg.From), dt, html.EscapeString(comment))
+	for _, chatID := range adminRecipients(ctx, "notifications") {
+		if adminSeesUser(ctx, chatID, strconv.Itoa(userID)) {
+			sendAdminNotification(ctx, bot, chatID, txt)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Чистка журнала /compact ---
+//
+// Что делает чистка — internal/journal/compact.go. /compact показывает,
+// что будет сделано, /compact run — выполняет, предварительно прислав
+// резервную копию.
+
+func handleCompactCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	if strings.TrimSpace(args) != "run" {
+		st := journal.Compact(ctx, clock.Now(), false)
+		text := "🧹 Чистка журнала — предварительный подсчёт\n\n" + st.String()
+		if st.Kept == st.Rows && st.Normalized == 0 && st.Moved == 0 {
+			text += "\n\nЖурнал в порядке, чистить нечего."
+		} else {
+			text += "\n\nВыполнить: /compact run (перед этим придёт резервная копия)"
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+	sendBackup(bot, chatID)
+	st := journal.Compact(ctx, clock.Now(), true)
+	refreshStatusBoard()
+	writeAudit(ctx, adminID, "compact", strings.ReplaceAll(st.String(), "\n", "; "))
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ Журнал очищен\n\n"+st.String()+
+		"\n\nЕсли что-то не так — восстановите присланную копию."))
+}
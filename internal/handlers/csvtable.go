@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"tabel-go/internal/storage"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Заголовки CSV-файлов ---
+//
+// Колонки каждого файла данных для заголовка и разбора по именам
+// (internal/storage/table.go). Новый файл с постоянным набором колонок
+// добавляется в csvTables.
+
+var attendanceTable = storage.Table{
+	Columns: []string{"time", "user_id", "name", "action", "location",
+		"source", "expected_return", "geo", "photo", "comment"},
+	Required: 5,
+}
+
+var csvTables = map[string]storage.Table{
+	dataFile:           attendanceTable,
+	statusFile:         attendanceTable,
+	usersFile:          {Columns: []string{"id", "name", "chat_id", "archived", "unit", "phone"}, Required: 3},
+	settingsFile:       {Columns: []string{"key", "value"}, Required: 2},
+	auditFile:          {Columns: []string{"time", "actor_id", "action", "details"}, Required: 3},
+	rightsHistoryFile:  {Columns: []string{"time", "actor_id", "target_id", "before", "after"}, Required: 5},
+	tokensFile:         {Columns: []string{"id", "sha256", "scope", "label", "issued_by", "issued", "revoked"}, Required: 7},
+	bansFile:           {Columns: []string{"user_id", "admin_id", "time", "reason"}, Required: 1},
+	holidaysFile:       {Columns: []string{"date", "name", "kind"}, Required: 1},
+	chatsFile:          {Columns: []string{"chat_id", "title"}, Required: 1},
+	dutyFile:           {Columns: []string{"start", "user_id"}, Required: 2},
+	handoverFile:       {Columns: []string{"time", "from_id", "to_id", "text"}, Required: 4},
+	invitesFile:        {Columns: []string{"code", "unit", "issued_by", "issued"}, Required: 2},
+	rosterFile:         {Columns: []string{"name", "phone", "added_by"}, Required: 1},
+	unitsFile:          {Columns: []string{"unit", "leader_id"}, Required: 1},
+	customJobsFile:     {Columns: []string{"name", "spec", "action", "chat_id", "created_by"}, Required: 4},
+	locationLimitsFile: {Columns: []string{"location", "minutes", "set_by"}, Required: 2},
+	trashFile:          {Columns: []string{"item_id", "file", "deleted", "deleted_by", "row"}, Required: 5},
+	quietQueueFile:     {Columns: []string{"queued", "chat_id", "text", "parse_mode", "markup"}, Required: 5},
+}
+
+// Таблица файла; архивы журнала устроены как рабочий файл
+func csvTableFor(filename string) (storage.Table, bool) {
+	if filename == adminsFile {
+		// ID, имя, затем флаг каждого права в порядке adminRights
+		cols := []string{"id", "name"}
+		for _, r := range adminRights {
+			cols = append(cols, r.Code)
+		}
+		return storage.Table{Columns: cols, Required: 2}, true
+	}
+	if journal.IsArchive(filename) {
+		return attendanceTable, true
+	}
+	t, ok := csvTables[filename]
+	return t, ok
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/scheduler"
+	"tabel-go/internal/validate"
+)
+
+// --- Свои задачи по расписанию ---
+//
+// Админ заводит задачу из админ-панели одной строкой: название, действие
+// и время («вечер сводка 21:00 пн-пт») или cron из пяти полей. Задачи
+// хранятся в custom_jobs.csv и после перезапуска ставятся в планировщик
+// заново; результат уходит в чат, из которого задачу создали.
+
+const customJobsFile = "custom_jobs.csv"
+
+func init() {
+	backupFiles = append(backupFiles, customJobsFile)
+}
+
+// Действия, доступные своим задачам
+var customJobActions = []struct {
+	Code, Title string
+}{
+	{"summary", "сводка"},
+	{"remind", "напоминание"},
+	{"export_day", "экспорт-день"},
+	{"export_week", "экспорт-неделя"},
+	{"export_month", "экспорт-месяц"},
+}
+
+type customJob struct {
+	Name      string
+	Spec      string
+	Action    string
+	ChatID    int64
+	CreatedBy int
+}
+
+var pendingCustomJob = make(map[int]string) // ID админа -> имя изменяемой задачи ("" — новая)
+
+var customJobNameRe = regexp.MustCompile(`^[a-zа-яё0-9_-]{1,24}$`)
+
+func loadCustomJobs(ctx context.Context) []customJob {
+	var list []customJob
+	for _, row := range readCSV(ctx, customJobsFile) {
+		chatID, err := strconv.ParseInt(row[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		j := customJob{Name: row[0], Spec: row[1], Action: row[2], ChatID: chatID}
+		if len(row) > 4 {
+			j.CreatedBy, _ = strconv.Atoi(row[4])
+		}
+		list = append(list, j)
+	}
+	return list
+}
+
+func customJobActionTitle(code string) string {
+	for _, a := range customJobActions {
+		if a.Code == code {
+			return a.Title
+		}
+	}
+	return code
+}
+
+func customJobAction(title string) (string, bool) {
+	title = strings.ToLower(title)
+	for _, a := range customJobActions {
+		if a.Title == title || a.Code == title {
+			return a.Code, true
+		}
+	}
+	return "", false
+}
+
+func (j customJob) run(ctx context.Context, bot Sender, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch j.Action {
+	case "summary":
+		adminSummary(ctx, bot, j.ChatID)
+	case "remind":
+		if isDutyDay(ctx, now) {
+			sendReminders(ctx, bot)
+		}
+	case "export_day":
+		sendFilteredExcel(ctx, bot, j.ChatID, today, filterToday)
+	case "export_week":
+		from := today.AddDate(0, 0, -6)
+		sendFilteredExcel(ctx, bot, j.ChatID, from, filterRange(from, today.AddDate(0, 0, 1)))
+	case "export_month":
+		from := today.AddDate(0, -1, 1)
+		sendFilteredExcel(ctx, bot, j.ChatID, from, filterRange(from, today.AddDate(0, 0, 1)))
+	default:
+		return fmt.Errorf("неизвестное действие %q", j.Action)
+	}
+	return nil
+}
+
+func (j customJob) schedulerJob(ctx context.Context, bot Sender) scheduler.Job {
+	return scheduler.Job{
+		Name:    j.Name,
+		Spec:    j.Spec,
+		Enabled: jobEnabled(ctx, j.Name),
+		CatchUp: 2 * time.Hour,
+		Run: func(ctx context.Context, now time.Time) error {
+			return j.run(ctx, bot, now)
+		},
+	}
+}
+
+func isBuiltinJob(name string) bool {
+	for _, n := range builtinJobs {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Создаёт или заменяет задачу и сразу переставляет её в планировщике
+func saveCustomJob(ctx context.Context, j customJob) {
+	updateCSV(ctx, customJobsFile, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if row[0] != j.Name {
+				keep = append(keep, row)
+			}
+		}
+		return append(keep, []string{j.Name, j.Spec, j.Action,
+			strconv.FormatInt(j.ChatID, 10), strconv.Itoa(j.CreatedBy)})
+	})
+	if jobs != nil {
+		jobs.Remove(j.Name)
+		jobs.Add(j.schedulerJob(ctx, jobsBot))
+	}
+}
+
+func deleteCustomJob(ctx context.Context, name string) bool {
+	found := false
+	updateCSV(ctx, customJobsFile, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if row[0] == name {
+				found = true
+				continue
+			}
+			keep = append(keep, row)
+		}
+		return keep
+	})
+	if found {
+		setSetting(ctx, "job_"+name, "")
+		setSetting(ctx, "jobrun_"+name, "")
+		if jobs != nil {
+			jobs.Remove(name)
+		}
+	}
+	return found
+}
+
+// --- Раздел админ-панели ---
+
+func sendCustomJobsPanel(ctx context.Context, bot Sender, chatID int64) {
+	list := loadCustomJobs(ctx)
+	var b strings.Builder
+	b.WriteString("⏰ Свои задачи по расписанию\n")
+	if len(list) == 0 {
+		b.WriteString("\nПока нет ни одной.")
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, j := range list {
+		mark, toggle := "🟢", "⏸"
+		if !jobEnabled(ctx, j.Name)() {
+			mark, toggle = "⚪️", "▶️"
+		}
+		fmt.Fprintf(&b, "\n%s %s — %s, %s", mark, j.Name, customJobActionTitle(j.Action), j.Spec)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ "+j.Name, "cjob_edit_"+j.Name),
+			tgbotapi.NewInlineKeyboardButtonData(toggle, "cjob_tog_"+j.Name),
+			tgbotapi.NewInlineKeyboardButtonData("🗑", "cjob_del_"+j.Name),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Добавить", "cjob_add"),
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "admin_panel"),
+	))
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+func customJobPrompt() string {
+	var titles []string
+	for _, a := range customJobActions {
+		titles = append(titles, a.Title)
+	}
+	return "✍️ Отправьте задачу одной строкой: название действие время\n\n" +
+		"Например:\nвечер сводка 21:00\nпятница экспорт-неделя 17:00 пт\nутро напоминание 0 8 * * 1-5\n\n" +
+		"Действия: " + strings.Join(titles, ", ") + "\n" +
+		"Дни: пн-пт, сб,вс, ежедневно — или cron из пяти полей.\n" +
+		"Для отмены напишите «отмена»."
+}
+
+func handleCustomJobAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	adminID := query.From.ID
+	chatID := query.Message.Chat.ID
+	data := query.Data
+	switch {
+	case data == "cjobs":
+		sendCustomJobsPanel(ctx, bot, chatID)
+	case data == "cjob_add":
+		pendingCustomJob[adminID] = ""
+		bot.Send(tgbotapi.NewMessage(chatID, customJobPrompt()))
+	case strings.HasPrefix(data, "cjob_edit_"):
+		name := strings.TrimPrefix(data, "cjob_edit_")
+		pendingCustomJob[adminID] = name
+		bot.Send(tgbotapi.NewMessage(chatID, "Изменение задачи «"+name+"». Название можно не менять.\n\n"+customJobPrompt()))
+	case strings.HasPrefix(data, "cjob_tog_"):
+		name := strings.TrimPrefix(data, "cjob_tog_")
+		state := "off"
+		if !jobEnabled(ctx, name)() {
+			state = "on"
+		}
+		setSetting(ctx, "job_"+name, state)
+		writeAudit(ctx, adminID, "job_"+state, name)
+		sendCustomJobsPanel(ctx, bot, chatID)
+	case strings.HasPrefix(data, "cjob_del_"):
+		name := strings.TrimPrefix(data, "cjob_del_")
+		if deleteCustomJob(ctx, name) {
+			writeAudit(ctx, adminID, "job_delete", name)
+		}
+		sendCustomJobsPanel(ctx, bot, chatID)
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handleCustomJobInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	editing := pendingCustomJob[adminID]
+	text := strings.TrimSpace(msg.Text)
+	if strings.EqualFold(text, "отмена") {
+		delete(pendingCustomJob, adminID)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Отменено."))
+		return
+	}
+	fields := strings.Fields(text)
+	if len(fields) < 3 {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Нужно: название действие время. Например: вечер сводка 21:00"))
+		return
+	}
+	name := strings.ToLower(fields[0])
+	if !customJobNameRe.MatchString(name) {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Название — одно слово до 24 символов"))
+		return
+	}
+	if isBuiltinJob(name) {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Это имя занято встроенной задачей"))
+		return
+	}
+	action, ok := customJobAction(fields[1])
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Неизвестное действие: "+fields[1]))
+		return
+	}
+	spec, err := validate.Schedule(fields[2:])
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ "+err.Error()))
+		return
+	}
+	if name != editing {
+		for _, j := range loadCustomJobs(ctx) {
+			if j.Name == name {
+				bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Задача «"+name+"» уже есть — измените её кнопкой ✏️"))
+				return
+			}
+		}
+	}
+	delete(pendingCustomJob, adminID)
+	if editing != "" && editing != name {
+		deleteCustomJob(ctx, editing)
+	}
+	saveCustomJob(ctx, customJob{Name: name, Spec: spec, Action: action, ChatID: msg.Chat.ID, CreatedBy: adminID})
+	if editing != "" {
+		writeAudit(ctx, adminID, "job_edit", name+" "+spec+" "+action)
+	} else {
+		writeAudit(ctx, adminID, "job_add", name+" "+spec+" "+action)
+	}
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("✅ Задача «%s»: %s по расписанию %s", name, customJobActionTitle(action), spec)))
+	sendCustomJobsPanel(ctx, bot, msg.Chat.ID)
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Опасная зона ---
+//
+// Необратимые операции админ-панели. Доступ — право danger_zone.
+// Подтверждение в два шага: сначала предупреждение, затем кнопка,
+// действующая dangerConfirmWindow, или ввод контрольной фразы. Каждое
+// действие пишется в аудит, главный админ получает уведомление.
+// Перед операцией данные сохраняются в cleared_<unix>.zip, и сутки
+// её можно откатить кнопкой «↩️ Восстановить».
+
+const (
+	dangerConfirmWindow = 30 * time.Second
+	dangerUndoWindow    = 24 * time.Hour
+)
+
+type dangerOp struct {
+	Code    string
+	Title   string
+	Warning string
+	Phrase  string
+}
+
+var dangerOps = []dangerOp{
+	{"journal", "🗑 Очистить журнал", "Будут удалены все отметки текущего месяца.", "ОЧИСТИТЬ ЖУРНАЛ"},
+	{"users", "👥 Очистить пользователей", "Будут удалены все зарегистрированные пользователи. Им придётся заново пройти /start.", "ОЧИСТИТЬ ПОЛЬЗОВАТЕЛЕЙ"},
+	{"reset", "💣 Полный сброс", "Будут удалены журнал, архивы, пользователи, подразделения, штатный список, графики дежурств, приглашения, блокировки, API-токены, корзина, свои задачи, праздники, чаты, нормы отсутствия по локациям и очередь тихих часов. Останутся только админы, настройки, история прав и аудит.", "ПОЛНЫЙ СБРОС"},
+}
+
+// Кто сейчас вводит контрольную фразу: ID -> код операции
+var pendingDangerPhrase = make(map[int]string)
+
+func findDangerOp(code string) (dangerOp, bool) {
+	for _, op := range dangerOps {
+		if op.Code == code {
+			return op, true
+		}
+	}
+	return dangerOp{}, false
+}
+
+func sendDangerZone(bot Sender, chatID int64) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, op := range dangerOps {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(op.Title, "danger_"+op.Code),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "admin_panel"),
+	))
+	msg := tgbotapi.NewMessage(chatID, "⚠️ Опасная зона\n\nПеред каждым действием сохраняется копия данных, откатить его можно в течение суток.")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+// Удаляет файлы под блокировками хранилища; кэши сбрасываются через
+// OnWrite, как при любой записи. journal.Lock держится на всё время:
+// отметка, пришедшая посередине, не пересоздаст журнал наполовину
+// стёртым, а marks.wal не вернёт стёртые отметки при следующем запуске.
+// Журнал и архивы должны идти в names первыми, как в journal.Compact.
+func wipeDataFiles(ctx context.Context, names []string) error {
+	unlock, err := journal.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	err = updateCSVs(ctx, names, func(map[string][][]string) map[string][][]string {
+		gone := make(map[string][][]string, len(names))
+		for _, name := range names {
+			gone[name] = nil
+		}
+		return gone
+	})
+	if err == nil && names[0] == dataFile {
+		journal.DropWAL()
+	}
+	return err
+}
+
+func clearJournal(ctx context.Context, adminID int) {
+	if err := wipeDataFiles(ctx, []string{dataFile}); err != nil {
+		log.Printf("danger: очистка журнала: %v", err)
+	}
+	writeAudit(ctx, adminID, "clear_journal", "")
+	refreshStatusBoard()
+}
+
+// Файлы, которые не трогает полный сброс
+var resetKeeps = map[string]bool{adminsFile: true, settingsFile: true, auditFile: true, rightsHistoryFile: true}
+
+func runDangerOp(ctx context.Context, adminID int, op dangerOp) {
+	switch op.Code {
+	case "journal":
+		clearJournal(ctx, adminID)
+		return
+	case "users":
+		if err := wipeDataFiles(ctx, []string{usersFile}); err != nil {
+			log.Printf("danger: очистка пользователей: %v", err)
+		}
+	case "reset":
+		names := append([]string{dataFile}, journal.Archives()...)
+		for _, name := range backupFiles {
+			if name != dataFile && !resetKeeps[name] {
+				names = append(names, name)
+			}
+		}
+		if err := wipeDataFiles(ctx, names); err != nil {
+			log.Printf("danger: полный сброс: %v", err)
+		}
+		refreshStatusBoard()
+	}
+	writeAudit(ctx, adminID, "danger_"+op.Code, "")
+}
+
+func dangerSnapshotName(stamp int64) string {
+	return fmt.Sprintf("cleared_%d.zip", stamp)
+}
+
+// Снимок данных перед операцией; старые снимки удаляются
+func saveDangerSnapshot(now time.Time) (int64, error) {
+	files, _ := filepath.Glob("cleared_*.zip")
+	for _, f := range files {
+		stamp, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(f, "cleared_"), ".zip"), 10, 64)
+		if err == nil && now.Sub(time.Unix(stamp, 0)) > dangerUndoWindow {
+			os.Remove(f)
+		}
+	}
+	data, err := buildBackupArchive()
+	if err != nil {
+		return 0, err
+	}
+	stamp := now.Unix()
+	return stamp, os.WriteFile(dangerSnapshotName(stamp), data, 0644)
+}
+
+// Откат операции; отметки, сделанные после неё, дописываются в журнал.
+// Дописываются только отметки не раньше снимка, которых нет в
+// восстановленном журнале: откат «users» журнал не трогал, и всё
+// остальное в нём уже есть.
+func restoreDangerSnapshot(ctx context.Context, adminID int, stamp int64) error {
+	data, err := os.ReadFile(dangerSnapshotName(stamp))
+	if err != nil {
+		return err
+	}
+	current := readCSV(ctx, dataFile)
+	if err := restoreBackup(ctx, data); err != nil {
+		return err
+	}
+	snapshot := time.Unix(stamp, 0)
+	var later [][]string
+	for _, row := range current {
+		if len(row) < 5 {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil || t.Before(snapshot) || journal.MarkExists(ctx, row) {
+			continue
+		}
+		later = append(later, row)
+	}
+	if len(later) > 0 {
+		updateCSV(ctx, dataFile, func(rows [][]string) [][]string {
+			return append(rows, later...)
+		})
+	}
+	os.Remove(dangerSnapshotName(stamp))
+	writeAudit(ctx, adminID, "danger_undo", strconv.FormatInt(stamp, 10))
+	refreshStatusBoard()
+	return nil
+}
+
+func finishDangerOp(ctx context.Context, bot Sender, chatID int64, adminID int, op dangerOp) {
+	delete(pendingDangerPhrase, adminID)
+	stamp, err := saveDangerSnapshot(clock.Now())
+	if err != nil {
+		log.Printf("danger: снимок не создан: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Не удалось сохранить копию данных, операция отменена."))
+		return
+	}
+	runDangerOp(ctx, adminID, op)
+	msg := tgbotapi.NewMessage(chatID, "✅ Выполнено: "+op.Title+"\n\nКопия данных сохранена, откатить можно в течение суток.")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Восстановить", fmt.Sprintf("danger_undo_%d", stamp)),
+	))
+	bot.Send(msg)
+	if adminID != rootAdminID(ctx) {
+		txt := fmt.Sprintf("⚠️ <b>Опасная зона</b>\n%s\n👤 %s (%d)\n⏰ %s",
+			op.Title, capitalizeName(getUserName(ctx, adminID, nil)), adminID, clock.Now().Format(dateFormat))
+		msg := tgbotapi.NewMessage(int64(rootAdminID(ctx)), txt)
+		msg.ParseMode = "HTML"
+		bot.Send(msg)
+	}
+}
+
+// danger_<оп> — предупреждение, danger_go_<оп> — второй шаг,
+// danger_ok_<оп>_<срок> — выполнение, если срок кнопки не истёк,
+// danger_undo_<снимок> — откат
+func handleDangerAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	userID := query.From.ID
+	parts := strings.Split(query.Data, "_")
+	switch {
+	case query.Data == "danger_cancel":
+		delete(pendingDangerPhrase, userID)
+		bot.Send(tgbotapi.NewMessage(chatID, "Отменено."))
+	case len(parts) == 2:
+		op, ok := findDangerOp(parts[1])
+		if !ok {
+			break
+		}
+		msg := tgbotapi.NewMessage(chatID, op.Title+"\n\n"+op.Warning+"\n\nПродолжить?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Продолжить", "danger_go_"+op.Code),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "danger_cancel"),
+		))
+		bot.Send(msg)
+	case len(parts) == 3 && parts[1] == "undo":
+		stamp, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			break
+		}
+		if clock.Now().Sub(time.Unix(stamp, 0)) > dangerUndoWindow {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "⌛ Прошло больше суток, откат недоступен"))
+			return
+		}
+		if err := restoreDangerSnapshot(ctx, userID, stamp); err != nil {
+			log.Printf("danger: откат %d: %v", stamp, err)
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Копия не найдена или уже восстановлена"))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, "↩️ Данные восстановлены. Отметки, сделанные после очистки, сохранены."))
+	case len(parts) == 3 && parts[1] == "go":
+		op, ok := findDangerOp(parts[2])
+		if !ok {
+			break
+		}
+		pendingDangerPhrase[userID] = op.Code
+		deadline := clock.Now().Add(dangerConfirmWindow).Unix()
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"❗ Последнее подтверждение.\n\nНажмите кнопку в течение %d секунд или отправьте фразу:\n%s",
+			int(dangerConfirmWindow.Seconds()), op.Phrase))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💥 Подтверждаю", fmt.Sprintf("danger_ok_%s_%d", op.Code, deadline)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "danger_cancel"),
+		))
+		bot.Send(msg)
+	case len(parts) == 4 && parts[1] == "ok":
+		op, ok := findDangerOp(parts[2])
+		deadline, err := strconv.ParseInt(parts[3], 10, 64)
+		if !ok || err != nil {
+			break
+		}
+		if clock.Now().Unix() > deadline {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "⌛ Время вышло, начните заново"))
+			return
+		}
+		finishDangerOp(ctx, bot, chatID, userID, op)
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+// Ввод контрольной фразы; любой другой текст отменяет операцию
+func handleDangerPhraseInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	op, _ := findDangerOp(pendingDangerPhrase[userID])
+	delete(pendingDangerPhrase, userID)
+	if !strings.EqualFold(strings.TrimSpace(msg.Text), op.Phrase) {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Фраза не совпала, операция отменена."))
+		return
+	}
+	finishDangerOp(ctx, bot, msg.Chat.ID, userID, op)
+}
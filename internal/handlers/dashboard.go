@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Веб-панель только для чтения ---
+//
+// Главная страница keep-alive сервера. С доступом на чтение (API-токен в
+// ?token=… или вход по логину, см. httpauth.go) показывает сводку, отметки
+// за сегодня и просрочивших возвращение. Без него — прежний короткий ответ
+// для пингов Render и мониторинга. /dashboard всегда требует вход. Новые отметки приходят
+// через /api/stream, и страница сразу обновляется.
+
+type dashboardMark struct {
+	Time, Name, Action, Location string
+}
+
+type dashboardOverdue struct {
+	Name, Location, Deadline, Late string
+}
+
+type dashboardData struct {
+	Token   string
+	Updated string
+	Version string
+	Summary string
+	Marks   []dashboardMark
+	Overdue []dashboardOverdue
+}
+
+func init() {
+	handleHTTP("/dashboard", requireScope("read", renderDashboard))
+}
+
+func serveDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !authorized(r, "read") {
+		fmt.Fprintf(w, "I'm alive! Tabel-Go-Bot for Render.com, version %s", versionString())
+		return
+	}
+	renderDashboard(w, r)
+}
+
+func renderDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	data := collectDashboard(ctx, clock.Now())
+	data.Token = r.URL.Query().Get("token")
+	dashboardTemplate.Execute(w, data)
+}
+
+func collectDashboard(ctx context.Context, now time.Time) dashboardData {
+	data := dashboardData{
+		Updated: now.Format("02.01.2006 15:04"),
+		Version: versionString(),
+		Summary: presenceText(ctx) + todayLateSection(ctx),
+	}
+	today := daysAgo(0)
+	for _, row := range journal.ReadRange(ctx, today, daysAgo(-1)) {
+		if len(row) < 5 {
+			continue
+		}
+		t, _ := time.ParseInLocation(dateFormat, row[0], time.Local)
+		data.Marks = append(data.Marks, dashboardMark{t.Format("15:04"), capitalizeName(row[2]), row[3], cleanLocation(row[4])})
+	}
+	// Свежие сверху
+	for i, j := 0, len(data.Marks)-1; i < j; i, j = i+1, j-1 {
+		data.Marks[i], data.Marks[j] = data.Marks[j], data.Marks[i]
+	}
+	for _, u := range getSortedUsers(ctx) {
+		row := findLastRow(ctx, strconv.Itoa(u.ID))
+		if row == nil || row[3] != "Убыл" {
+			continue
+		}
+		deadline, ok := returnDeadline(ctx, row)
+		if !ok || now.Before(deadline) {
+			continue
+		}
+		data.Overdue = append(data.Overdue, dashboardOverdue{
+			capitalizeName(u.Name), cleanLocation(row[4]), formatExpectedReturn(deadline), formatDuration(now.Sub(deadline)),
+		})
+	}
+	return data
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{"path": httpPath}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Табель</title>
+<style>
+body { font-family: sans-serif; margin: 0 auto; padding: 16px; max-width: 1100px; }
+.cols { display: flex; flex-wrap: wrap; gap: 24px; }
+.cols > div { flex: 1; min-width: 320px; }
+pre { white-space: pre-wrap; font-family: inherit; background: #f5f5f5; padding: 12px; border-radius: 8px; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; }
+.late { color: #c62828; }
+.muted { color: #888; font-size: 13px; }
+</style>
+</head>
+<body>
+<h2>📊 Табель</h2>
+<p class="muted">Обновлено {{.Updated}} · <span id="live">подключение…</span> · {{.Version}}</p>
+{{if .Overdue}}
+<h3 class="late">⏰ Просрочили возвращение ({{len .Overdue}})</h3>
+<table>
+<tr><th>ФИО</th><th>Где</th><th>Должен был вернуться</th><th>Опоздание</th></tr>
+{{range .Overdue}}<tr><td>{{.Name}}</td><td>{{.Location}}</td><td>{{.Deadline}}</td><td class="late">{{.Late}}</td></tr>
+{{end}}</table>
+{{end}}
+<div class="cols">
+<div>
+<h3>👥 Сводка</h3>
+<pre>{{.Summary}}</pre>
+</div>
+<div>
+<h3>🕒 Отметки за сегодня ({{len .Marks}})</h3>
+{{if .Marks}}<table>
+<tr><th>Время</th><th>ФИО</th><th>Действие</th><th>Локация</th></tr>
+{{range .Marks}}<tr><td>{{.Time}}</td><td>{{.Name}}</td><td>{{.Action}}</td><td>{{.Location}}</td></tr>
+{{end}}</table>{{else}}<p>Отметок пока нет.</p>{{end}}
+</div>
+</div>
+<script>
+// Новая отметка — перерисовать страницу; без ленты — раз в минуту
+let reloadTimer = setTimeout(() => location.reload(), 60000);
+const live = document.getElementById("live");
+const stream = new EventSource({{path "/api/stream"}} + ({{.Token}} ? "?token=" + encodeURIComponent({{.Token}}) : ""));
+stream.onopen = () => { live.textContent = "🟢 онлайн"; };
+stream.onerror = () => { live.textContent = "🔴 нет связи, переподключение…"; };
+stream.addEventListener("mark", () => {
+  clearTimeout(reloadTimer);
+  reloadTimer = setTimeout(() => location.reload(), 1000);
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"tabel-go/internal/config"
+	"tabel-go/internal/journal"
+	"tabel-go/internal/storage"
+)
+
+// --- Шифрование файлов данных ---
+//
+// Если задан DATA_ENCRYPTION_KEY, все CSV-файлы и marks.wal пишутся
+// зашифрованными AES-256-GCM (ключ — SHA-256 пароля); шифрует и
+// расшифровывает слой хранения (internal/storage/crypt.go), остальной код
+// видит обычные строки. Файлы без заголовка читаются как есть и шифруются
+// при следующей записи, так что включить шифрование можно на работающем
+// боте. Резервные копии содержат файлы в том виде, в каком они лежат на
+// диске, — для восстановления нужен тот же ключ.
+
+func dataKey() ([]byte, bool) {
+	pass := config.Secret("DATA_ENCRYPTION_KEY")
+	if pass == "" {
+		return nil, false
+	}
+	key := sha256.Sum256([]byte(pass))
+	return key[:], true
+}
+
+// При запуске: каждый зашифрованный файл должен открываться текущим
+// ключом. Иначе первая же запись затёрла бы данные, которые не удалось
+// прочитать.
+func checkDataEncryption() error {
+	files, _ := filepath.Glob("*.csv")
+	files = append(files, journal.WALFile)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		if _, err := storage.Open(data); err != nil {
+			return fmt.Errorf("%s: %w (DATA_ENCRYPTION_KEY)", f, err)
+		}
+	}
+	return nil
+}
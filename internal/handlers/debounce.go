@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Защита от двойных отметок ---
+//
+// Повторное нажатие на плохой связи приходит вторым апдейтом через секунду-две.
+// Если у пользователя уже есть такая же отметка (действие и локация) за
+// последние journal.DebounceWindow, новая молча отбрасывается.
+
+func isDuplicateMark(ctx context.Context, userID int, action, location string, now time.Time) bool {
+	row := findLastRow(ctx, strconv.Itoa(userID))
+	if row == nil || row[3] != action || row[4] != location {
+		return false
+	}
+	t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+	return err == nil && now.Sub(t) < journal.DebounceWindow
+}
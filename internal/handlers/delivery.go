@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Недоставленные сообщения ---
+//
+// Напоминания и сводки отправляются с повторами. Если сообщение так и не
+// ушло, сбой запоминается, и раз в полчаса (задача delivery, jobs.go)
+// главный админ получает одну сводку: кому и что не доставлено и почему.
+
+const deliveryAttempts = 3
+
+type deliveryFailure struct {
+	ChatID int64
+	What   string
+	Err    string
+	At     time.Time
+}
+
+var (
+	deliveryMu       sync.Mutex
+	deliveryFailures []deliveryFailure
+)
+
+// Ошибки, при которых повтор бесполезен: бот заблокирован, чата нет
+func permanentSendError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == 400 || apiErr.Code == 403)
+}
+
+// Отправка с повторами; what — что отправлялось, для отчёта о сбоях
+func deliver(bot Sender, c tgbotapi.Chattable, what string) error {
+	// У демо-пользователей (/seed) чатов нет
+	if isDemoID(strconv.FormatInt(chattableChatID(c), 10)) {
+		return nil
+	}
+	var err error
+	for attempt := 1; attempt <= deliveryAttempts; attempt++ {
+		if _, err = bot.Send(c); err == nil {
+			return nil
+		}
+		if permanentSendError(err) {
+			break
+		}
+		wait := time.Duration(attempt) * 2 * time.Second
+		var apiErr *tgbotapi.Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = time.Duration(apiErr.RetryAfter) * time.Second
+		}
+		clock.Sleep(wait)
+	}
+	chatID := chattableChatID(c)
+	log.Printf("delivery: %s для %d не доставлено: %v", what, chatID, err)
+	captureError(err, map[string]string{"delivery": what, "chat_id": fmt.Sprint(chatID)})
+	deliveryMu.Lock()
+	deliveryFailures = append(deliveryFailures, deliveryFailure{chatID, what, err.Error(), clock.Now()})
+	deliveryMu.Unlock()
+	return err
+}
+
+func chattableChatID(c tgbotapi.Chattable) int64 {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		return m.ChatID
+	case tgbotapi.DocumentConfig:
+		return m.ChatID
+	case tgbotapi.PhotoConfig:
+		return m.ChatID
+	}
+	return 0
+}
+
+// Сводка сбоев, накопленных с прошлого запуска; пустая не отправляется
+func reportDeliveryFailures(ctx context.Context, bot Sender) error {
+	deliveryMu.Lock()
+	failures := deliveryFailures
+	deliveryFailures = nil
+	deliveryMu.Unlock()
+	if len(failures) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📭 Не доставлено сообщений: %d\n\n", len(failures)))
+	for i, f := range failures {
+		if i == 30 {
+			b.WriteString(fmt.Sprintf("…и ещё %d\n", len(failures)-i))
+			break
+		}
+		who := fmt.Sprintf("%d", f.ChatID)
+		if isUserRegistered(ctx, int(f.ChatID)) {
+			who = capitalizeName(getUserName(ctx, int(f.ChatID), nil)) + " (" + who + ")"
+		}
+		b.WriteString(fmt.Sprintf("%s %s — %s: %s\n", f.At.Format("15:04"), f.What, who, f.Err))
+	}
+	if _, err := bot.Send(tgbotapi.NewMessage(int64(rootAdminID(ctx)), b.String())); err != nil {
+		return fmt.Errorf("отчёт главному админу не отправлен: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Демонстрационные данные /seed ---
+//
+// /seed [человек] [недель] — вымышленный личный состав (по умолчанию 20
+// человек в трёх подразделениях) и отметки за несколько недель (по
+// умолчанию 3): уходы в рабочее время в разные места, возвращения,
+// изредка — опоздания и невозвращения. Чтобы не смешать выдумку с
+// настоящими данными, команда работает только в песочнице (--sandbox)
+// или пока в боте нет ни одного пользователя. У демо-пользователей ID
+// от demoIDBase, у отметок источник "demo"; /seed clear удаляет всё это.
+
+const (
+	demoIDBase    = 900000000
+	demoSource    = "demo"
+	demoMaxUsers  = 200
+	demoMaxWeeks  = 12
+	demoUsersDef  = 20
+	demoWeeksDef  = 3
+	demoLeaveProb = 0.4
+)
+
+var (
+	demoSurnames = []string{"Иванов", "Смирнов", "Кузнецов", "Попов", "Васильев", "Петров", "Соколов",
+		"Михайлов", "Новиков", "Фёдоров", "Морозов", "Волков", "Алексеев", "Лебедев", "Семёнов",
+		"Егоров", "Павлов", "Козлов", "Степанов", "Николаев", "Орлов", "Андреев", "Макаров", "Никитин"}
+	demoInitials = []string{"А", "В", "Д", "Е", "И", "К", "М", "Н", "О", "П", "Р", "С"}
+	demoUnits    = []string{"1 взвод", "2 взвод", "3 взвод"}
+)
+
+func isDemoID(id string) bool {
+	n, err := strconv.Atoi(id)
+	return err == nil && n >= demoIDBase && n < demoIDBase+demoMaxUsers
+}
+
+// Можно ли наполнять бота выдуманными данными
+func demoSeedAllowed(ctx context.Context) bool {
+	if sandboxMode {
+		return true
+	}
+	for _, u := range getAllUsers(ctx) {
+		if !isDemoID(strconv.Itoa(u.ID)) {
+			return false
+		}
+	}
+	return true
+}
+
+type demoUser struct {
+	ID   string
+	Name string
+}
+
+// Отметки одного человека за день: уход в рабочее время и возвращение
+func demoDayMarks(rnd *rand.Rand, day time.Time, u demoUser, now time.Time) [][]string {
+	var rows [][]string
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		if rnd.Float64() > demoLeaveProb/2 {
+			return nil
+		}
+	} else if rnd.Float64() > demoLeaveProb {
+		return nil
+	}
+	out := day.Add(time.Duration(8*60+rnd.Intn(9*60)) * time.Minute)
+	back := out.Add(time.Duration(30+rnd.Intn(4*60)) * time.Minute)
+	if out.After(now) {
+		return nil
+	}
+	location := leaveLocations[rnd.Intn(len(leaveLocations))]
+	expected := out.Add(3 * time.Hour).Format(dateFormat)
+	rows = append(rows, []string{out.Format(dateFormat), u.ID, u.Name, "Убыл", location, demoSource, expected})
+	// Изредка человек так и не отмечает возвращение
+	if rnd.Float64() < 0.05 || back.After(now) {
+		return rows
+	}
+	return append(rows, []string{back.Format(dateFormat), u.ID, u.Name, "Прибыл", "-", demoSource})
+}
+
+func seedDemoData(ctx context.Context, users, weeks int, now time.Time) (int, int) {
+	rnd := rand.New(rand.NewSource(now.UnixNano()))
+	var people []demoUser
+	var userRows [][]string
+	used := make(map[string]bool)
+	for i := 0; i < users; i++ {
+		var name string
+		for {
+			name = fmt.Sprintf("%s %s.%s.", demoSurnames[rnd.Intn(len(demoSurnames))],
+				demoInitials[rnd.Intn(len(demoInitials))], demoInitials[rnd.Intn(len(demoInitials))])
+			if !used[name] || len(used) >= len(demoSurnames)*len(demoInitials)*len(demoInitials) {
+				break
+			}
+		}
+		used[name] = true
+		id := strconv.Itoa(demoIDBase + i)
+		people = append(people, demoUser{id, name})
+		userRows = append(userRows, []string{id, name, id, "", demoUnits[i%len(demoUnits)],
+			fmt.Sprintf("+7900%07d", rnd.Intn(10000000))})
+	}
+	updateCSV(ctx, usersFile, func(rows [][]string) [][]string {
+		return append(removeDemoRows(rows, 0), userRows...)
+	})
+	updateCSV(ctx, unitsFile, func(rows [][]string) [][]string {
+		have := make(map[string]bool)
+		for _, row := range rows {
+			if len(row) > 0 {
+				have[row[0]] = true
+			}
+		}
+		for _, u := range demoUnits {
+			if !have[u] {
+				rows = append(rows, []string{u})
+			}
+		}
+		return rows
+	})
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	byFile := make(map[string][][]string)
+	marks := 0
+	for day := today.AddDate(0, 0, -7*weeks+1); !day.After(today); day = day.AddDate(0, 0, 1) {
+		for _, u := range people {
+			for _, row := range demoDayMarks(rnd, day, u, now) {
+				dest := dataFile
+				if day.Before(monthStart) {
+					dest = journal.ArchiveFile(day)
+				}
+				byFile[dest] = append(byFile[dest], row)
+				marks++
+			}
+		}
+	}
+	for f, gen := range byFile {
+		updateCSV(ctx, f, func(rows [][]string) [][]string {
+			rows = append(removeDemoRows(rows, 1), gen...)
+			sort.SliceStable(rows, func(i, j int) bool {
+				ti, _, _ := journal.ParseTime(rows[i][0])
+				tj, _, _ := journal.ParseTime(rows[j][0])
+				return ti.Before(tj)
+			})
+			return rows
+		})
+	}
+	refreshStatusBoard()
+	return len(people), marks
+}
+
+// Строки без демо-данных; idCol — колонка с ID пользователя
+func removeDemoRows(rows [][]string, idCol int) [][]string {
+	var keep [][]string
+	for _, row := range rows {
+		if len(row) > idCol && isDemoID(row[idCol]) {
+			continue
+		}
+		keep = append(keep, row)
+	}
+	return keep
+}
+
+func clearDemoData(ctx context.Context) {
+	updateCSV(ctx, usersFile, func(rows [][]string) [][]string { return removeDemoRows(rows, 0) })
+	for _, f := range append(journal.Archives(), dataFile) {
+		updateCSV(ctx, f, func(rows [][]string) [][]string { return removeDemoRows(rows, 1) })
+	}
+	refreshStatusBoard()
+}
+
+// /seed [человек] [недель], /seed clear
+func handleSeedCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 1 && fields[0] == "clear" {
+		clearDemoData(ctx)
+		writeAudit(ctx, adminID, "demo_clear", "")
+		bot.Send(tgbotapi.NewMessage(chatID, "🧹 Демо-данные удалены."))
+		return
+	}
+	if !demoSeedAllowed(ctx) {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ В боте уже есть настоящие пользователи. Демо-данные можно создать только в песочнице (запуск с --sandbox) или в пустом боте."))
+		return
+	}
+	users, weeks := demoUsersDef, demoWeeksDef
+	if len(fields) > 0 {
+		if n, err := strconv.Atoi(fields[0]); err == nil && n > 0 && n <= demoMaxUsers {
+			users = n
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❗ Число человек: от 1 до %d", demoMaxUsers)))
+			return
+		}
+	}
+	if len(fields) > 1 {
+		if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 && n <= demoMaxWeeks {
+			weeks = n
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("❗ Число недель: от 1 до %d", demoMaxWeeks)))
+			return
+		}
+	}
+	people, marks := seedDemoData(ctx, users, weeks, clock.Now())
+	writeAudit(ctx, adminID, "demo_seed", fmt.Sprintf("%d %d", people, marks))
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🎭 Создано демо-данных: %d человек, %d отметок за %d нед.\nУдалить: /seed clear", people, marks, weeks)))
+}
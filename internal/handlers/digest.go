@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Еженедельный дайджест (понедельник, утро) ---
+
+const digestHour = 9
+
+func sendWeeklyDigest(ctx context.Context, bot Sender, now time.Time) {
+	text := buildWeeklyDigest(ctx, now)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, chatID := range adminRecipients(ctx, "summary") {
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		sendWeeklyCharts(ctx, bot, chatID, to.AddDate(0, 0, -7), to)
+	}
+}
+
+func buildWeeklyDigest(ctx context.Context, now time.Time) string {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := to.AddDate(0, 0, -7)
+	rows := journal.ReadSince(ctx, from.AddDate(0, 0, -7))
+	inPeriod := filterRange(from, to)
+
+	var marks, arrivals, departures int
+	for _, row := range rows {
+		if len(row) < 5 || !inPeriod(row) {
+			continue
+		}
+		marks++
+		switch row[3] {
+		case "Прибыл":
+			arrivals++
+		case "Убыл":
+			departures++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🗞 Итоги недели %s — %s\n\n", from.Format("02.01"), to.AddDate(0, 0, -1).Format("02.01")))
+	b.WriteString(fmt.Sprintf("📝 Отметок: %d (🟢 %d / 🔴 %d)\n", marks, arrivals, departures))
+
+	late := findLateArrivals(ctx, rows, from, to)
+	b.WriteString(fmt.Sprintf("⏰ Опозданий: %d\n", len(late)))
+	if len(late) > 0 {
+		counts := make(map[string]int)
+		for _, l := range late {
+			counts[l.Name]++
+		}
+		var names []string
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if counts[names[i]] != counts[names[j]] {
+				return counts[names[i]] > counts[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		if len(names) > 5 {
+			names = names[:5]
+		}
+		for _, name := range names {
+			b.WriteString(fmt.Sprintf("— %s: %d\n", name, counts[name]))
+		}
+	}
+
+	list := collectAbsences(rows, "", from, to)
+	sort.Slice(list, func(i, j int) bool { return list[i].Duration(now) > list[j].Duration(now) })
+	if len(list) > 0 {
+		b.WriteString("\n⌛ Самые долгие отлучки:\n")
+		for i, a := range list {
+			if i == 3 {
+				break
+			}
+			status := ""
+			if a.Open {
+				status = ", не вернулся"
+			}
+			b.WriteString(fmt.Sprintf("— %s: %s (%s, %s%s)\n", a.Name, formatDuration(a.Duration(now)), a.Location, a.Left.Format("02.01 15:04"), status))
+		}
+	}
+
+	anomalies := weeklyAnomalies(rows, list, from, to, now)
+	if len(anomalies) > 0 {
+		b.WriteString("\n⚠️ Аномалии:\n")
+		for _, a := range anomalies {
+			b.WriteString("— " + a + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Ночные прибытия, слишком короткие и слишком долгие отлучки
+func weeklyAnomalies(rows [][]string, list []absence, from, to, now time.Time) []string {
+	var res []string
+	inPeriod := filterRange(from, to)
+	for _, row := range rows {
+		if len(row) < 5 || row[3] != "Прибыл" || !inPeriod(row) {
+			continue
+		}
+		t, _ := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if t.Hour() < 5 {
+			res = append(res, fmt.Sprintf("%s — прибытие ночью (%s)", capitalizeName(row[2]), t.Format("02.01 15:04")))
+		}
+	}
+	for _, a := range list {
+		d := a.Duration(now)
+		if !a.Open && d < time.Minute {
+			res = append(res, fmt.Sprintf("%s — отлучка меньше минуты (%s)", a.Name, a.Left.Format("02.01 15:04")))
+		}
+		if d > 24*time.Hour {
+			res = append(res, fmt.Sprintf("%s — вне части больше суток (%s, с %s)", a.Name, a.Location, a.Left.Format("02.01 15:04")))
+		}
+	}
+	return res
+}
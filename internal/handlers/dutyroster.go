@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- График нарядов и передача дежурства ---
+//
+// duty.csv: начало смены (02.01.2006 15:04), ID заступающего. Смена длится
+// до начала следующей. За DUTY_REMIND_MIN минут (по умолчанию 60) до
+// начала бот напоминает заступающему. Сменяющийся оставляет записку
+// командой /handover — бот пересылает её следующему по графику.
+// Записки хранятся в handover.csv: время, от кого, кому, текст.
+
+const (
+	dutyFile       = "duty.csv"
+	handoverFile   = "handover.csv"
+	dutyTimeLayout = "02.01.2006 15:04"
+	dutyListDays   = 7
+)
+
+type DutyShift struct {
+	Start  time.Time
+	UserID int
+}
+
+var (
+	dutyRemindMu   sync.Mutex
+	dutyRemindSent = make(map[string]bool) // начало|ID
+)
+
+func init() {
+	backupFiles = append(backupFiles, dutyFile, handoverFile)
+}
+
+func dutyRemindLead() time.Duration {
+	if m, err := strconv.Atoi(os.Getenv("DUTY_REMIND_MIN")); err == nil && m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return time.Hour
+}
+
+func loadDutyShifts(ctx context.Context) []DutyShift {
+	var shifts []DutyShift
+	for _, row := range readCSV(ctx, dutyFile) {
+		if len(row) < 2 {
+			continue
+		}
+		start, err := time.ParseInLocation(dutyTimeLayout, row[0], time.Local)
+		uid, err2 := strconv.Atoi(row[1])
+		if err != nil || err2 != nil {
+			continue
+		}
+		shifts = append(shifts, DutyShift{start, uid})
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].Start.Before(shifts[j].Start) })
+	return shifts
+}
+
+// Текущая смена (последняя начавшаяся) и следующая за ней
+func dutyShiftsAround(ctx context.Context, now time.Time) (current, next *DutyShift) {
+	shifts := loadDutyShifts(ctx)
+	for i := range shifts {
+		if shifts[i].Start.After(now) {
+			next = &shifts[i]
+			break
+		}
+		current = &shifts[i]
+	}
+	return current, next
+}
+
+func sendDutyReminders(ctx context.Context, bot Sender, now time.Time) {
+	lead := dutyRemindLead()
+	for _, s := range loadDutyShifts(ctx) {
+		if s.Start.Before(now) || s.Start.Sub(now) > lead {
+			continue
+		}
+		key := s.Start.Format(dutyTimeLayout) + "|" + strconv.Itoa(s.UserID)
+		dutyRemindMu.Lock()
+		sent := dutyRemindSent[key]
+		dutyRemindSent[key] = true
+		dutyRemindMu.Unlock()
+		if sent {
+			continue
+		}
+		text := fmt.Sprintf("🪖 Напоминание: в %s вы заступаете в наряд.", s.Start.Format("15:04 02.01"))
+		if note := lastHandoverFor(ctx, s.UserID, s.Start.Add(-24*time.Hour)); note != "" {
+			text += "\n\n📝 Записка от сменяющегося:\n" + note
+		}
+		bot.Send(tgbotapi.NewMessage(int64(s.UserID), text))
+		incMetric("tabel_reminders_sent_total", `kind="duty"`)
+	}
+}
+
+// Последняя записка для пользователя не старше since
+func lastHandoverFor(ctx context.Context, userID int, since time.Time) string {
+	note := ""
+	for _, row := range readCSV(ctx, handoverFile) {
+		if len(row) < 4 || row[2] != strconv.Itoa(userID) {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		note = row[3]
+	}
+	return note
+}
+
+// /handover <текст> — записка заступающему
+func handleHandoverCommand(ctx context.Context, bot Sender, chatID int64, userID int, text string) {
+	text = strings.TrimSpace(text)
+	current, next := dutyShiftsAround(ctx, clock.Now())
+	if current == nil || current.UserID != userID {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Вы сейчас не на дежурстве по графику."))
+		return
+	}
+	if next == nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Следующая смена в графике не найдена."))
+		return
+	}
+	if text == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "📝 Напишите: /handover <что передать заступающему>"))
+		return
+	}
+	now := clock.Now().Format(dateFormat)
+	appendCSV(ctx, handoverFile, []string{now, strconv.Itoa(userID), strconv.Itoa(next.UserID), text})
+	bot.Send(tgbotapi.NewMessage(int64(next.UserID), fmt.Sprintf(
+		"📝 Передача дежурства от %s (смена с %s):\n%s",
+		capitalizeName(getUserName(ctx, userID, nil)), next.Start.Format("15:04 02.01"), text)))
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ Записка передана: "+capitalizeName(getUserName(ctx, next.UserID, nil))))
+}
+
+// /duty — график на неделю, /duty add <дд.мм.гггг чч:мм> <ID>, /duty del <дд.мм.гггг чч:мм>
+func handleDutyCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 4 && fields[0] == "add":
+		start, err := time.ParseInLocation(dutyTimeLayout, fields[1]+" "+fields[2], time.Local)
+		uid, err2 := strconv.Atoi(fields[3])
+		if err != nil || err2 != nil || !isUserRegistered(ctx, uid) {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /duty add 18.10.2026 08:00 <ID зарегистрированного>"))
+			return
+		}
+		key := start.Format(dutyTimeLayout)
+		updateCSV(ctx, dutyFile, func(rows [][]string) [][]string {
+			var keep [][]string
+			for _, row := range rows {
+				if len(row) > 0 && row[0] != key {
+					keep = append(keep, row)
+				}
+			}
+			return append(keep, []string{key, strconv.Itoa(uid)})
+		})
+		writeAudit(ctx, adminID, "duty_add", key+" "+strconv.Itoa(uid))
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %s: %s", key, capitalizeName(getUserName(ctx, uid, nil)))))
+	case len(fields) == 3 && fields[0] == "del":
+		key := fields[1] + " " + fields[2]
+		if !removeRows(ctx, dutyFile, func(row []string) bool { return row[0] == key }) {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Смена не найдена."))
+			return
+		}
+		writeAudit(ctx, adminID, "duty_del", key)
+		bot.Send(tgbotapi.NewMessage(chatID, "🗑 Смена удалена: "+key))
+	default:
+		now := clock.Now()
+		current, _ := dutyShiftsAround(ctx, now)
+		var b strings.Builder
+		b.WriteString("🪖 График нарядов:\n")
+		if current != nil {
+			b.WriteString(fmt.Sprintf("Сейчас: %s (с %s)\n\n", capitalizeName(getUserName(ctx, current.UserID, nil)), current.Start.Format("15:04 02.01")))
+		}
+		count := 0
+		for _, s := range loadDutyShifts(ctx) {
+			if s.Start.After(now) && s.Start.Before(now.AddDate(0, 0, dutyListDays)) {
+				b.WriteString(fmt.Sprintf("— %s %s: %s\n", weekdayShort[(int(s.Start.Weekday())+6)%7], s.Start.Format("02.01 15:04"), capitalizeName(getUserName(ctx, s.UserID, nil))))
+				count++
+			}
+		}
+		if count == 0 {
+			b.WriteString("на ближайшую неделю смен нет\n")
+		}
+		b.WriteString("\nДобавить: /duty add 18.10.2026 08:00 <ID>\nУдалить: /duty del 18.10.2026 08:00")
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Исправление ввода правкой сообщения ---
+//
+// Если бот ждёт текст (ФИО, локацию, комментарий…), отредактированное
+// сообщение обрабатывается как новое. Если ФИО или локация уже приняты,
+// правка того же сообщения в течение editFixWindow исправляет сохранённое.
+
+const editFixWindow = 10 * time.Minute
+
+type acceptedInput struct {
+	MessageID int
+	Kind      string // "name" или "location"
+	DT        string // время отметки для локации
+	At        time.Time
+}
+
+var lastAcceptedInput = make(map[int]acceptedInput)
+
+func rememberInput(msg *tgbotapi.Message, kind, dt string) {
+	lastAcceptedInput[msg.From.ID] = acceptedInput{msg.MessageID, kind, dt, clock.Now()}
+}
+
+func awaitingTextInput(userID int) bool {
+	if pendingNameInput[userID] || pendingLocationInput[userID] || pendingPersonnelSearch[userID] {
+		return true
+	}
+	if _, ok := pendingComment[userID]; ok {
+		return true
+	}
+	if _, ok := pendingRecordEdit[userID]; ok {
+		return true
+	}
+	if _, ok := pendingRename[userID]; ok {
+		return true
+	}
+	if _, ok := pendingMarkFor[userID]; ok {
+		return true
+	}
+	if _, ok := pendingReturnInput[userID]; ok {
+		return true
+	}
+	_, ok := pendingDangerPhrase[userID]
+	return ok
+}
+
+func handleEditedMessage(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	if msg.From == nil || msg.Text == "" || isGroupChat(msg.Chat) {
+		return
+	}
+	userID := msg.From.ID
+	if awaitingTextInput(userID) {
+		handleMessage(ctx, bot, msg)
+		return
+	}
+	input, ok := lastAcceptedInput[userID]
+	if !ok || input.MessageID != msg.MessageID || clock.Now().Sub(input.At) > editFixWindow {
+		return
+	}
+	text := strings.TrimSpace(msg.Text)
+	switch input.Kind {
+	case "name":
+		normalized, ok := validate.Name(text)
+		if !ok {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Формат неверный, ФИО не изменено. Введите так: Иванов И.И."))
+			return
+		}
+		saveUserName(ctx, userID, normalized, msg.Chat.ID)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ ФИО исправлено: "+normalized))
+	case "location":
+		if len([]rune(text)) < 3 {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Локация не изменена: нужно не менее 3 символов."))
+			return
+		}
+		_, ok := updateRecord(ctx, userID, userID, input.DT, func(row []string) []string {
+			row[4] = text
+			return row
+		})
+		if ok {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Локация исправлена: "+text))
+		}
+	}
+	input.At = clock.Now()
+	lastAcceptedInput[userID] = input
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Проверка геолокации при прибытии ---
+//
+// Если включено (/geo on), для «Прибыл» нужно отправить геопозицию.
+// Координаты и расстояние до части пишутся в 8-ю колонку записи
+// («широта,долгота,метры»); отметки дальше радиуса геозоны помечаются,
+// админам уходит предупреждение. Геозона: /geo set <широта> <долгота> <радиус, м>.
+
+const (
+	colGeo            = 7
+	geoModeKey        = "geo_required"
+	geofenceKey       = "geofence"
+	defaultGeoRadiusM = 500
+)
+
+var pendingGeoArrival = make(map[int]bool)
+
+type Geofence struct {
+	Lat, Lon float64
+	RadiusM  float64
+}
+
+func loadGeofence(ctx context.Context) (Geofence, bool) {
+	parts := strings.Split(getSetting(ctx, geofenceKey, ""), ",")
+	if len(parts) != 3 {
+		return Geofence{}, false
+	}
+	lat, err1 := strconv.ParseFloat(parts[0], 64)
+	lon, err2 := strconv.ParseFloat(parts[1], 64)
+	r, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return Geofence{}, false
+	}
+	return Geofence{lat, lon, r}, true
+}
+
+func geoRequired(ctx context.Context) bool {
+	_, ok := loadGeofence(ctx)
+	return ok && getSetting(ctx, geoModeKey, "") == "1"
+}
+
+// Расстояние по большому кругу, метры
+func distanceM(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusM = 6371000
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusM * math.Asin(math.Sqrt(a))
+}
+
+// Расстояние до части по записи; ok=false, если геопозиции нет
+func markDistance(row []string) (float64, bool) {
+	if len(row) <= colGeo {
+		return 0, false
+	}
+	parts := strings.Split(row[colGeo], ",")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	d, err := strconv.ParseFloat(parts[2], 64)
+	return d, err == nil
+}
+
+// Отметка сделана за пределами геозоны
+func markIsFar(ctx context.Context, row []string) bool {
+	d, ok := markDistance(row)
+	fence, fenceOK := loadGeofence(ctx)
+	return ok && fenceOK && d > fence.RadiusM
+}
+
+func askArrivalLocation(bot Sender, chatID int64, userID int) {
+	pendingGeoArrival[userID] = true
+	msg := tgbotapi.NewMessage(chatID, "📍 Для отметки прибытия отправьте геопозицию кнопкой ниже.")
+	msg.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonLocation("📍 Отправить геопозицию")),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton("❌ Отмена")),
+	)
+	bot.Send(msg)
+}
+
+func handleGeoArrivalInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	if msg.Location == nil {
+		if strings.TrimSpace(msg.Text) == "❌ Отмена" {
+			delete(pendingGeoArrival, userID)
+			reply := tgbotapi.NewMessage(msg.Chat.ID, "Отметка отменена.")
+			reply.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+			bot.Send(reply)
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "📍 Нужна геопозиция — нажмите кнопку «Отправить геопозицию»."))
+		return
+	}
+	delete(pendingGeoArrival, userID)
+	fence, _ := loadGeofence(ctx)
+	lat, lon := msg.Location.Latitude, msg.Location.Longitude
+	dist := distanceM(fence.Lat, fence.Lon, lat, lon)
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, userID, msg.From)
+	saveAttendanceRow(ctx, []string{now, strconv.Itoa(userID), name, "Прибыл", "-", "", "",
+		fmt.Sprintf("%.6f,%.6f,%.0f", lat, lon, dist)})
+	notifyAdminAboutMark(ctx, bot, userID, name, "Прибыл", "-", now)
+
+	text := "✅ Прибытие отмечено!"
+	if dist > fence.RadiusM {
+		text += fmt.Sprintf("\n⚠️ Вы в %.0f м от части, отметка помечена.", dist)
+		warn := fmt.Sprintf("⚠️ <b>Прибытие вне геозоны</b>\n👤 %s\n📏 %.0f м от части\n⏰ %s", name, dist, now)
+		for _, chatID := range adminRecipients(ctx, "notifications") {
+			if adminSeesUser(ctx, chatID, strconv.Itoa(userID)) {
+				sendAdminNotification(ctx, bot, chatID, warn)
+			}
+		}
+	}
+	reply := tgbotapi.NewMessage(msg.Chat.ID, "📍 Геопозиция получена.")
+	reply.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	bot.Send(reply)
+	bot.Send(markConfirmation(msg.Chat.ID, text, now))
+	sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+}
+
+// /geo — состояние, /geo on|off, /geo set <широта> <долгота> [радиус, м]
+func handleGeoCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 1 && (fields[0] == "on" || fields[0] == "off"):
+		if _, ok := loadGeofence(ctx); !ok && fields[0] == "on" {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Сначала задайте геозону: /geo set <широта> <долгота> <радиус, м>"))
+			return
+		}
+		value := ""
+		if fields[0] == "on" {
+			value = "1"
+		}
+		setSetting(ctx, geoModeKey, value)
+		writeAudit(ctx, adminID, "geo_mode", fields[0])
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Проверка геолокации: "+fields[0]))
+	case (len(fields) == 3 || len(fields) == 4) && fields[0] == "set":
+		lat, err1 := strconv.ParseFloat(fields[1], 64)
+		lon, err2 := strconv.ParseFloat(fields[2], 64)
+		radius := float64(defaultGeoRadiusM)
+		var err3 error
+		if len(fields) == 4 {
+			radius, err3 = strconv.ParseFloat(fields[3], 64)
+		}
+		if err1 != nil || err2 != nil || err3 != nil || radius <= 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /geo set 55.751244 37.618423 500"))
+			return
+		}
+		value := fmt.Sprintf("%.6f,%.6f,%.0f", lat, lon, radius)
+		setSetting(ctx, geofenceKey, value)
+		writeAudit(ctx, adminID, "geofence", value)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Геозона: %.6f, %.6f, радиус %.0f м", lat, lon, radius)))
+	default:
+		text := "📍 Проверка геолокации: "
+		if geoRequired(ctx) {
+			text += "включена\n"
+		} else {
+			text += "выключена\n"
+		}
+		if fence, ok := loadGeofence(ctx); ok {
+			text += fmt.Sprintf("Геозона: %.6f, %.6f, радиус %.0f м\n", fence.Lat, fence.Lon, fence.RadiusM)
+		} else {
+			text += "Геозона не задана\n"
+		}
+		text += "\nВключить: /geo on\nВыключить: /geo off\nГеозона: /geo set <широта> <долгота> <радиус, м>"
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+	}
+}
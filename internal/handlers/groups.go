@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Групповые чаты: /who и /summary ---
+//
+// Отвечать сводкой бот будет только в чатах, одобренных главным админом
+// (/allowchat в самом чате, /denychat — убрать). Список — в chats.csv.
+
+const chatsFile = "chats.csv"
+
+func init() {
+	backupFiles = append(backupFiles, chatsFile)
+}
+
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat.IsGroup() || chat.IsSuperGroup()
+}
+
+func isChatApproved(ctx context.Context, chatID int64) bool {
+	id := strconv.FormatInt(chatID, 10)
+	for _, row := range readCSV(ctx, chatsFile) {
+		if len(row) > 0 && row[0] == id {
+			return true
+		}
+	}
+	return false
+}
+
+func setChatApproved(ctx context.Context, chatID int64, title string, approved bool) {
+	id := strconv.FormatInt(chatID, 10)
+	updateCSV(ctx, chatsFile, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if len(row) > 0 && row[0] != id {
+				keep = append(keep, row)
+			}
+		}
+		if approved {
+			keep = append(keep, []string{id, title})
+		}
+		return keep
+	})
+}
+
+// Команды в группе; true — команда обработана
+func handleGroupCommand(ctx context.Context, bot Sender, msg *tgbotapi.Message) bool {
+	chat := msg.Chat
+	switch msg.Command() {
+	case "who", "summary":
+		if !isChatApproved(ctx, chat.ID) {
+			bot.Send(tgbotapi.NewMessage(chat.ID, fmt.Sprintf("🔒 Чат не одобрен. Главный админ может разрешить его командой /allowchat (ID чата: %d).", chat.ID)))
+			return true
+		}
+		bot.Send(tgbotapi.NewMessage(chat.ID, presenceText(ctx)))
+	case "allowchat":
+		if !isRootAdmin(ctx, msg.From.ID) {
+			return true
+		}
+		setChatApproved(ctx, chat.ID, chat.Title, true)
+		writeAudit(ctx, msg.From.ID, "allow_chat", fmt.Sprintf("%d %s", chat.ID, chat.Title))
+		bot.Send(tgbotapi.NewMessage(chat.ID, "✅ Чат одобрен: /who покажет, кто в части."))
+	case "denychat":
+		if !isRootAdmin(ctx, msg.From.ID) {
+			return true
+		}
+		setChatApproved(ctx, chat.ID, chat.Title, false)
+		writeAudit(ctx, msg.From.ID, "deny_chat", fmt.Sprintf("%d %s", chat.ID, chat.Title))
+		bot.Send(tgbotapi.NewMessage(chat.ID, "🔒 Доступ к сводке из этого чата закрыт."))
+	default:
+		return false
+	}
+	return true
+}
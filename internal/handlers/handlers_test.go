@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Подмены Sender и Clock для тестов обработчиков ---
+
+// Запоминает всё, что обработчик отправил, вместо похода в Telegram
+type fakeSender struct {
+	sent []tgbotapi.Chattable
+}
+
+func (f *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.sent = append(f.sent, c)
+	return tgbotapi.Message{}, nil
+}
+
+func (f *fakeSender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.sent = append(f.sent, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeSender) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeSender) AnswerCallbackQuery(c tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error) {
+	f.sent = append(f.sent, c)
+	return tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeSender) GetFileDirectURL(fileID string) (string, error) { return "", nil }
+
+func (f *fakeSender) BotUsername() string { return "tabel_test_bot" }
+
+// Тексты отправленных сообщений
+func (f *fakeSender) texts() []string {
+	var out []string
+	for _, c := range f.sent {
+		if msg, ok := c.(tgbotapi.MessageConfig); ok {
+			out = append(out, msg.Text)
+		}
+	}
+	return out
+}
+
+// Часы, которые стоят на месте; Sleep только сдвигает время
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func (c *fakeClock) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.Sleep(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch, func() bool { return false }
+}
+
+// Пустой каталог данных и часы на момент now; всё возвращается после теста
+func setupHandlerTest(t *testing.T, now time.Time) (*fakeSender, *fakeClock) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	prev := clock
+	fc := &fakeClock{now: now}
+	clock = fc
+	t.Cleanup(func() {
+		clock = prev
+		os.Chdir(wd)
+	})
+	return &fakeSender{}, fc
+}
+
+func TestQuietCommand(t *testing.T) {
+	ctx := context.Background()
+	bot, _ := setupHandlerTest(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local))
+
+	handleQuietCommand(ctx, bot, 1, "25:00-06:00")
+	if texts := bot.texts(); len(texts) != 1 || !strings.Contains(texts[0], "Формат") {
+		t.Fatalf("неверный интервал: ответ %q", texts)
+	}
+	if got := quietHoursSetting(ctx); got != "" {
+		t.Fatalf("неверный интервал сохранён: %q", got)
+	}
+
+	handleQuietCommand(ctx, bot, 1, "23:00 - 06:00")
+	if got := quietHoursSetting(ctx); got != "23:00-06:00" {
+		t.Fatalf("quiet_hours = %q", got)
+	}
+}
+
+func TestNonCriticalWaitsForQuietHoursEnd(t *testing.T) {
+	ctx := context.Background()
+	bot, fc := setupHandlerTest(t, time.Date(2026, 3, 2, 23, 30, 0, 0, time.Local))
+	setSetting(ctx, "quiet_hours", "23:00-06:00")
+
+	sendNonCritical(ctx, bot, tgbotapi.NewMessage(42, "Напоминание"))
+	if len(bot.sent) != 0 {
+		t.Fatalf("в тихие часы отправлено: %q", bot.texts())
+	}
+
+	fc.Sleep(8 * time.Hour)
+	if isQuietTime(ctx, fc.Now()) {
+		t.Fatal("07:30 считается тихим временем")
+	}
+	queued := takeQuietQueue(ctx)
+	if len(queued) != 1 {
+		t.Fatalf("в очереди %d сообщений, ожидалось 1", len(queued))
+	}
+	msg, ok := quietQueueMessage(queued[0])
+	if !ok || msg.ChatID != 42 || msg.Text != "Напоминание" {
+		t.Fatalf("из очереди прочитано %+v", msg)
+	}
+	if len(takeQuietQueue(ctx)) != 0 {
+		t.Fatal("очередь не очищена")
+	}
+}
+
+// Кнопка проходит через router: без права — отказ, у главного админа — обработчик
+func TestCallbackRights(t *testing.T) {
+	ctx := context.Background()
+	bot, _ := setupHandlerTest(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local))
+	press := func(userID int, data string) {
+		handleAction(ctx, bot, &tgbotapi.CallbackQuery{
+			ID:      "q",
+			From:    &tgbotapi.User{ID: userID},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}},
+			Data:    data,
+		})
+	}
+
+	press(rootAdminID(ctx)+1, "danger")
+	if len(bot.sent) != 1 {
+		t.Fatalf("без права отправлено %d сообщений", len(bot.sent))
+	}
+	if answer, ok := bot.sent[0].(tgbotapi.CallbackConfig); !ok || answer.Text != "⛔ Недостаточно прав" {
+		t.Fatalf("без права ответ %+v", bot.sent[0])
+	}
+
+	bot.sent = nil
+	press(rootAdminID(ctx), "danger")
+	if texts := bot.texts(); len(texts) == 0 {
+		t.Fatal("главному админу опасная зона не открылась")
+	}
+}
+
+// Окно отмены отметки считается по clock, а не по настенным часам
+func TestUndoGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	bot, fc := setupHandlerTest(t, start)
+	undo := func() string {
+		bot.sent = nil
+		handleUndoMark(ctx, bot, &tgbotapi.CallbackQuery{
+			ID:      "q",
+			From:    &tgbotapi.User{ID: 7},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 7}},
+			Data:    fmt.Sprintf("undo_%d", start.Unix()),
+		})
+		for _, c := range bot.sent {
+			if answer, ok := c.(tgbotapi.CallbackConfig); ok {
+				return answer.Text
+			}
+		}
+		return ""
+	}
+	appendCSV(ctx, dataFile, []string{start.Format(dateFormat), "7", "Иванов И.И.", "Прибыл", "Часть"})
+
+	fc.Sleep(undoGracePeriod + time.Second)
+	if got := undo(); got != "Время для отмены истекло" {
+		t.Fatalf("после окна ответ %q", got)
+	}
+	if len(readCSV(ctx, dataFile)) != 1 {
+		t.Fatal("отметка удалена после окна отмены")
+	}
+
+	fc.now = start.Add(undoGracePeriod - time.Second)
+	if got := undo(); got != "Отменено" {
+		t.Fatalf("в окне ответ %q", got)
+	}
+	if len(readCSV(ctx, dataFile)) != 0 {
+		t.Fatal("отметка не удалена")
+	}
+}
+
+// Отметку перед полуночью последнего дня месяца можно отменить и после
+// того, как ротация перенесла её в архив
+func TestUndoAfterMonthRollover(t *testing.T) {
+	ctx := context.Background()
+	mark := time.Date(2026, 3, 31, 23, 58, 0, 0, time.Local)
+	bot, fc := setupHandlerTest(t, mark)
+	appendCSV(ctx, dataFile, []string{mark.Format(dateFormat), "7", "Иванов И.И.", "Убыл", "Домой"})
+	fc.Sleep(3 * time.Minute)
+	journal.Archive(ctx, fc.Now())
+	if len(readCSV(ctx, dataFile)) != 0 || len(readCSV(ctx, journal.ArchiveFile(mark))) != 1 {
+		t.Fatal("ротация не перенесла отметку в архив")
+	}
+
+	handleUndoMark(ctx, bot, &tgbotapi.CallbackQuery{
+		ID:      "q",
+		From:    &tgbotapi.User{ID: 7},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 7}},
+		Data:    fmt.Sprintf("undo_%d", mark.Unix()),
+	})
+	if rows := readCSV(ctx, journal.ArchiveFile(mark)); len(rows) != 0 {
+		t.Fatalf("в архиве осталось %q", rows)
+	}
+}
+
+// Откат «users» не задваивает журнал; отметка после очистки сохраняется
+func TestDangerUndoKeepsJournal(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	_, fc := setupHandlerTest(t, now)
+	appendCSV(ctx, usersFile, []string{"7", "Иванов И.И.", "7"})
+	for _, h := range []int{8, 9, 10} {
+		dt := time.Date(2026, 3, 2, h, 0, 0, 0, time.Local).Format(dateFormat)
+		appendCSV(ctx, dataFile, []string{dt, "7", "Иванов И.И.", "Прибыл", "Часть"})
+	}
+
+	stamp, err := saveDangerSnapshot(fc.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, _ := findDangerOp("users")
+	runDangerOp(ctx, 1, op)
+	fc.Sleep(time.Minute)
+	appendCSV(ctx, dataFile, []string{fc.Now().Format(dateFormat), "8", "Петров П.П.", "Прибыл", "Часть"})
+
+	if err := restoreDangerSnapshot(ctx, 1, stamp); err != nil {
+		t.Fatal(err)
+	}
+	if rows := readCSV(ctx, dataFile); len(rows) != 4 {
+		t.Fatalf("в журнале %d строк, ожидалось 4: %q", len(rows), rows)
+	}
+	if len(readCSV(ctx, usersFile)) != 1 {
+		t.Fatal("пользователи не восстановлены")
+	}
+}
+
+// Восстановление старой копии не подмешивает месяцы, появившиеся после неё
+func TestRestoreReplacesJournalMonths(t *testing.T) {
+	ctx := context.Background()
+	setupHandlerTest(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local))
+	january := time.Date(2026, 1, 15, 9, 0, 0, 0, time.Local)
+	appendCSV(ctx, journal.ArchiveFile(january), []string{january.Format(dateFormat), "7", "Иванов И.И.", "Прибыл", "Часть"})
+	data, err := buildBackupArchive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	february := time.Date(2026, 2, 10, 9, 0, 0, 0, time.Local)
+	appendCSV(ctx, journal.ArchiveFile(february), []string{february.Format(dateFormat), "7", "Иванов И.И.", "Прибыл", "Часть"})
+
+	if err := restoreBackup(ctx, data); err != nil {
+		t.Fatal(err)
+	}
+	if files := journal.Archives(); len(files) != 1 || files[0] != journal.ArchiveFile(january) {
+		t.Fatalf("архивы после восстановления: %v", files)
+	}
+}
+
+// Админ, закреплённый за подразделением, не откроет сводку чужого
+func TestUnitSummaryRespectsScope(t *testing.T) {
+	ctx := context.Background()
+	bot, _ := setupHandlerTest(t, time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local))
+	appendCSV(ctx, unitsFile, []string{"1 взвод"})
+	appendCSV(ctx, unitsFile, []string{"2 взвод"})
+	saveAdminRights(ctx, 5, "Сидоров С.С.", map[string]bool{"summary": true})
+	setSetting(ctx, scopeKey(5), "1 взвод")
+
+	press := func(data string) string {
+		bot.sent = nil
+		handleAction(ctx, bot, &tgbotapi.CallbackQuery{
+			ID:      "q",
+			From:    &tgbotapi.User{ID: 5},
+			Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 5}},
+			Data:    data,
+		})
+		for _, c := range bot.sent {
+			if answer, ok := c.(tgbotapi.CallbackConfig); ok {
+				return answer.Text
+			}
+		}
+		return ""
+	}
+	if got := press("usum_1"); got != "⛔ Недостаточно прав" {
+		t.Fatalf("чужое подразделение: ответ %q", got)
+	}
+	if got := press("usum_0"); got != "" || len(bot.texts()) != 1 {
+		t.Fatalf("своё подразделение: ответ %q, сообщения %q", got, bot.texts())
+	}
+}
+
+// Отчёт, догнанный после полуночи, видит положение на 19:00 прошлого дня
+func TestLastRowsAtMissedReport(t *testing.T) {
+	ctx := context.Background()
+	report := time.Date(2026, 3, 2, 19, 0, 0, 0, time.Local)
+	setupHandlerTest(t, report.Add(6*time.Hour))
+	appendCSV(ctx, dataFile, []string{report.Add(-time.Hour).Format(dateFormat), "7", "Иванов И.И.", "Убыл", "Домой"})
+	appendCSV(ctx, dataFile, []string{report.Add(5 * time.Hour).Format(dateFormat), "7", "Иванов И.И.", "Прибыл", "Часть"})
+
+	if row := lastRowsAt(ctx, report)["7"]; row == nil || row[3] != "Убыл" {
+		t.Fatalf("на %s ожидалось «Убыл», получено %q", report.Format("02.01 15:04"), row)
+	}
+	if row := lastRowsAt(ctx, report.Add(6*time.Hour))["7"]; row == nil || row[3] != "Прибыл" {
+		t.Fatalf("сейчас ожидалось «Прибыл», получено %q", row)
+	}
+}
+
+// Занятый marks.wal не держит апдейт дольше его контекста, а принятая
+// отметка дописывается, когда хранилище освободится
+func TestMarkDoesNotWaitForBusyStorage(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local)
+	setupHandlerTest(t, now)
+	bg := context.Background()
+	unlock, err := journal.Lock(bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(bg, 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		saveAttendanceRow(ctx, []string{now.Format(dateFormat), "7", "Иванов И.И.", "Прибыл", "-"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("отметка ждала хранилище дольше контекста апдейта")
+	}
+	unlock()
+	for i := 0; i < 100 && len(readCSV(bg, dataFile)) == 0; i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if rows := readCSV(bg, dataFile); len(rows) != 1 {
+		t.Fatalf("в журнале %d строк, ожидалась 1", len(rows))
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"tabel-go/internal/config"
+)
+
+// --- Доступ к HTTP-эндпоинтам ---
+//
+// Открыты только пинг на / и Mini App (его запросы подписаны initData).
+// Панель, /api/v1, /api/stream и метрики требуют API-токен (заголовок
+// "Authorization: Bearer" или ?token=) либо логин и пароль из
+// HTTP_BASIC_USER / HTTP_BASIC_PASSWORD, область для них — HTTP_BASIC_SCOPE
+// (по умолчанию read). HTTP_ALLOW_IPS — адреса и подсети через запятую;
+// если задан, закрытые эндпоинты и /admin доступны только с них. За
+// прокси Render адрес клиента берётся из X-Forwarded-For при HTTP_TRUST_PROXY=1.
+
+const httpAuthRealm = "Tabel"
+
+// Строки сравниваются за одинаковое время: сравниваются их SHA-256
+func secureEqual(a, b string) bool {
+	ha, hb := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+func clientIP(r *http.Request) net.IP {
+	if os.Getenv("HTTP_TRUST_PROXY") == "1" {
+		// Последний адрес добавлен нашим прокси, предыдущие мог подставить клиент
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			parts := strings.Split(fwd, ",")
+			return net.ParseIP(strings.TrimSpace(parts[len(parts)-1]))
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+func ipAllowed(r *http.Request) bool {
+	list := strings.TrimSpace(os.Getenv("HTTP_ALLOW_IPS"))
+	if list == "" {
+		return true
+	}
+	ip := clientIP(r)
+	if ip == nil {
+		return false
+	}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "/") {
+			_, network, err := net.ParseCIDR(item)
+			if err != nil {
+				log.Printf("HTTP_ALLOW_IPS: неверная подсеть %q", item)
+				continue
+			}
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(item); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func basicAuthEnabled() bool {
+	return os.Getenv("HTTP_BASIC_USER") != "" && config.Secret("HTTP_BASIC_PASSWORD") != ""
+}
+
+func basicAuthScope() string {
+	if scope := os.Getenv("HTTP_BASIC_SCOPE"); tokenScopes[scope] > 0 {
+		return scope
+	}
+	return "read"
+}
+
+// Область доступа запроса по токену или логину; "" — не авторизован
+func requestScope(r *http.Request) string {
+	ctx := r.Context()
+	if token := requestToken(r); token != "" {
+		return tokenScope(ctx, token)
+	}
+	if user, pass, ok := r.BasicAuth(); ok && basicAuthEnabled() {
+		// Оба сравнения выполняются всегда, чтобы время ответа не выдавало логин
+		userOK := secureEqual(user, os.Getenv("HTTP_BASIC_USER"))
+		passOK := secureEqual(pass, config.Secret("HTTP_BASIC_PASSWORD"))
+		if userOK && passOK {
+			return basicAuthScope()
+		}
+	}
+	return ""
+}
+
+// Доступ есть: адрес разрешён и области хватает
+func authorized(r *http.Request, scope string) bool {
+	have := requestScope(r)
+	return ipAllowed(r) && have != "" && tokenScopes[have] >= tokenScopes[scope]
+}
+
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ipAllowed(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		have := requestScope(r)
+		if have == "" {
+			if basicAuthEnabled() {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+httpAuthRealm+`", charset="UTF-8"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if tokenScopes[have] < tokenScopes[scope] {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Только проверка адреса — для страниц со своим входом (/admin)
+func restrictIP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ipAllowed(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
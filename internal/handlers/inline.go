@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Инлайн-запросы «@бот …» ---
+//
+// Работают в любом чате для админов с правом summary (инлайн-режим
+// включается у @BotFather). Пустой запрос или «кто вне части» — сводка,
+// иначе поиск людей по части ФИО с их текущим статусом.
+
+const inlineResultsLimit = 20
+
+func inlineWantsSummary(q string) bool {
+	q = strings.ToLower(q)
+	return q == "" || strings.Contains(q, "вне части") || strings.Contains(q, "сводка") || strings.Contains(q, "кто")
+}
+
+// Текущее состояние человека по последней отметке
+func userStatusText(ctx context.Context, u User) string {
+	text := "👤 " + capitalizeName(u.Name)
+	row := findLastRow(ctx, strconv.Itoa(u.ID))
+	if row == nil {
+		return text + "\nОтметок ещё нет"
+	}
+	text += fmt.Sprintf("\n%s %s", actionEmoji(row[3]), row[3])
+	if row[3] == "Убыл" {
+		text += ": " + cleanLocation(row[4])
+		if t, ok := expectedReturn(row); ok {
+			text += "\n⏳ Вернётся к " + t.Format("02.01 15:04")
+		}
+	}
+	text += "\n⏰ с " + row[0]
+	if unit := userUnits(ctx)[strconv.Itoa(u.ID)]; unit != "" {
+		text += "\n🏷 " + unit
+	}
+	return text
+}
+
+func handleInlineQuery(ctx context.Context, bot Sender, q *tgbotapi.InlineQuery) {
+	answer := tgbotapi.InlineConfig{InlineQueryID: q.ID, IsPersonal: true, CacheTime: 0}
+	if !hasRight(ctx, q.From.ID, "summary") {
+		bot.Request(answer)
+		return
+	}
+	adminChat := int64(q.From.ID)
+	query := strings.TrimSpace(q.Query)
+	var results []interface{}
+	if inlineWantsSummary(query) {
+		s, scope := loadPresence(ctx), adminScope(ctx, q.From.ID)
+		text := s.text(func(uid string) bool { return scope == "" || s.Units[uid] == scope })
+		article := tgbotapi.NewInlineQueryResultArticle("summary", "📊 Сводка: кто в части и вне её", text)
+		article.Description = "Текущее состояние по последним отметкам"
+		results = append(results, article)
+	}
+	if query != "" {
+		needle := strings.ToLower(query)
+		for _, u := range scopedUsers(ctx, adminChat) {
+			if len(results) >= inlineResultsLimit {
+				break
+			}
+			if !strings.Contains(strings.ToLower(u.Name), needle) {
+				continue
+			}
+			text := userStatusText(ctx, u)
+			article := tgbotapi.NewInlineQueryResultArticle("u"+strconv.Itoa(u.ID), capitalizeName(u.Name), text)
+			if lines := strings.SplitN(text, "\n", 3); len(lines) > 1 {
+				article.Description = lines[1]
+			}
+			results = append(results, article)
+		}
+	}
+	answer.Results = results
+	bot.Request(answer)
+}
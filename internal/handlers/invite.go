@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Регистрация по пригласительным ссылкам ---
+//
+// Ссылка t.me/<бот>?start=<код> привязана к подразделению (или ни к
+// какому). Кто пришёл по ней, регистрируется и сразу попадает в
+// подразделение. Режим «только по приглашениям» включён по умолчанию
+// (/invite only off — открытая регистрация): /start без кода сразу
+// получает отказ, если список ЛС (/roster) пуст; иначе можно
+// зарегистрироваться, только найдя своё ФИО в этом списке. Админов режим
+// не касается.
+// invites.csv: код, подразделение, кто создал, когда.
+
+const (
+	invitesFile   = "invites.csv"
+	inviteOnlyKey = "invite_only"
+)
+
+// Пользователь -> подразделение из приглашения (пусто — без подразделения)
+var pendingInvite = make(map[int]string)
+
+func init() {
+	backupFiles = append(backupFiles, invitesFile)
+}
+
+func inviteOnly(ctx context.Context) bool {
+	return getSetting(ctx, inviteOnlyKey, "1") == "1"
+}
+
+func newInviteCode() string {
+	b := make([]byte, 5)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Подразделение по коду; ok=false — кода нет
+func findInvite(ctx context.Context, code string) (unit string, ok bool) {
+	for _, row := range readCSV(ctx, invitesFile) {
+		if len(row) > 1 && row[0] == code {
+			return row[1], true
+		}
+	}
+	return "", false
+}
+
+func inviteLink(bot Sender, code string) string {
+	return "https://t.me/" + bot.BotUsername() + "?start=" + code
+}
+
+// /start <код> от незарегистрированного; false — в регистрации отказано
+func acceptInvite(ctx context.Context, bot Sender, chatID int64, userID int, code string) bool {
+	if code != "" {
+		if unit, ok := findInvite(ctx, code); ok {
+			pendingInvite[userID] = unit
+			return true
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Пригласительная ссылка недействительна. Попросите новую у командира."))
+		return false
+	}
+	if !inviteOnly(ctx) || isAdminAny(ctx, userID) {
+		return true
+	}
+	if len(loadRoster(ctx)) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, inviteOnlyRefusal))
+		return false
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, "🔒 Регистрация по пригласительной ссылке. Без неё — только если вы есть в списке личного состава."))
+	return true
+}
+
+const inviteOnlyRefusal = "🔒 Регистрация только по пригласительной ссылке. Обратитесь к командиру."
+
+// Без приглашения в режиме «только по приглашениям» регистрируются лишь
+// те, кто нашёлся в списке ЛС (claimRosterEntry) — сюда они не доходят
+func registrationAllowed(ctx context.Context, userID int) bool {
+	if !inviteOnly(ctx) || isAdminAny(ctx, userID) {
+		return true
+	}
+	_, invited := pendingInvite[userID]
+	return invited
+}
+
+// После сохранения ФИО: подразделение из приглашения
+func completeInvite(ctx context.Context, userID int) {
+	unit, ok := pendingInvite[userID]
+	if !ok {
+		return
+	}
+	delete(pendingInvite, userID)
+	if unit != "" {
+		setUserUnit(ctx, userID, unit)
+	}
+	writeAudit(ctx, userID, "invite_used", unit)
+}
+
+// /invite — список, /invite new [подразделение], /invite del <код>, /invite only on|off
+func handleInviteCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	switch {
+	case fields[0] == "new":
+		unit := ""
+		if len(fields) == 2 {
+			if unit = findUnit(ctx, fields[1]); unit == "" {
+				bot.Send(tgbotapi.NewMessage(chatID, "❗ Подразделение не найдено. Список: /units"))
+				return
+			}
+		}
+		code := newInviteCode()
+		appendCSV(ctx, invitesFile, []string{code, unit, fmt.Sprint(adminID), clock.Now().Format(dateFormat)})
+		writeAudit(ctx, adminID, "invite_new", code+" "+unit)
+		text := "🔗 Пригласительная ссылка"
+		if unit != "" {
+			text += " в «" + unit + "»"
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, text+":\n"+inviteLink(bot, code)))
+	case fields[0] == "del" && len(fields) == 2:
+		code := strings.TrimSpace(fields[1])
+		if !removeRows(ctx, invitesFile, func(row []string) bool { return row[0] == code }) {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Код не найден."))
+			return
+		}
+		writeAudit(ctx, adminID, "invite_del", code)
+		bot.Send(tgbotapi.NewMessage(chatID, "🗑 Ссылка отозвана."))
+	case fields[0] == "only" && len(fields) == 2 && (fields[1] == "on" || fields[1] == "off"):
+		value := ""
+		if fields[1] == "on" {
+			value = "1"
+		}
+		setSetting(ctx, inviteOnlyKey, value)
+		writeAudit(ctx, adminID, "invite_only", fields[1])
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Регистрация только по приглашениям: "+fields[1]))
+	default:
+		var b strings.Builder
+		b.WriteString("🔗 Пригласительные ссылки:\n")
+		rows := readCSV(ctx, invitesFile)
+		if len(rows) == 0 {
+			b.WriteString("пока нет\n")
+		}
+		for _, row := range rows {
+			if len(row) < 2 {
+				continue
+			}
+			unit := row[1]
+			if unit == "" {
+				unit = noUnit
+			}
+			b.WriteString(fmt.Sprintf("— %s: %s\n", unit, inviteLink(bot, row[0])))
+		}
+		mode := "выкл"
+		if inviteOnly(ctx) {
+			mode = "вкл"
+		}
+		b.WriteString("\nТолько по приглашениям: " + mode)
+		b.WriteString("\n\nСоздать: /invite new [подразделение]\nОтозвать: /invite del <код>\nРежим: /invite only on|off")
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/scheduler"
+)
+
+// --- Периодические задачи ---
+
+// Расписание задачи меняется переменной CRON_<ИМЯ> (например,
+// CRON_REPORT="0 20 * * 1-5", CRON_REMINDERS="* * * * 1-5"),
+// отключается командой /jobs off <имя>.
+// Запуск, пропущенный из-за простоя (бесплатный хостинг засыпает),
+// выполняется при старте, если опоздание не больше CatchUp задачи.
+
+var jobs *scheduler.Scheduler
+
+// Sender задач: живёт весь срок работы бота, в отличие от Sender апдейта
+var jobsBot Sender
+
+// Имена встроенных задач; свои задачи (customjobs.go) их занять не могут
+var builtinJobs = []string{"reminders", "report", "digest", "autoexport", "archive", "backup",
+	"overdue", "duty", "anomalies", "delivery", "s3backup"}
+
+func jobSpec(name, def string) string {
+	if spec := strings.TrimSpace(os.Getenv("CRON_" + strings.ToUpper(name))); spec != "" {
+		return spec
+	}
+	return def
+}
+
+func jobEnabled(ctx context.Context, name string) func() bool {
+	return func() bool { return getSetting(ctx, "job_"+name, "on") != "off" }
+}
+
+// Ежедневная копия: CRON_BACKUP или, по-старому, BACKUP_HOUR; без них задачи нет
+func backupSpec() string {
+	if spec := jobSpec("backup", ""); spec != "" {
+		return spec
+	}
+	hourStr := os.Getenv("BACKUP_HOUR")
+	if hourStr == "" {
+		return ""
+	}
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil || hour < 0 || hour > 23 {
+		log.Printf("BACKUP_HOUR: неверное значение %q", hourStr)
+		return ""
+	}
+	return fmt.Sprintf("0 %d * * *", hour)
+}
+
+// Напоминания проверяются каждую минуту. Старое CRON_REMINDERS
+// («30 18 * * *») по-прежнему действует, но тогда личное время из
+// /remind срабатывает только в моменты этого расписания.
+func remindersSpec() string {
+	spec := jobSpec("reminders", "* * * * *")
+	if spec != "* * * * *" {
+		log.Printf("CRON_REMINDERS=%q: напоминания проверяются только по этому расписанию, личное время (/remind) учитывается в эти моменты", spec)
+	}
+	return spec
+}
+
+func botJobs(ctx context.Context, bot Sender) []scheduler.Job {
+	list := []scheduler.Job{
+		// Каждую минуту: у каждого своё время напоминания (reminders.go)
+		{Name: "reminders", Spec: remindersSpec(), Quiet: true,
+			Run: func(ctx context.Context, now time.Time) error {
+				runReminders(ctx, bot, now)
+				return nil
+			}},
+		{Name: "report", Spec: jobSpec("report", fmt.Sprintf("0 %d * * *", reportHour)), CatchUp: 4 * time.Hour,
+			Run: func(ctx context.Context, now time.Time) error {
+				if isDutyDay(ctx, now) {
+					sendDailyReport(ctx, bot, now)
+				}
+				return nil
+			}},
+		{Name: "digest", Spec: jobSpec("digest", fmt.Sprintf("0 %d * * 1", digestHour)), CatchUp: 12 * time.Hour,
+			Run: func(ctx context.Context, now time.Time) error {
+				sendWeeklyDigest(ctx, bot, now)
+				return nil
+			}},
+		{Name: "autoexport", Spec: jobSpec("autoexport", fmt.Sprintf("0 %d * * *", autoExportHour)), Jitter: time.Minute, CatchUp: 12 * time.Hour,
+			Run: func(ctx context.Context, now time.Time) error {
+				sendAutoExports(ctx, bot, now)
+				return nil
+			}},
+		{Name: "archive", Spec: jobSpec("archive", "5 0 1 * *"), Jitter: time.Minute,
+			Run: func(ctx context.Context, now time.Time) error {
+				journal.Archive(ctx, now)
+				return nil
+			}},
+		// Просроченные возвращения (overdue.go) и смены дежурных (dutyroster.go)
+		{Name: "overdue", Spec: jobSpec("overdue", "* * * * *"), Quiet: true,
+			Run: func(ctx context.Context, now time.Time) error {
+				checkOverdue(ctx, bot, now)
+				return nil
+			}},
+		{Name: "duty", Spec: jobSpec("duty", "* * * * *"), Quiet: true,
+			Run: func(ctx context.Context, now time.Time) error {
+				sendDutyReminders(ctx, bot, now)
+				return nil
+			}},
+		{Name: "anomalies", Spec: jobSpec("anomalies", "* * * * *"), Quiet: true,
+			Run: func(ctx context.Context, _ time.Time) error {
+				sendAnomalyAlerts(ctx, bot)
+				return nil
+			}},
+		{Name: "delivery", Spec: jobSpec("delivery", "*/30 * * * *"),
+			Run: func(ctx context.Context, _ time.Time) error {
+				return reportDeliveryFailures(ctx, bot)
+			}},
+	}
+	if spec := backupSpec(); spec != "" {
+		list = append(list, scheduler.Job{Name: "backup", Spec: spec, Jitter: time.Minute, CatchUp: 12 * time.Hour,
+			Run: func(ctx context.Context, _ time.Time) error {
+				sendBackup(bot, int64(rootAdminID(ctx)))
+				return nil
+			}})
+	}
+	if cfg, ok := loadS3Config(); ok {
+		spec := s3BackupSpec(cfg)
+		log.Printf("s3: выгрузка копий по расписанию %q в %s/%s", spec, cfg.Endpoint, cfg.Bucket)
+		list = append(list, scheduler.Job{Name: "s3backup", Spec: spec, Jitter: time.Minute, CatchUp: cfg.Interval,
+			Run: func(ctx context.Context, now time.Time) error {
+				return runS3Backup(ctx, cfg, now)
+			}})
+	}
+	for i := range list {
+		list[i].Enabled = jobEnabled(ctx, list[i].Name)
+	}
+	return list
+}
+
+// Время последнего запуска задачи; нулевое, если она ещё не запускалась
+func jobLastRun(ctx context.Context, name string) time.Time {
+	sec, err := strconv.ParseInt(getSetting(ctx, "jobrun_"+name, ""), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func startJobs(ctx context.Context, bot Sender) {
+	jobsBot = bot
+	jobs = scheduler.New(clock)
+	jobs.OnError = func(name string, err error) {
+		captureError(err, map[string]string{"job": name})
+	}
+	jobs.LastRun = func(name string) time.Time { return jobLastRun(ctx, name) }
+	jobs.SaveRun = func(name string, t time.Time) {
+		setSetting(ctx, "jobrun_"+name, strconv.FormatInt(t.Unix(), 10))
+	}
+	for _, job := range botJobs(ctx, bot) {
+		if err := jobs.Add(job); err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+	}
+	for _, job := range unitReportJobs(ctx, bot) {
+		if err := jobs.Add(job); err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+	}
+	for _, j := range loadCustomJobs(ctx) {
+		if err := jobs.Add(j.schedulerJob(ctx, bot)); err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+	}
+	jobs.Start(shutdownCtx)
+	// Архивация догоняет пропущенное, пока бот был выключен
+	go jobs.RunNow(shutdownCtx, "archive")
+}
+
+// --- /jobs: список задач, on|off <имя>, run <имя> ---
+
+func handleJobsCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	if jobs == nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "Планировщик не запущен."))
+		return
+	}
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, jobsText()))
+		return
+	}
+	if len(fields) != 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Использование: /jobs [on|off|run <задача>]"))
+		return
+	}
+	action, name := fields[0], fields[1]
+	known := false
+	for _, st := range jobs.Status() {
+		if st.Name == name {
+			known = true
+		}
+	}
+	if !known {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Нет такой задачи: "+name))
+		return
+	}
+	switch action {
+	case "on", "off":
+		setSetting(ctx, "job_"+name, action)
+		writeAudit(ctx, adminID, "job_"+action, name)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Задача %s: %s", name, action)))
+	case "run":
+		writeAudit(ctx, adminID, "job_run", name)
+		bot.Send(tgbotapi.NewMessage(chatID, "▶️ Запускаю "+name+"…"))
+		go func() {
+			jobs.RunNow(shutdownCtx, name)
+			jobsBot.Send(tgbotapi.NewMessage(chatID, "Готово: "+name))
+		}()
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, "Использование: /jobs [on|off|run <задача>]"))
+	}
+}
+
+func jobsText() string {
+	var b strings.Builder
+	b.WriteString("⏰ Задачи по расписанию\n")
+	for _, st := range jobs.Status() {
+		mark := "🟢"
+		if !st.Enabled {
+			mark = "⚪️"
+		}
+		fmt.Fprintf(&b, "\n%s %s — %s", mark, st.Name, st.Spec)
+		if !st.Next.IsZero() {
+			fmt.Fprintf(&b, "\n   следующий запуск: %s", st.Next.Format("02.01 15:04"))
+		}
+		if !st.LastRun.IsZero() {
+			result := "ok"
+			if st.Err != nil {
+				result = "ошибка: " + st.Err.Error()
+			}
+			fmt.Fprintf(&b, "\n   последний: %s, %s, %s", st.LastRun.Format("02.01 15:04"),
+				st.Duration.Round(time.Millisecond), result)
+		}
+	}
+	return b.String()
+}
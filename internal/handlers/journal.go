@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Личный журнал с листалкой ---
+
+const journalPageSize = 5
+
+var journalPeriods = []struct {
+	Code string
+	Name string
+}{
+	{"week", "7 дней"},
+	{"month", "30 дней"},
+	{"all", "Всё время"},
+}
+
+func journalSince(period string) time.Time {
+	switch period {
+	case "week":
+		return daysAgo(7)
+	case "month":
+		return daysAgo(30)
+	}
+	return time.Time{}
+}
+
+// Записи пользователя начиная с since, от новых к старым
+func getUserHistory(ctx context.Context, userID string, since time.Time) [][]string {
+	rows := journal.ReadSince(ctx, since)
+	var history [][]string
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if len(row) < 5 || row[1] != userID {
+			continue
+		}
+		if !since.IsZero() {
+			t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+			if err != nil || t.Before(since) {
+				continue
+			}
+		}
+		history = append(history, row)
+	}
+	return history
+}
+
+func actionEmoji(action string) string {
+	switch action {
+	case "Прибыл":
+		return "🟢"
+	case "Убыл":
+		return "🔴"
+	}
+	if st, ok := findStatus(action); ok {
+		return st.Emoji
+	}
+	return "❓"
+}
+
+func formatJournalEntry(ctx context.Context, e []string) string {
+	date, timePart := splitDateTime(e[0])
+	flag := ""
+	if markEnteredBy(e) != 0 {
+		flag = " | ✍️ внесено админом"
+	}
+	if isAutoMark(e) {
+		flag = " | 🛰 автоматически"
+	}
+	if markPhoto(e) != "" {
+		flag += " | 📷 фото"
+	}
+	if markIsFar(ctx, e) {
+		d, _ := markDistance(e)
+		flag += fmt.Sprintf(" | 📍 %.0f м от части", d)
+	}
+	if c := markComment(e); c != "" {
+		flag += "\n💬 " + c
+	}
+	return fmt.Sprintf("%s %s %s\n%s | %s | %s%s\n\n", actionEmoji(e[3]), e[3], e[4], date, timePart, e[2], flag)
+}
+
+func sendJournalPage(ctx context.Context, bot Sender, chatID int64, userID string, period string, page int) {
+	renderJournalPage(ctx, bot, chatID, userID, period, page, "jpage_", "📖 Журнал")
+}
+
+// Журнал выбранного пользователя для админа (из карточки ЛС)
+func sendUserJournalPage(ctx context.Context, bot Sender, chatID int64, userID string, period string, page int) {
+	uid, _ := strconv.Atoi(userID)
+	title := "📖 Журнал: " + capitalizeName(getUserName(ctx, uid, nil))
+	renderJournalPage(ctx, bot, chatID, userID, period, page, "ujpage_"+userID+"_", title)
+}
+
+// prefix — начало callback листалки; выбор даты есть только в личном журнале
+func renderJournalPage(ctx context.Context, bot Sender, chatID int64, userID, period string, page int, prefix, title string) {
+	history := getUserHistory(ctx, userID, journalSince(period))
+	if len(history) == 0 {
+		msg := tgbotapi.NewMessage(chatID, title+"\n\nЗаписей не найдено.")
+		msg.ReplyMarkup = journalKeyboard(prefix, period, 0, 0)
+		bot.Send(msg)
+		return
+	}
+	pages := (len(history) + journalPageSize - 1) / journalPageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	var resp strings.Builder
+	resp.WriteString(fmt.Sprintf("%s — стр. %d из %d\n\n", title, page+1, pages))
+	start := page * journalPageSize
+	end := start + journalPageSize
+	if end > len(history) {
+		end = len(history)
+	}
+	chrono := make([][]string, len(history))
+	for i, e := range history {
+		chrono[len(history)-1-i] = e
+	}
+	anomalies := detectAnomalies(ctx, chrono)
+	for _, e := range history[start:end] {
+		entry := formatJournalEntry(ctx, e)
+		if titles := anomalies[anomalyKey(e)]; len(titles) > 0 {
+			entry = strings.TrimSuffix(entry, "\n") + "⚠️ " + strings.Join(titles, ", ") + "\n\n"
+		}
+		resp.WriteString(entry)
+	}
+	msg := tgbotapi.NewMessage(chatID, resp.String())
+	kb := journalKeyboard(prefix, period, page, pages)
+	if prefix != "jpage_" {
+		kb.InlineKeyboard = append(journalPhotoRows(userID, history[start:end]), kb.InlineKeyboard...)
+	}
+	msg.ReplyMarkup = kb
+	bot.Send(msg)
+}
+
+func journalKeyboard(prefix, period string, page, pages int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️ Новее", fmt.Sprintf("%s%s_%d", prefix, period, page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Старше ▶️", fmt.Sprintf("%s%s_%d", prefix, period, page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	var filters []tgbotapi.InlineKeyboardButton
+	for _, p := range journalPeriods {
+		label := p.Name
+		if p.Code == period {
+			label = "• " + label
+		}
+		filters = append(filters, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%s_0", prefix, p.Code)))
+	}
+	rows = append(rows, filters)
+	if prefix == "jpage_" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Выбрать дату", "jcal_"+clock.Now().Format(callbackMonthLayout)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Разбор callback вида jpage_<период>_<страница>
+func parseJournalCallback(data string) (period string, page int, ok bool) {
+	return parseJournalParts(strings.TrimPrefix(data, "jpage_"))
+}
+
+// Разбор ujpage_<ID>_<период>_<страница>
+func parseUserJournalCallback(data string) (userID, period string, page int, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(data, "ujpage_"), "_", 2)
+	if len(parts) != 2 {
+		return "", "", 0, false
+	}
+	period, page, ok = parseJournalParts(parts[1])
+	return parts[0], period, page, ok
+}
+
+func parseJournalParts(s string) (period string, page int, ok bool) {
+	parts := strings.Split(s, "_")
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[0], page, true
+}
+
+// --- Журнал по дате: календарь и хронология по дням ---
+
+const (
+	callbackDateLayout  = "2006-01-02"
+	callbackMonthLayout = "2006-01"
+)
+
+var weekdayShort = []string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+var monthNames = []string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+	"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"}
+
+// Календарь на месяц. Если rangeStart задан — выбирается конец периода.
+func sendDatePicker(bot Sender, chatID int64, month time.Time, rangeStart string) {
+	text := "📅 Выберите день:"
+	if rangeStart != "" {
+		start, _ := time.Parse(callbackDateLayout, rangeStart)
+		text = fmt.Sprintf("📅 Начало периода: %s\nВыберите последний день:", start.Format("02.01.2006"))
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = datePickerKeyboard(month, rangeStart)
+	bot.Send(msg)
+}
+
+func datePickerKeyboard(month time.Time, rangeStart string) tgbotapi.InlineKeyboardMarkup {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	monthCallback := func(m time.Time) string {
+		if rangeStart != "" {
+			return "jcalr_" + rangeStart + "_" + m.Format(callbackMonthLayout)
+		}
+		return "jcal_" + m.Format(callbackMonthLayout)
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("◀️", monthCallback(first.AddDate(0, -1, 0))),
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s %d", monthNames[first.Month()-1], first.Year()), "noop"),
+		tgbotapi.NewInlineKeyboardButtonData("▶️", monthCallback(first.AddDate(0, 1, 0))),
+	))
+	var header []tgbotapi.InlineKeyboardButton
+	for _, d := range weekdayShort {
+		header = append(header, tgbotapi.NewInlineKeyboardButtonData(d, "noop"))
+	}
+	rows = append(rows, header)
+	// Понедельник — первый день недели
+	offset := (int(first.Weekday()) + 6) % 7
+	week := make([]tgbotapi.InlineKeyboardButton, 0, 7)
+	for i := 0; i < offset; i++ {
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", "noop"))
+	}
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		data := "jday_" + d.Format(callbackDateLayout)
+		if rangeStart != "" {
+			data = "jrange_" + rangeStart + "_" + d.Format(callbackDateLayout)
+		}
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(d.Day()), data))
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = make([]tgbotapi.InlineKeyboardButton, 0, 7)
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, tgbotapi.NewInlineKeyboardButtonData(" ", "noop"))
+		}
+		rows = append(rows, week)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Хронология отметок за период [from, to] по дням
+func sendJournalTimeline(ctx context.Context, bot Sender, chatID int64, userID string, from, to time.Time) {
+	end := to.AddDate(0, 0, 1)
+	rows := journal.ReadRange(ctx, from, end)
+	var b strings.Builder
+	period := from.Format("02.01.2006")
+	if !to.Equal(from) {
+		period += " — " + to.Format("02.01.2006")
+	}
+	b.WriteString("📖 Журнал за " + period + "\n")
+	lastDate := ""
+	count := 0
+	for _, row := range rows {
+		if len(row) < 5 || row[1] != userID {
+			continue
+		}
+		date, timePart := splitDateTime(row[0])
+		if date != lastDate {
+			b.WriteString("\n📅 " + date + "\n")
+			lastDate = date
+		}
+		line := fmt.Sprintf("  %s %s %s", timePart, actionEmoji(row[3]), row[3])
+		if row[3] != "Прибыл" && row[4] != "-" {
+			line += " (" + cleanLocation(row[4]) + ")"
+		}
+		b.WriteString(line + "\n")
+		count++
+	}
+	if count == 0 {
+		b.WriteString("\nЗаписей не найдено.")
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	if from.Equal(to) {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➡️ До другой даты", "jcalr_"+from.Format(callbackDateLayout)+"_"+from.Format(callbackMonthLayout)),
+		))
+	}
+	bot.Send(msg)
+}
+
+// Обработка jcal_/jcalr_/jday_/jrange_, возвращает false если data не про календарь
+func handleJournalDateCallback(ctx context.Context, bot Sender, chatID int64, userID string, data string) bool {
+	switch {
+	case strings.HasPrefix(data, "jcal_"):
+		month, err := time.ParseInLocation(callbackMonthLayout, strings.TrimPrefix(data, "jcal_"), time.Local)
+		if err == nil {
+			sendDatePicker(bot, chatID, month, "")
+		}
+	case strings.HasPrefix(data, "jcalr_"):
+		parts := strings.Split(strings.TrimPrefix(data, "jcalr_"), "_")
+		if len(parts) != 2 {
+			return true
+		}
+		month, err := time.ParseInLocation(callbackMonthLayout, parts[1], time.Local)
+		if err == nil {
+			sendDatePicker(bot, chatID, month, parts[0])
+		}
+	case strings.HasPrefix(data, "jday_"):
+		day, err := time.ParseInLocation(callbackDateLayout, strings.TrimPrefix(data, "jday_"), time.Local)
+		if err == nil {
+			sendJournalTimeline(ctx, bot, chatID, userID, day, day)
+		}
+	case strings.HasPrefix(data, "jrange_"):
+		parts := strings.Split(strings.TrimPrefix(data, "jrange_"), "_")
+		if len(parts) != 2 {
+			return true
+		}
+		from, err1 := time.ParseInLocation(callbackDateLayout, parts[0], time.Local)
+		to, err2 := time.ParseInLocation(callbackDateLayout, parts[1], time.Local)
+		if err1 != nil || err2 != nil {
+			return true
+		}
+		if to.Before(from) {
+			from, to = to, from
+		}
+		sendJournalTimeline(ctx, bot, chatID, userID, from, to)
+	default:
+		return false
+	}
+	return true
+}
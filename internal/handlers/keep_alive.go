@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// --- HTTP-сервер ---
+//
+// Один сервер на всё: пинг для Render, веб-панель, админка, Mini App, API.
+// Адрес задаётся HTTP_HOST и HTTP_PORT (иначе PORT от Render, иначе 10000).
+// HTTP_BASE_PATH — префикс всех путей, если бот стоит за прокси в
+// подкаталоге, например /tabel. Обработчики регистрируются через
+// handleHTTP на собственном mux, ссылки на страницах строятся httpPath.
+
+var (
+	httpMux      = http.NewServeMux()
+	httpBasePath = normalizeBasePath(os.Getenv("HTTP_BASE_PATH"))
+)
+
+func normalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+func handleHTTP(pattern string, handler http.HandlerFunc) {
+	httpMux.HandleFunc(pattern, handler)
+}
+
+// Путь с учётом HTTP_BASE_PATH — для ссылок и редиректов
+func httpPath(p string) string {
+	return httpBasePath + p
+}
+
+func httpAddr() string {
+	port := os.Getenv("HTTP_PORT")
+	if port == "" {
+		port = os.Getenv("PORT")
+	}
+	if port == "" {
+		port = "10000"
+	}
+	return net.JoinHostPort(os.Getenv("HTTP_HOST"), port)
+}
+
+func httpHandler() http.Handler {
+	if httpBasePath == "" {
+		return httpMux
+	}
+	stripped := http.StripPrefix(httpBasePath, httpMux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == httpBasePath:
+			http.Redirect(w, r, httpBasePath+"/", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, httpBasePath+"/"):
+			stripped.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+var httpServer *http.Server
+
+func StartKeepAlive() {
+	handleHTTP("/", serveDashboard)
+	srv := &http.Server{
+		Addr:              httpAddr(),
+		Handler:           httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		// Долгие ответы (/api/stream) снимают ограничение сами
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  120 * time.Second,
+		// Запросы отменяются при остановке бота — в том числе /api/stream
+		BaseContext: func(net.Listener) context.Context { return shutdownCtx },
+	}
+	httpServer = srv
+	go func() {
+		log.Printf("HTTP-сервер: %s%s", srv.Addr, httpBasePath)
+		if err := srv.ListenAndServe(); err != nil {
+			log.Printf("HTTP-сервер остановлен: %v", err)
+		}
+	}()
+}
+
+func stopHTTPServer(ctx context.Context) {
+	if httpServer == nil {
+		return
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Printf("HTTP-сервер: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Постоянные кнопки внизу чата ---
+//
+// Альтернатива инлайн-меню: обычная клавиатура «Прибыл / Убыл / Журнал»,
+// которая не уезжает вверх вместе с сообщениями. Включается каждым
+// пользователем для себя (/keyboard или кнопка в меню), хранится
+// в settings.csv как keyboard:<ID>.
+
+const (
+	keyboardKeyPrefix = "keyboard:"
+	quickArrived      = "🟢 Прибыл"
+	quickLeft         = "🔴 Убыл"
+	quickJournal      = "📖 Журнал"
+)
+
+func quickKeyboardEnabled(ctx context.Context, userID int) bool {
+	return getSetting(ctx, keyboardKeyPrefix+strconv.Itoa(userID), "") == "1"
+}
+
+func quickKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	kb := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(
+		tgbotapi.NewKeyboardButton(quickArrived),
+		tgbotapi.NewKeyboardButton(quickLeft),
+		tgbotapi.NewKeyboardButton(quickJournal),
+	))
+	kb.ResizeKeyboard = true
+	return kb
+}
+
+// Показывает клавиатуру заново, например после /start
+func sendQuickKeyboard(bot Sender, chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "⌨️ Кнопки отметки — внизу чата.")
+	msg.ReplyMarkup = quickKeyboard()
+	bot.Send(msg)
+}
+
+func setQuickKeyboard(ctx context.Context, bot Sender, chatID int64, userID int, on bool) {
+	key := keyboardKeyPrefix + strconv.Itoa(userID)
+	if on {
+		setSetting(ctx, key, "1")
+		sendQuickKeyboard(bot, chatID)
+		return
+	}
+	setSetting(ctx, key, "")
+	msg := tgbotapi.NewMessage(chatID, "⌨️ Постоянные кнопки убраны, отмечайтесь через меню.")
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+	bot.Send(msg)
+}
+
+// /keyboard on|off
+func handleKeyboardCommand(ctx context.Context, bot Sender, chatID int64, userID int, args string) {
+	switch strings.TrimSpace(args) {
+	case "on":
+		setQuickKeyboard(ctx, bot, chatID, userID, true)
+	case "off":
+		setQuickKeyboard(ctx, bot, chatID, userID, false)
+	default:
+		state := "выключены"
+		if quickKeyboardEnabled(ctx, userID) {
+			state = "включены"
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, "⌨️ Постоянные кнопки внизу чата: "+state+
+			"\nВключить: /keyboard on\nВыключить: /keyboard off"))
+	}
+}
+
+// kbd_toggle — переключение из главного меню
+func handleKeyboardAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	setQuickKeyboard(ctx, bot, query.Message.Chat.ID, query.From.ID, !quickKeyboardEnabled(ctx, query.From.ID))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+// Нажатие на постоянную кнопку; false — это не кнопка
+func handleQuickKeyboard(ctx context.Context, bot Sender, msg *tgbotapi.Message) bool {
+	userID := msg.From.ID
+	switch msg.Text {
+	case quickArrived:
+		delete(pendingLocationInput, userID)
+		markArrived(ctx, bot, msg.Chat.ID, msg.From)
+	case quickLeft:
+		delete(pendingLocationInput, userID)
+		askDeparture(ctx, bot, msg.Chat.ID, userID)
+	case quickJournal:
+		sendJournalPage(ctx, bot, msg.Chat.ID, strconv.Itoa(userID), "all", 0)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Текущее состояние по последним отметкам ---
+//
+// status.csv хранит последнюю отметку каждого пользователя: новая отметка
+// обновляет одну строку таблицы, и findLastRow не перечитывает журнал.
+// Любая другая запись в журнал или архивы (правка, удаление, очистка,
+// восстановление) помечает таблицу устаревшей, и при следующем обращении
+// она пересобирается одним проходом по журналу. Файл служебный, в бэкап
+// не входит — он всегда восстановим из журнала.
+
+const statusFile = "status.csv"
+
+var (
+	statusMu    sync.Mutex
+	statusRows  map[string][]string // ID -> последняя отметка
+	statusStale = true
+	statusSize  int64     // размер рабочего файла журнала
+	statusMod   time.Time // на момент последнего обновления
+)
+
+// Вызывается из writeCSV и после восстановления из бэкапа
+func invalidateCaches(filename string) {
+	journal.Invalidate(filename)
+	if filename == usersFile {
+		invalidateUserRegistry()
+	}
+	if filename == dataFile || journal.IsArchive(filename) {
+		statusMu.Lock()
+		statusStale = true
+		statusMu.Unlock()
+	}
+}
+
+func dataFileStamp() (int64, time.Time) {
+	info, err := os.Stat(dataFile)
+	if err != nil {
+		return 0, time.Time{}
+	}
+	return info.Size(), info.ModTime()
+}
+
+// Таблица актуальна: не помечена и рабочий файл не меняли в обход бота
+func statusFreshLocked() bool {
+	if statusStale || statusRows == nil {
+		return false
+	}
+	size, mod := dataFileStamp()
+	return size == statusSize && mod.Equal(statusMod)
+}
+
+// Один проход: архивы от старых к новым, затем рабочий файл
+func rebuildStatusLocked(ctx context.Context) {
+	rows := make(map[string][]string)
+	for _, f := range append(journal.Archives(), dataFile) {
+		for _, row := range journal.Index(ctx, f).Rows {
+			if len(row) > 4 {
+				rows[row[1]] = row
+			}
+		}
+	}
+	statusRows = rows
+	saveStatusLocked(ctx)
+}
+
+func saveStatusLocked(ctx context.Context) {
+	out := make([][]string, 0, len(statusRows))
+	for _, row := range statusRows {
+		out = append(out, row)
+	}
+	writeCSV(ctx, statusFile, out)
+	statusStale = false
+	statusSize, statusMod = dataFileStamp()
+}
+
+// При запуске: таблица с диска, если она не старше журнала
+func loadStatusTable(ctx context.Context) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	info, err := os.Stat(statusFile)
+	_, mod := dataFileStamp()
+	if err != nil || info.ModTime().Before(mod) {
+		rebuildStatusLocked(ctx)
+		return
+	}
+	statusRows = make(map[string][]string)
+	for _, row := range readCSV(ctx, statusFile) {
+		if len(row) > 4 {
+			statusRows[row[1]] = row
+		}
+	}
+	statusStale = false
+	statusSize, statusMod = dataFileStamp()
+}
+
+// Снимок таблицы; строки не изменять
+func lastRows(ctx context.Context) map[string][]string {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if !statusFreshLocked() {
+		rebuildStatusLocked(ctx)
+	}
+	snapshot := make(map[string][]string, len(statusRows))
+	for id, row := range statusRows {
+		snapshot[id] = row
+	}
+	return snapshot
+}
+
+// Последние отметки на момент at. Пока в таблице нет отметок позже at
+// (обычная сводка), это та же таблица; иначе — проход по журналу до at,
+// например для отчёта, догнанного после простоя.
+func lastRowsAt(ctx context.Context, at time.Time) map[string][]string {
+	rows := lastRows(ctx)
+	later := false
+	for _, row := range rows {
+		if t, err := time.ParseInLocation(dateFormat, row[0], time.Local); err == nil && t.After(at) {
+			later = true
+			break
+		}
+	}
+	if !later {
+		return rows
+	}
+	atMonth := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.Local)
+	rows = make(map[string][]string)
+	for _, f := range append(journal.Archives(), dataFile) {
+		if m, ok := journal.ArchiveMonth(f); ok && m.After(atMonth) {
+			continue
+		}
+		for _, row := range journal.Index(ctx, f).Rows {
+			if len(row) <= 4 {
+				continue
+			}
+			if t, err := time.ParseInLocation(dateFormat, row[0], time.Local); err == nil && !t.After(at) {
+				rows[row[1]] = row
+			}
+		}
+	}
+	return rows
+}
+
+func lastRowFor(ctx context.Context, userID string) ([]string, bool) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if !statusFreshLocked() {
+		rebuildStatusLocked(ctx)
+	}
+	row, ok := statusRows[userID]
+	return row, ok
+}
+
+// Последняя запись пользователя — копия строки из таблицы текущего состояния
+func findLastRow(ctx context.Context, userID string) []string {
+	if row, ok := lastRowFor(ctx, userID); ok {
+		return append([]string(nil), row...)
+	}
+	return nil
+}
+
+// Новая отметка дописана в журнал. wasFresh — таблица была актуальна до
+// записи; тогда достаточно обновить одну строку
+func recordLastRow(ctx context.Context, wasFresh bool, row []string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if !wasFresh {
+		rebuildStatusLocked(ctx)
+		return
+	}
+	statusRows[row[1]] = row
+	saveStatusLocked(ctx)
+}
+
+func statusTableFresh() bool {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	return statusFreshLocked()
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Опоздания относительно начала рабочего дня ---
+//
+// Начало дня — настройка workday_start (или WORKDAY_START), по умолчанию 08:30.
+// Опозданием считается «Прибыл» в рабочий день позже начала, если на начало
+// дня человек был вне части (последняя отметка до начала — «Убыл»).
+
+type lateMark struct {
+	UID     string
+	Name    string
+	Time    time.Time
+	Minutes int
+}
+
+func workdayStartSetting(ctx context.Context) string {
+	def := os.Getenv("WORKDAY_START")
+	if def == "" {
+		def = "08:30"
+	}
+	return getSetting(ctx, "workday_start", def)
+}
+
+func workdayStartOn(ctx context.Context, day time.Time) time.Time {
+	t, err := time.Parse("15:04", workdayStartSetting(ctx))
+	if err != nil {
+		t, _ = time.Parse("15:04", "08:30")
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+// Опоздания в интервале [from, to). rows должны включать записи до from,
+// чтобы знать статус на начало первого дня.
+func findLateArrivals(ctx context.Context, rows [][]string, from, to time.Time) []lateMark {
+	cal := loadWorkCalendar(ctx)
+	prev := make(map[string]time.Time) // время последней «Убыл» пользователя
+	var late []lateMark
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil || !t.Before(to) {
+			continue
+		}
+		switch row[3] {
+		case "Убыл":
+			prev[row[1]] = t
+		case "Прибыл":
+			left, wasOut := prev[row[1]]
+			delete(prev, row[1])
+			if !wasOut || t.Before(from) || !cal.IsDutyDay(t) {
+				continue
+			}
+			start := workdayStartOn(ctx, t)
+			if t.After(start) && left.Before(start) {
+				late = append(late, lateMark{UID: row[1], Name: capitalizeName(row[2]), Time: t, Minutes: int(t.Sub(start).Minutes())})
+			}
+		}
+	}
+	return late
+}
+
+// Блок «Опоздали сегодня» для сводки
+func todayLateSection(ctx context.Context) string {
+	return lateSection(ctx, daysAgo(0))
+}
+
+// Опоздавшие за день day; для прошедшего дня в заголовке — его дата
+func lateSection(ctx context.Context, day time.Time) string {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	late := findLateArrivals(ctx, journal.ReadRange(ctx, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1)), day, day.AddDate(0, 0, 1))
+	if len(late) == 0 {
+		return ""
+	}
+	sort.Slice(late, func(i, j int) bool { return late[i].Name < late[j].Name })
+	when := "сегодня"
+	if !day.Equal(daysAgo(0)) {
+		when = day.Format("02.01")
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n⏰ Опоздали %s (%d):\n", when, len(late)))
+	for _, l := range late {
+		b.WriteString(fmt.Sprintf("— %s (%s, +%d мин)\n", l.Name, l.Time.Format("15:04"), l.Minutes))
+	}
+	return b.String()
+}
+
+// Отчёт об опоздавших за последние n дней
+func sendLateReport(ctx context.Context, bot Sender, chatID int64, days int) {
+	from := daysAgo(days - 1)
+	to := daysAgo(-1)
+	late := findLateArrivals(ctx, scopedRows(ctx, chatID, journal.ReadSince(ctx, from.AddDate(0, 0, -7))), from, to)
+	if len(late) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ За %d дн. опозданий нет (начало дня %s).", days, workdayStartSetting(ctx))))
+		return
+	}
+	type agg struct {
+		Name    string
+		Count   int
+		Minutes int
+		Dates   []string
+	}
+	byUser := make(map[string]*agg)
+	for _, l := range late {
+		a, ok := byUser[l.UID]
+		if !ok {
+			a = &agg{Name: l.Name}
+			byUser[l.UID] = a
+		}
+		a.Count++
+		a.Minutes += l.Minutes
+		a.Dates = append(a.Dates, l.Time.Format("02.01 15:04"))
+	}
+	list := make([]*agg, 0, len(byUser))
+	for _, a := range byUser {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⏰ Опоздания за %d дн. (начало дня %s)\n\n", days, workdayStartSetting(ctx)))
+	for _, a := range list {
+		b.WriteString(fmt.Sprintf("— %s: %d раз, всего %d мин\n   %s\n", a.Name, a.Count, a.Minutes, strings.Join(a.Dates, ", ")))
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
+
+func handleWorkdayCommand(ctx context.Context, bot Sender, chatID int64, args string) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "🕗 Начало рабочего дня: "+workdayStartSetting(ctx)+"\nИзменить: /workday 08:30"))
+		return
+	}
+	if _, err := time.Parse("15:04", args); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /workday ЧЧ:ММ"))
+		return
+	}
+	setSetting(ctx, "workday_start", args)
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ Начало рабочего дня: "+args))
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/config"
+)
+
+// --- Запуск и аварийное завершение ---
+//
+// Главный админ получает сообщение при каждом старте бота (версия,
+// хранилище, объёмы данных), при панике в основном цикле или в фоновой
+// задаче и при остановке по сигналу — на Render это рестарт или деплой.
+// При остановке бот перестаёт принимать апдейты, дорабатывает текущий и
+// останавливает HTTP-сервер (запросы ждут до shutdownGrace).
+//
+// Контекст доходит до сетевых вызовов: отправки в Telegram (через
+// WithContext), S3, Google Sheets, загрузки файлов из Telegram и
+// входящих HTTP-запросов, — и до хранилища: ожидание занятого CSV-файла
+// обрывается вместе с контекстом (storage.go), так что зависшая запись
+// не держит цикл апдейтов дольше updateTimeout.
+
+const (
+	updateTimeout          = 30 * time.Second
+	shutdownGrace          = 10 * time.Second
+	telegramRequestTimeout = 90 * time.Second
+)
+
+// Контекст работы бота: отменяется при остановке. От него идут контексты
+// HTTP-запросов и фоновых выгрузок.
+var shutdownCtx, shutdown = context.WithCancel(context.Background())
+
+func storageDescription() string {
+	text := "CSV в рабочем каталоге"
+	if _, ok := dataKey(); ok {
+		text += ", зашифрованы"
+	}
+	if sandboxMode {
+		text += ", песочница"
+	}
+	if _, ok := loadS3Config(); ok {
+		text += ", копии в S3"
+	}
+	return text
+}
+
+func notifyStartup(ctx context.Context, bot Sender) {
+	txt := fmt.Sprintf("🚀 Бот запущен\n\n"+
+		"Версия: %s\n"+
+		"Хранилище: %s\n"+
+		"Пользователей: %d\n"+
+		"Админов: %d\n"+
+		"Подразделений: %d\n"+
+		"Отметок за месяц: %d",
+		versionString(), storageDescription(), len(getSortedUsers(ctx)), len(getAdmins(ctx)), len(loadUnits(ctx)), len(readCSV(ctx, dataFile)))
+	if _, err := bot.Send(tgbotapi.NewMessage(int64(rootAdminID(ctx)), txt)); err != nil {
+		log.Printf("startup: не удалось уведомить главного админа: %v", err)
+	}
+}
+
+// Вызывается через defer: сообщает о панике и продолжает её
+func reportCrash(bot Sender, where string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	// Контекст упавшего кода мог уже истечь, а сообщить о падении нужно
+	ctx := context.Background()
+	stack := string(debug.Stack())
+	if len(stack) > 3000 {
+		stack = stack[:3000] + "\n…"
+	}
+	stack = config.Redact(stack)
+	log.Printf("panic в %s: %v\n%s", where, r, stack)
+	if cp, ok := r.(capturedPanic); ok {
+		r = cp.Value
+	} else {
+		captureEvent("fatal", fmt.Sprintf("panic: %v", r), map[string]string{"task": where},
+			map[string]interface{}{"stack": stack})
+	}
+	bot.Send(tgbotapi.NewMessage(int64(rootAdminID(ctx)),
+		config.Redact(fmt.Sprintf("💥 Бот упал (%s)\n\n%v\n\n%s", where, r, stack))))
+	panic(r)
+}
+
+// Фоновая задача, о падении которой узнает главный админ
+func watched(ctx context.Context, bot Sender, where string, task func(context.Context, Sender)) {
+	defer reportCrash(bot, where)
+	task(ctx, bot)
+}
+
+// SIGTERM/SIGINT: сообщить об остановке и отменить shutdownCtx;
+// остальное доделывает finishShutdown из основного цикла
+func notifyOnShutdown(ctx context.Context, bot Sender) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		s := <-sig
+		log.Printf("остановка по сигналу %v", s)
+		bot.Send(tgbotapi.NewMessage(int64(rootAdminID(ctx)), fmt.Sprintf("⏹ Бот останавливается (%v)", s)))
+		shutdown()
+	}()
+}
+
+// Останавливает HTTP-сервер; текущий апдейт к этому моменту уже обработан
+func finishShutdown(bot Sender) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	stopHTTPServer(ctx)
+}
+
+// Апдейты обрабатываются по одному: состояние ввода (pending*) — обычные
+// map без блокировок. Через updateTimeout контекст апдейта истекает, и
+// зависший обработчик больше ничего не отправляет — отправки
+// возвращают ошибку, и он быстро доходит до конца. Остановка бота
+// текущий апдейт не прерывает.
+func dispatchUpdate(bot Sender, update tgbotapi.Update) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+	handleUpdate(ctx, bot, update)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("update: обработка дольше %s (%v)", updateTimeout, updateContext(update))
+		incMetric("tabel_update_timeouts_total", "")
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Нормы отсутствия по локациям ---
+//
+// Сколько обычно длится отлучка в локацию: «Столовая 1 ч», «Поликлиника
+// 4 ч». Если при убытии не указано время возвращения, срок считается по
+// норме, и после него контроль опозданий (overdue.go) напоминает
+// пользователю, а затем сообщает админам — в любое время суток, не
+// дожидаясь вечернего напоминания. Нормы задаются командой /limits и
+// хранятся в location_limits.csv (локация без эмодзи, минуты; 0 — без нормы).
+
+const locationLimitsFile = "location_limits.csv"
+
+func init() {
+	backupFiles = append(backupFiles, locationLimitsFile)
+}
+
+// Нормы по умолчанию, пока админ не задал свои
+var defaultLocationLimits = map[string]time.Duration{
+	"Магазин":  time.Hour,
+	"Столовая": time.Hour,
+}
+
+func locationLimitKey(loc string) string {
+	return strings.ToLower(cleanLocation(loc))
+}
+
+// Все нормы: ключ локации -> длительность (0 — нормы нет)
+func locationLimits(ctx context.Context) map[string]time.Duration {
+	limits := make(map[string]time.Duration)
+	for loc, d := range defaultLocationLimits {
+		limits[locationLimitKey(loc)] = d
+	}
+	for _, row := range readCSV(ctx, locationLimitsFile) {
+		if m, err := strconv.Atoi(row[1]); err == nil && m >= 0 {
+			limits[locationLimitKey(row[0])] = time.Duration(m) * time.Minute
+		}
+	}
+	return limits
+}
+
+func locationLimit(ctx context.Context, loc string) (time.Duration, bool) {
+	d := locationLimits(ctx)[locationLimitKey(loc)]
+	return d, d > 0
+}
+
+// Разделяет «Поликлиника 4 ч» на локацию и норму: норма — хвост строки
+func splitLimitArgs(args string) (loc string, d time.Duration, ok bool) {
+	fields := strings.Fields(args)
+	for i := 1; i < len(fields); i++ {
+		tail := strings.Join(fields[i:], " ")
+		if strings.EqualFold(tail, "выкл") || strings.EqualFold(tail, "нет") {
+			return strings.Join(fields[:i], " "), 0, true
+		}
+		if d, ok := validate.Duration(tail); ok {
+			return strings.Join(fields[:i], " "), d, true
+		}
+	}
+	return "", 0, false
+}
+
+func formatLimit(d time.Duration) string {
+	if d <= 0 {
+		return "нет"
+	}
+	return formatDuration(d)
+}
+
+func locationLimitsText(ctx context.Context) string {
+	limits := locationLimits(ctx)
+	var b strings.Builder
+	b.WriteString("⏱ Нормы отсутствия по локациям\n\n")
+	shown := make(map[string]bool)
+	for _, loc := range leaveLocations {
+		key := locationLimitKey(loc)
+		shown[key] = true
+		fmt.Fprintf(&b, "%s — %s\n", loc, formatLimit(limits[key]))
+	}
+	for _, row := range readCSV(ctx, locationLimitsFile) {
+		if key := locationLimitKey(row[0]); !shown[key] {
+			shown[key] = true
+			fmt.Fprintf(&b, "%s — %s\n", row[0], formatLimit(limits[key]))
+		}
+	}
+	b.WriteString("\nЗадать: /limits Поликлиника 4ч\nУбрать: /limits Столовая выкл")
+	return b.String()
+}
+
+// /limits — список, /limits <локация> <норма>|выкл
+func handleLimitsCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	if strings.TrimSpace(args) == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, locationLimitsText(ctx)))
+		return
+	}
+	loc, d, ok := splitLimitArgs(args)
+	if !ok || cleanLocation(loc) == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /limits Столовая 1ч (или 90м, 1:30, выкл)"))
+		return
+	}
+	if d > 7*24*time.Hour {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Норма не больше недели"))
+		return
+	}
+	name := cleanLocation(loc)
+	for _, l := range leaveLocations {
+		if locationLimitKey(l) == locationLimitKey(name) {
+			name = cleanLocation(l)
+		}
+	}
+	updateCSV(ctx, locationLimitsFile, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if locationLimitKey(row[0]) != locationLimitKey(name) {
+				keep = append(keep, row)
+			}
+		}
+		return append(keep, []string{name, strconv.Itoa(int(d.Minutes())), strconv.Itoa(adminID)})
+	})
+	writeAudit(ctx, adminID, "location_limit", fmt.Sprintf("%s %d", name, int(d.Minutes())))
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %s: норма %s", name, formatLimit(d))))
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Отметка за пользователя (нет телефона, сел аккумулятор) ---
+
+// Админ -> пользователь, для которого вводится локация вручную
+var pendingMarkFor = make(map[int]int)
+
+func canMarkFor(ctx context.Context, userID int) bool {
+	return isRootAdmin(ctx, userID) || isAdminWithRight(ctx, userID, "manage_users")
+}
+
+func sendMarkForMenu(ctx context.Context, bot Sender, chatID int64, uid int) {
+	action, loc := getLastAction(ctx, uid)
+	status := "нет отметок"
+	if action != "" {
+		status = actionEmoji(action) + " " + action
+		if action != "Прибыл" {
+			status += " (" + cleanLocation(loc) + ")"
+		}
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📝 Отметить за: %s\nТекущий статус: %s", getUserName(ctx, uid, nil), status))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🟢 Прибыл", fmt.Sprintf("mfa_%d", uid)),
+		tgbotapi.NewInlineKeyboardButtonData("🔴 Убыл", fmt.Sprintf("mfl_%d", uid)),
+	))
+	bot.Send(msg)
+}
+
+// Меню локаций для отметки за пользователя: mfloc_<uid>_<индекс локации>
+func markForLeaveMenu(uid int) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for i := 0; i < len(leaveLocations); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(leaveLocations[i], fmt.Sprintf("mfloc_%d_%d", uid, i)),
+		}
+		if i+1 < len(leaveLocations) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(leaveLocations[i+1], fmt.Sprintf("mfloc_%d_%d", uid, i+1)))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func recordMarkFor(ctx context.Context, bot Sender, chatID int64, adminID, uid int, action, location string) {
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, uid, nil)
+	saveAttendanceByAdmin(ctx, now, strconv.Itoa(uid), name, action, location, adminID)
+	notifyAdminAboutMark(ctx, bot, uid, name+" (внёс "+getUserName(ctx, adminID, nil)+")", action, location, now)
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %s: %s %s", name, actionEmoji(action), action)))
+	// Сообщаем самому пользователю, если он уже писал боту
+	for _, u := range getSortedUsers(ctx) {
+		if u.ID == uid && u.ChatID != 0 {
+			text := fmt.Sprintf("ℹ️ Админ отметил за вас: %s %s", actionEmoji(action), action)
+			if action != "Прибыл" {
+				text += " (" + cleanLocation(location) + ")"
+			}
+			bot.Send(tgbotapi.NewMessage(u.ChatID, text))
+			break
+		}
+	}
+}
+
+func handleMarkForAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	adminID := query.From.ID
+	chatID := query.Message.Chat.ID
+	data := query.Data
+	if !canMarkFor(ctx, adminID) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нет прав"))
+		return
+	}
+	defer bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	switch {
+	case strings.HasPrefix(data, "markfor_"):
+		uid, _ := strconv.Atoi(strings.TrimPrefix(data, "markfor_"))
+		sendMarkForMenu(ctx, bot, chatID, uid)
+	case strings.HasPrefix(data, "mfa_"):
+		uid, _ := strconv.Atoi(strings.TrimPrefix(data, "mfa_"))
+		recordMarkFor(ctx, bot, chatID, adminID, uid, "Прибыл", "-")
+	case strings.HasPrefix(data, "mfl_"):
+		uid, _ := strconv.Atoi(strings.TrimPrefix(data, "mfl_"))
+		msg := tgbotapi.NewMessage(chatID, "Выберите локацию, куда убыл "+getUserName(ctx, uid, nil)+":")
+		msg.ReplyMarkup = markForLeaveMenu(uid)
+		bot.Send(msg)
+	case strings.HasPrefix(data, "mfloc_"):
+		parts := strings.Split(strings.TrimPrefix(data, "mfloc_"), "_")
+		if len(parts) != 2 {
+			return
+		}
+		uid, _ := strconv.Atoi(parts[0])
+		i, err := strconv.Atoi(parts[1])
+		if err != nil || i < 0 || i >= len(leaveLocations) {
+			return
+		}
+		if leaveLocations[i] == "📝 Другое" {
+			pendingMarkFor[adminID] = uid
+			bot.Send(tgbotapi.NewMessage(chatID, "Введите вручную, куда убыл:"))
+			return
+		}
+		recordMarkFor(ctx, bot, chatID, adminID, uid, "Убыл", leaveLocations[i])
+	}
+}
+
+func handleMarkForInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	location := strings.TrimSpace(msg.Text)
+	if len([]rune(location)) < 3 {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Введите корректную локацию (не менее 3 символов)."))
+		return
+	}
+	uid := pendingMarkFor[adminID]
+	delete(pendingMarkFor, adminID)
+	recordMarkFor(ctx, bot, msg.Chat.ID, adminID, uid, "Убыл", location)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Метрики использования /metrics ---
+//
+// Прикладные показатели в формате Prometheus: сколько отметок за день,
+// кто сейчас вне части, сколько разослано напоминаний и выгрузок, как
+// быстро обрабатываются апдейты. Счётчики живут в памяти и обнуляются при
+// перезапуске, остальное считается по CSV в момент запроса. Доступ —
+// область read (httpauth.go).
+
+const metricsDays = 7
+
+var metricHelp = map[string]string{
+	"tabel_marks_total":           "Отметок записано с момента запуска",
+	"tabel_reminders_sent_total":  "Разослано напоминаний",
+	"tabel_exports_total":         "Выгрузок в Excel",
+	"tabel_updates_total":         "Обработано апдейтов Telegram",
+	"tabel_update_seconds_sum":    "Суммарное время обработки апдейтов",
+	"tabel_update_avg_seconds":    "Среднее время обработки апдейта",
+	"tabel_update_timeouts_total": "Апдейтов, обработка которых заняла больше updateTimeout",
+	"tabel_marks_day":             "Отметок за день",
+	"tabel_users":                 "Пользователей по текущему состоянию",
+}
+
+var (
+	metricsMu      sync.Mutex
+	metricCounters = make(map[string]float64) // имя{метки} -> значение
+)
+
+func init() {
+	handleHTTP("/metrics", requireScope("read", serveMetrics))
+}
+
+// labels — готовая строка вида kind="evening" или пустая
+func incMetric(name, labels string) {
+	addMetric(name, labels, 1)
+}
+
+func addMetric(name, labels string, v float64) {
+	key := name
+	if labels != "" {
+		key += "{" + labels + "}"
+	}
+	metricsMu.Lock()
+	metricCounters[key] += v
+	metricsMu.Unlock()
+}
+
+// Через defer в начале обработки апдейта
+func observeUpdate(start time.Time) {
+	incMetric("tabel_updates_total", "")
+	addMetric("tabel_update_seconds_sum", "", time.Since(start).Seconds())
+}
+
+func metricName(key string) string {
+	if i := strings.IndexByte(key, '{'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	values := make(map[string]float64)
+	metricsMu.Lock()
+	for k, v := range metricCounters {
+		values[k] = v
+	}
+	metricsMu.Unlock()
+	if n := values["tabel_updates_total"]; n > 0 {
+		values["tabel_update_avg_seconds"] = values["tabel_update_seconds_sum"] / n
+	}
+
+	since := daysAgo(metricsDays - 1)
+	for i := 0; i < metricsDays; i++ {
+		values[fmt.Sprintf(`tabel_marks_day{day="%s"}`, daysAgo(i).Format("2006-01-02"))] = 0
+	}
+	for _, row := range journal.ReadRange(ctx, since, daysAgo(-1)) {
+		t, _ := time.ParseInLocation(dateFormat, row[0], time.Local)
+		values[fmt.Sprintf(`tabel_marks_day{day="%s"}`, t.Format("2006-01-02"))]++
+	}
+	states := map[string]float64{"in": 0, "out": 0, "status": 0, "none": 0}
+	last := lastRows(ctx)
+	for _, u := range getSortedUsers(ctx) {
+		row := last[strconv.Itoa(u.ID)]
+		switch {
+		case row == nil:
+			states["none"]++
+		case row[3] == "Прибыл":
+			states["in"]++
+		case row[3] == "Убыл":
+			states["out"]++
+		default:
+			states["status"]++
+		}
+	}
+	for state, n := range states {
+		values[fmt.Sprintf(`tabel_users{state="%s"}`, state)] = n
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	prev := ""
+	for _, k := range keys {
+		name := metricName(k)
+		if name != prev {
+			kind := "gauge"
+			if strings.HasSuffix(name, "_total") || strings.HasSuffix(name, "_sum") {
+				kind = "counter"
+			}
+			b.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", name, metricHelp[name], name, kind))
+			prev = name
+		}
+		b.WriteString(k + " " + strconv.FormatFloat(values[k], 'f', -1, 64) + "\n")
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Временное отключение уведомлений админа ---
+//
+// Кнопки под уведомлениями об отметках: «🔕 1 час» и «🔕 До утра».
+// Срок хранится в settings.csv (mute:<ID>), после него уведомления
+// приходят снова. Оповещения о невозвращении в срок не глушатся.
+
+const muteMorningHour = 7
+
+func muteKey(chatID int64) string {
+	return fmt.Sprintf("mute:%d", chatID)
+}
+
+func mutedUntil(ctx context.Context, chatID int64) time.Time {
+	t, err := time.ParseInLocation(dateFormat, getSetting(ctx, muteKey(chatID), ""), time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func isAdminMuted(ctx context.Context, chatID int64) bool {
+	return clock.Now().Before(mutedUntil(ctx, chatID))
+}
+
+func muteKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔕 1 час", "mute_1h"),
+		tgbotapi.NewInlineKeyboardButtonData("🔕 До утра", "mute_morning"),
+	))
+}
+
+// Уведомление админу с кнопками отключения, если он их не заглушил
+func sendAdminNotification(ctx context.Context, bot Sender, chatID int64, txt string) {
+	if isAdminMuted(ctx, chatID) {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, txt)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = muteKeyboard()
+	sendNonCritical(ctx, bot, msg)
+}
+
+func handleMuteAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	now := clock.Now()
+	var until time.Time
+	switch query.Data {
+	case "mute_1h":
+		until = now.Add(time.Hour)
+	case "mute_morning":
+		until = nextClock(now, muteMorningHour, 0)
+	case "mute_off":
+		setSetting(ctx, muteKey(chatID), "")
+		bot.Send(tgbotapi.NewMessage(chatID, "🔔 Уведомления включены."))
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	default:
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	setSetting(ctx, muteKey(chatID), until.Format(dateFormat))
+	writeAudit(ctx, query.From.ID, "mute", strconv.FormatInt(chatID, 10)+" до "+until.Format(dateFormat))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🔕 Уведомления об отметках отключены до %s.", until.Format("15:04 02.01")))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔔 Включить сейчас", "mute_off"),
+	))
+	bot.Send(msg)
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Уведомления отключены"))
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Контроль опозданий с возвращением ---
+//
+// Если указано ожидаемое время возвращения (или для локации есть норма,
+// см. loclimits.go), после его истечения пользователю приходит
+// напоминание, а через OVERDUE_ESCALATE_MIN минут (по умолчанию 30) —
+// сообщение админам. Проверка идёт раз в минуту задачей overdue (jobs.go).
+
+var (
+	overdueMu    sync.Mutex
+	overdueStage = make(map[string]int) // uid|время отметки -> 1 пользователь уведомлён, 2 админы
+)
+
+func overdueEscalationDelay() time.Duration {
+	if m, err := strconv.Atoi(os.Getenv("OVERDUE_ESCALATE_MIN")); err == nil && m >= 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// Срок возвращения для записи «Убыл»
+func returnDeadline(ctx context.Context, row []string) (time.Time, bool) {
+	if t, ok := expectedReturn(row); ok {
+		return t, true
+	}
+	d, ok := locationLimit(ctx, row[4])
+	if !ok {
+		return time.Time{}, false
+	}
+	left, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return left.Add(d), true
+}
+
+func checkOverdue(ctx context.Context, bot Sender, now time.Time) {
+	delay := overdueEscalationDelay()
+	active := make(map[string]bool)
+	for _, u := range getSortedUsers(ctx) {
+		row := findLastRow(ctx, strconv.Itoa(u.ID))
+		if row == nil || row[3] != "Убыл" || isBanned(ctx, u.ID) {
+			continue
+		}
+		deadline, ok := returnDeadline(ctx, row)
+		if !ok || now.Before(deadline) {
+			continue
+		}
+		key := row[1] + "|" + row[0]
+		active[key] = true
+		overdueMu.Lock()
+		stage := overdueStage[key]
+		overdueMu.Unlock()
+		late := now.Sub(deadline)
+		if stage < 1 {
+			sendNonCritical(ctx, bot, tgbotapi.NewMessage(u.ChatID, fmt.Sprintf(
+				"⏰ Ты должен был вернуться %s. Если уже в части — отметь прибытие!", formatExpectedReturn(deadline))))
+			incMetric("tabel_reminders_sent_total", `kind="overdue"`)
+			stage = 1
+		}
+		if stage < 2 && late >= delay {
+			notifyAdminsOverdue(ctx, bot, u, row, deadline, late)
+			stage = 2
+		}
+		overdueMu.Lock()
+		overdueStage[key] = stage
+		overdueMu.Unlock()
+	}
+	// Забываем вернувшихся
+	overdueMu.Lock()
+	for key := range overdueStage {
+		if !active[key] {
+			delete(overdueStage, key)
+		}
+	}
+	overdueMu.Unlock()
+}
+
+// Получатели служебных оповещений: главный админ и админы с указанным правом
+func adminRecipients(ctx context.Context, right string) []int64 {
+	chats := []int64{int64(rootAdminID(ctx))}
+	for _, a := range getAdmins(ctx) {
+		if a.ID != rootAdminID(ctx) && a.Rights[right] {
+			chats = append(chats, int64(a.ID))
+		}
+	}
+	return chats
+}
+
+func notifyAdminsOverdue(ctx context.Context, bot Sender, u User, row []string, deadline time.Time, late time.Duration) {
+	txt := fmt.Sprintf(
+		"🚨 <b>Не вернулся в срок</b>\n"+
+			"👤 <b>ФИО:</b> %s\n"+
+			"📍 <b>Локация:</b> %s\n"+
+			"🚶 <b>Убыл:</b> %s\n"+
+			"⏳ <b>Срок:</b> %s\n"+
+			"⌛ <b>Опоздание:</b> %d мин",
+		u.Name, cleanLocation(row[4]), row[0], deadline.Format(dateFormat), int(late.Minutes()))
+	if phone := userPhones(ctx)[strconv.Itoa(u.ID)]; phone != "" {
+		txt += "\n📞 <b>Телефон:</b> " + phone
+	}
+	for _, chatID := range adminRecipients(ctx, "summary") {
+		if !adminSeesUser(ctx, chatID, strconv.Itoa(u.ID)) {
+			continue
+		}
+		msg := tgbotapi.NewMessage(chatID, txt)
+		msg.ParseMode = "HTML"
+		if _, err := bot.Send(msg); err != nil {
+			log.Printf("overdue: не удалось уведомить %d: %v", chatID, err)
+		}
+	}
+	if channelEventsEnabled() {
+		postToChannel(bot, txt, "HTML")
+	}
+}
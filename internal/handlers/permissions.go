@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Кнопки и права на них ---
+//
+// Каждой кнопке сопоставлены обработчик и нужное право. Право проверяет
+// router (router.go) до вызова обработчика, поэтому обработчикам
+// не нужно повторять проверку. rightRoot — только главный админ,
+// rightAnyAdmin — любой админ, rightUnitLeader — командир подразделения.
+// Кнопки выбора локации и календаря журнала не имеют постоянного callback
+// и разбираются в handleAction после router.
+
+const (
+	rightRoot       = "root"
+	rightAnyAdmin   = "any"
+	rightUnitLeader = "leader"
+)
+
+// Маршруты команд и кнопок; заполняются в init, чтобы обработчики могли
+// ссылаться на router без цикла инициализации
+var router Router
+
+func init() {
+	router.Allowed = hasRight
+	router.AllowCallback = leaderCanView
+	registerCommands(&router)
+	registerCallbacks(&router)
+}
+
+// Порядок префиксов важен: более длинные раньше
+func registerCallbacks(r *Router) {
+	answer := func(bot Sender, query *tgbotapi.CallbackQuery, text string) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, text))
+	}
+	for _, c := range []Callback{
+		{Data: "arrived", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			answer(bot, q, markArrived(ctx, bot, q.Message.Chat.ID, q.From))
+		}},
+		{Data: "left", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			answer(bot, q, askDeparture(ctx, bot, q.Message.Chat.ID, q.From.ID))
+		}},
+		{Data: "journal", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendJournalPage(ctx, bot, q.Message.Chat.ID, strconv.Itoa(q.From.ID), "all", 0)
+			answer(bot, q, "Журнал")
+		}},
+		{Data: "admin_panel", Right: rightAnyAdmin, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendAdminPanel(bot, q.Message.Chat.ID)
+			answer(bot, q, "Открыта админ-панель")
+		}},
+		{Data: "personnel", Right: "manage_users", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendPersonnelList(ctx, bot, q.Message.Chat.ID, 0)
+		}},
+		{Data: "add_admin", Right: rightRoot, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendPersonnelForAdmin(ctx, bot, q.Message.Chat.ID, 0)
+		}},
+		{Data: "manage_admins", Right: rightRoot, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendAdminsList(ctx, bot, q.Message.Chat.ID, 0)
+		}},
+		{Data: "rights_history", Right: rightRoot, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendRightsHistory(ctx, bot, q.Message.Chat.ID)
+			answer(bot, q, "")
+		}},
+		{Data: "summary", Right: "summary", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			adminSummary(ctx, bot, q.Message.Chat.ID)
+			answer(bot, q, "Быстрая сводка")
+		}},
+		{Data: "export_today", Right: "export", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendFilteredExcel(ctx, bot, q.Message.Chat.ID, daysAgo(0), filterToday)
+		}},
+		{Data: "export_yesterday", Right: "export", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendFilteredExcel(ctx, bot, q.Message.Chat.ID, daysAgo(1), filterYesterday)
+		}},
+		{Data: "export_7days", Right: "export", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendFilteredExcel(ctx, bot, q.Message.Chat.ID, daysAgo(8), filterLastNDays(7))
+		}},
+		{Data: "export_30days", Right: "export", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendFilteredExcel(ctx, bot, q.Message.Chat.ID, daysAgo(31), filterLastNDays(30))
+		}},
+		{Data: "report", Right: "export", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			msg := tgbotapi.NewMessage(q.Message.Chat.ID, "Выберите период для экспорта:")
+			msg.ReplyMarkup = reportFilterMenu()
+			bot.Send(msg)
+			answer(bot, q, "")
+		}},
+		{Data: "danger", Right: "danger_zone", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendDangerZone(bot, q.Message.Chat.ID)
+			answer(bot, q, "")
+		}},
+		{Data: "analytics", Right: "summary", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendAnalytics(ctx, bot, q.Message.Chat.ID)
+			answer(bot, q, "Аналитика")
+		}},
+		{Data: "analytics_xlsx", Right: "summary", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendAnalyticsExcel(ctx, bot, q.Message.Chat.ID)
+		}},
+		{Data: "late_7", Right: "summary", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendLateReport(ctx, bot, q.Message.Chat.ID, 7)
+		}},
+		{Data: "late_30", Right: "summary", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			sendLateReport(ctx, bot, q.Message.Chat.ID, 30)
+		}},
+		{Data: "restore_confirm", Right: rightRoot, Run: handleRestoreAction},
+		{Data: "restore_cancel", Right: rightRoot, Run: handleRestoreAction},
+		{Data: "kbd_toggle", Run: handleKeyboardAction},
+		{Data: "noop", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			answer(bot, q, "")
+		}},
+		{Data: "cjobs", Right: "settings", Run: handleCustomJobAction},
+
+		{Prefix: "jpage_", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			if period, page, ok := parseJournalCallback(q.Data); ok {
+				sendJournalPage(ctx, bot, q.Message.Chat.ID, strconv.Itoa(q.From.ID), period, page)
+			}
+			answer(bot, q, "")
+		}},
+		{Prefix: "personnel_", Right: "manage_users", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			idx, _ := strconv.Atoi(strings.TrimPrefix(q.Data, "personnel_"))
+			sendPersonnelList(ctx, bot, q.Message.Chat.ID, idx)
+			answer(bot, q, "")
+		}},
+		{Prefix: "ujpage_", Right: "manage_users", Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			if uid, period, page, ok := parseUserJournalCallback(q.Data); ok {
+				sendUserJournalPage(ctx, bot, q.Message.Chat.ID, uid, period, page)
+			}
+			answer(bot, q, "")
+		}},
+		{Prefix: "jphoto_", Right: "manage_users", Run: handlePhotoAction},
+		{Prefix: "mphoto_", Run: handlePhotoAction},
+		{Prefix: "psearch", Right: "manage_users", Run: handlePersonnelSearchAction},
+		{Prefix: "palpha", Right: "manage_users", Run: handlePersonnelSearchAction},
+		{Prefix: "urename_", Right: "manage_users", Run: handleRenameAction},
+		{Prefix: "uremind_", Right: "manage_users", Run: handleRemindAction},
+		{Prefix: "uarch", Right: "manage_users", Run: handleUserArchiveAction},
+		{Prefix: "uunarch_", Right: "manage_users", Run: handleUserArchiveAction},
+		{Prefix: "uunit", Right: "manage_users", Run: handleUnitAction},
+		{Prefix: "uban", Right: "manage_users", Run: handleBanAction},
+		{Prefix: "usum_", Right: "summary", Run: handleUnitReportAction},
+		{Prefix: "uexp", Right: "export", Run: handleUnitReportAction},
+		{Prefix: "markfor_", Right: "manage_users", Run: handleMarkForAction},
+		{Prefix: "mfa_", Right: "manage_users", Run: handleMarkForAction},
+		{Prefix: "mfl_", Right: "manage_users", Run: handleMarkForAction},
+		{Prefix: "mfloc_", Right: "manage_users", Run: handleMarkForAction},
+		{Prefix: "erec", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "erow_", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "eact_", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "eloc_", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "etime_", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "edel", Right: "edit_records", Run: handleRecordEditAction},
+		{Prefix: "udel", Right: rightRoot, Run: handleUserDeleteAction},
+		{Prefix: "demote", Right: rightRoot, Run: handleDemoteAction},
+		{Prefix: "adminlist_", Right: rightRoot, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			idx, _ := strconv.Atoi(strings.TrimPrefix(q.Data, "adminlist_"))
+			sendAdminsList(ctx, bot, q.Message.Chat.ID, idx)
+			answer(bot, q, "")
+		}},
+		{Prefix: "makeadmin_", Right: rightRoot, Run: func(ctx context.Context, bot Sender, q *tgbotapi.CallbackQuery) {
+			idx, _ := strconv.Atoi(strings.TrimPrefix(q.Data, "makeadmin_"))
+			users := getSortedUsers(ctx)
+			if idx >= 0 && idx < len(users) {
+				sendRightsCheckboxMenu(ctx, bot, q.Message.Chat.ID, users[idx].ID, nil)
+			}
+			answer(bot, q, "")
+		}},
+		{Prefix: "right_", Right: rightRoot, Run: handleRightToggle},
+		{Prefix: "save_rights_", Right: rightRoot, Run: handleSaveRights},
+		{Prefix: "rpreset_", Right: rightRoot, Run: handleRolePresetAction},
+		{Prefix: "lead_", Right: rightUnitLeader, Run: handleLeaderAction},
+		{Prefix: "danger_", Right: "danger_zone", Run: handleDangerAction},
+		{Prefix: "trash_", Right: "edit_records", Run: handleTrashAction},
+		{Prefix: "cjob_", Right: "settings", Run: handleCustomJobAction},
+		{Prefix: "ret_", Run: handleReturnAction},
+		{Prefix: "retat_", Run: handleReturnAction},
+		{Prefix: "retin_", Run: handleReturnAction},
+		{Prefix: "undo_", Run: handleUndoMark},
+		{Prefix: "mute_", Run: handleMuteAction},
+		{Prefix: "mcomm_", Run: handleCommentAction},
+		{Prefix: "status_", Run: handleStatusAction},
+		{Prefix: "rootaccept_", Run: handleRootTransferAction},
+		{Prefix: "rootdecline_", Run: handleRootTransferAction},
+	} {
+		r.Callback(c)
+	}
+}
+
+// right_<код>_<ID>, в коде права может быть «_»
+func handleRightToggle(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	rest := strings.TrimPrefix(query.Data, "right_")
+	sep := strings.LastIndex(rest, "_")
+	if sep < 0 {
+		return
+	}
+	code := rest[:sep]
+	uid, _ := strconv.Atoi(rest[sep+1:])
+	current := editedRights(ctx, uid)
+	current[code] = !current[code]
+	sendRightsCheckboxMenu(ctx, bot, query.Message.Chat.ID, uid, current)
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handleSaveRights(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	uid, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "save_rights_"))
+	before := getAdminRights(ctx, uid)
+	current := editedRights(ctx, uid)
+	delete(pendingRights, uid)
+	userName := getUserName(ctx, uid, nil)
+	saveAdminRights(ctx, uid, userName, current)
+	recordRightsChange(ctx, query.From.ID, uid, before, current)
+	updateUserCommands(ctx, bot, uid)
+	bot.Send(tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf("✅ Права сохранены для %s", userName)))
+}
+
+func hasRight(ctx context.Context, userID int, right string) bool {
+	switch right {
+	case "":
+		return true
+	case rightRoot:
+		return isRootAdmin(ctx, userID)
+	case rightAnyAdmin:
+		return isRootAdmin(ctx, userID) || isAdminAny(ctx, userID)
+	case rightUnitLeader:
+		return leaderUnit(ctx, userID) != ""
+	}
+	return isAdminWithRight(ctx, userID, right)
+}
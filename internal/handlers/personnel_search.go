@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Поиск по личному составу ---
+//
+// Поиск по части фамилии и переход по первой букве. Кнопки ведут на
+// personnel_<idx> — индекс в getSortedUsers, как и у листалки.
+
+const personnelSearchLimit = 20
+
+var pendingPersonnelSearch = make(map[int]bool)
+
+func firstLetter(name string) string {
+	r := []rune(strings.TrimSpace(name))
+	if len(r) == 0 {
+		return ""
+	}
+	return strings.ToUpper(string(r[0]))
+}
+
+// Кнопки-карточки для найденных пользователей
+func personnelResultKeyboard(users []User, match func(User) bool) ([][]tgbotapi.InlineKeyboardButton, int) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	found := 0
+	for idx, u := range users {
+		if !match(u) {
+			continue
+		}
+		found++
+		if found <= personnelSearchLimit {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(u.Name, fmt.Sprintf("personnel_%d", idx)),
+			))
+		}
+	}
+	return rows, found
+}
+
+func sendPersonnelAlphabet(ctx context.Context, bot Sender, chatID int64) {
+	seen := make(map[string]bool)
+	var letters []string
+	for _, u := range scopedUsers(ctx, chatID) {
+		if l := firstLetter(u.Name); l != "" && !seen[l] {
+			seen[l] = true
+			letters = append(letters, l)
+		}
+	}
+	sort.Strings(letters)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, l := range letters {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l, "palpha_"+l))
+		if len(row) == 6 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск по фамилии", "psearch")))
+	msg := tgbotapi.NewMessage(chatID, "🔤 Выберите первую букву фамилии:")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+func sendPersonnelMatches(ctx context.Context, bot Sender, chatID int64, title string, match func(User) bool) {
+	rows, found := personnelResultKeyboard(scopedUsers(ctx, chatID), match)
+	if found == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Никого не найдено."))
+		return
+	}
+	text := fmt.Sprintf("%s (%d):", title, found)
+	if found > personnelSearchLimit {
+		text += fmt.Sprintf("\nПоказаны первые %d — уточните запрос.", personnelSearchLimit)
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+func handlePersonnelSearchAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	switch {
+	case query.Data == "psearch":
+		pendingPersonnelSearch[query.From.ID] = true
+		bot.Send(tgbotapi.NewMessage(chatID, "🔍 Введите фамилию или её часть:"))
+	case query.Data == "palpha":
+		sendPersonnelAlphabet(ctx, bot, chatID)
+	case strings.HasPrefix(query.Data, "palpha_"):
+		letter := strings.TrimPrefix(query.Data, "palpha_")
+		sendPersonnelMatches(ctx, bot, chatID, "🔤 На букву "+letter, func(u User) bool {
+			return firstLetter(u.Name) == letter
+		})
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handlePersonnelSearchInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	delete(pendingPersonnelSearch, msg.From.ID)
+	q := strings.ToLower(strings.TrimSpace(msg.Text))
+	if q == "" {
+		return
+	}
+	sendPersonnelMatches(ctx, bot, msg.Chat.ID, "🔍 Найдено", func(u User) bool {
+		fields := strings.Fields(strings.ToLower(u.Name))
+		return len(fields) > 0 && strings.Contains(fields[0], q)
+	})
+}
+
+// Текущий статус для карточки: в части / вне части, последняя отметка
+func personnelStatusLine(ctx context.Context, uid string) string {
+	row := findLastRow(ctx, uid)
+	if row == nil {
+		return "\n⚪️ Отметок ещё не было"
+	}
+	t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+	if err != nil {
+		return ""
+	}
+	if row[3] == "Прибыл" {
+		return fmt.Sprintf("\n🟢 В части\n⏰ Последняя отметка: %s", t.Format("02.01 15:04"))
+	}
+	line := fmt.Sprintf("\n🔴 Вне части: %s\n⏰ Убыл: %s (%s назад)", cleanLocation(row[4]), t.Format("02.01 15:04"), formatDuration(clock.Now().Sub(t)))
+	if ret, ok := expectedReturn(row); ok {
+		line += "\n↩️ Вернётся " + formatExpectedReturn(ret)
+	}
+	return line
+}
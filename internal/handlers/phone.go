@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/export"
+)
+
+// --- Телефон пользователя ---
+//
+// После ввода ФИО бот предлагает поделиться контактом (необязательно).
+// Номер хранится в 6-й колонке users.csv, виден в карточке ЛС и в
+// выгрузке личного состава (/list xlsx).
+
+const (
+	colUserPhone = 5
+	skipPhone    = "Пропустить"
+)
+
+var pendingPhoneInput = make(map[int]bool)
+
+func userPhones(ctx context.Context) map[string]string {
+	phones := make(map[string]string)
+	for _, row := range loadUserRegistry(ctx).Rows {
+		if len(row) > colUserPhone && row[colUserPhone] != "" {
+			phones[row[0]] = row[colUserPhone]
+		}
+	}
+	return phones
+}
+
+func setUserPhone(ctx context.Context, userID int, phone string) bool {
+	return setUserField(ctx, userID, colUserPhone, phone)
+}
+
+func askPhone(bot Sender, chatID int64, userID int) {
+	pendingPhoneInput[userID] = true
+	msg := tgbotapi.NewMessage(chatID, "📞 Поделитесь номером телефона — дежурный сможет позвонить, если вы задержитесь. Это необязательно.")
+	msg.ReplyMarkup = tgbotapi.NewOneTimeReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonContact("📞 Отправить номер")),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(skipPhone)),
+	)
+	bot.Send(msg)
+}
+
+func handlePhoneInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	text := "Хорошо, номер можно будет указать позже."
+	if msg.Contact != nil {
+		// Принимаем только собственный контакт
+		if msg.Contact.UserID != msg.From.ID {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Отправьте свой номер кнопкой ниже или нажмите «Пропустить»."))
+			return
+		}
+		setUserPhone(ctx, userID, msg.Contact.PhoneNumber)
+		text = "✅ Номер сохранён."
+	} else if strings.TrimSpace(msg.Text) != skipPhone {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "📞 Нажмите «Отправить номер» или «Пропустить»."))
+		return
+	}
+	delete(pendingPhoneInput, userID)
+	reply := tgbotapi.NewMessage(msg.Chat.ID, text)
+	reply.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	bot.Send(reply)
+	sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+}
+
+// Текстовый список личного состава для /list
+func getUserList(ctx context.Context) string {
+	phones := userPhones(ctx)
+	units := userUnits(ctx)
+	var b strings.Builder
+	for _, u := range getSortedUsers(ctx) {
+		id := strconv.Itoa(u.ID)
+		b.WriteString(fmt.Sprintf("— %s (%d)", capitalizeName(u.Name), u.ID))
+		if units[id] != "" {
+			b.WriteString(", " + units[id])
+		}
+		if phones[id] != "" {
+			b.WriteString(", " + phones[id])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func sendPersonnelExcel(ctx context.Context, bot Sender, chatID int64) {
+	users := scopedUsers(ctx, chatID)
+	if len(users) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет данных о личном составе."))
+		return
+	}
+	phones := userPhones(ctx)
+	units := userUnits(ctx)
+	var rows [][]interface{}
+	for _, u := range users {
+		id := strconv.Itoa(u.ID)
+		last := ""
+		if row := findLastRow(ctx, id); row != nil {
+			last = fmt.Sprintf("%s %s %s", row[0], row[3], cleanLocation(row[4]))
+		}
+		rows = append(rows, []interface{}{capitalizeName(u.Name), u.ID, phones[id], units[id], last})
+	}
+	f := export.Table("Личный состав", []string{"ФИО", "Telegram ID", "Телефон", "Подразделение", "Последняя отметка"},
+		rows, 24, 16, 16, 16, 36)
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		log.Printf("personnel export: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания Excel файла"))
+		return
+	}
+	bot.Send(tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  "Личный_состав.xlsx",
+		Bytes: buf.Bytes(),
+	}))
+}
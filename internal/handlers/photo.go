@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Фото к отметке убытия ---
+//
+// К любому убытию можно приложить фото кнопкой в подтверждении. Для
+// локаций из настройки photo_locations (/photos) фото обязательно: отметка
+// записывается только после него. Хранится file_id в 9-й колонке записи,
+// админ открывает фото из журнала пользователя.
+
+const (
+	colPhoto          = 8
+	photoLocationsKey = "photo_locations"
+)
+
+// Ожидание фото: либо новое убытие в Location, либо существующая отметка Mark
+type photoRequest struct {
+	Location string
+	Mark     string
+}
+
+var pendingPhoto = make(map[int]photoRequest)
+
+func photoLocations(ctx context.Context) []string {
+	var locs []string
+	for _, l := range strings.Split(getSetting(ctx, photoLocationsKey, ""), "|") {
+		if l != "" {
+			locs = append(locs, l)
+		}
+	}
+	return locs
+}
+
+func photoRequired(ctx context.Context, loc string) bool {
+	for _, l := range photoLocations(ctx) {
+		if l == loc {
+			return true
+		}
+	}
+	return false
+}
+
+func markPhoto(row []string) string {
+	if len(row) > colPhoto {
+		return row[colPhoto]
+	}
+	return ""
+}
+
+func setMarkPhoto(ctx context.Context, uid int, dt, fileID string) bool {
+	return setRecordField(ctx, uid, dt, colPhoto, fileID)
+}
+
+func askDeparturePhoto(bot Sender, chatID int64, userID int, loc string) {
+	pendingPhoto[userID] = photoRequest{Location: loc}
+	bot.Send(tgbotapi.NewMessage(chatID, "📷 Для «"+cleanLocation(loc)+"» нужно фото (например, направления). Пришлите его одним снимком или напишите «отмена»."))
+}
+
+func handlePhotoInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	req := pendingPhoto[userID]
+	if len(msg.Photo) == 0 {
+		if strings.EqualFold(strings.TrimSpace(msg.Text), "отмена") {
+			delete(pendingPhoto, userID)
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Отменено."))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "📷 Жду фото. Чтобы отменить — напишите «отмена»."))
+		return
+	}
+	delete(pendingPhoto, userID)
+	// Последний элемент — самый крупный размер
+	fileID := msg.Photo[len(msg.Photo)-1].FileID
+	if req.Mark != "" {
+		if setMarkPhoto(ctx, userID, req.Mark, fileID) {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Фото приложено к отметке."))
+		} else {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Отметка не найдена."))
+		}
+		return
+	}
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, userID, msg.From)
+	saveAttendanceRow(ctx, []string{now, strconv.Itoa(userID), name, "Убыл", req.Location, "", "", "", fileID})
+	notifyAdminAboutMark(ctx, bot, userID, name, "Убыл", req.Location, now)
+	bot.Send(departureConfirmation(msg.Chat.ID, "✅ Убытие отмечено, фото сохранено!", now))
+	sendMainMenu(ctx, bot, msg.Chat.ID, msg.From)
+}
+
+// mphoto_<unix> — приложить фото к своей отметке, jphoto_<ID>_<unix> — показать админу
+func handlePhotoAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, "_")
+	ts, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	dt := time.Unix(ts, 0).Format(dateFormat)
+	switch {
+	case parts[0] == "mphoto" && len(parts) == 2:
+		pendingPhoto[query.From.ID] = photoRequest{Mark: dt}
+		bot.Send(tgbotapi.NewMessage(chatID, "📷 Пришлите фото к отметке "+dt+"."))
+	case parts[0] == "jphoto" && len(parts) == 3:
+		uid, _ := strconv.Atoi(parts[1])
+		_, rows, idx := findRecord(ctx, uid, dt)
+		if idx < 0 || markPhoto(rows[idx]) == "" {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Фото не найдено"))
+			return
+		}
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(markPhoto(rows[idx])))
+		photo.Caption = fmt.Sprintf("%s — %s %s", capitalizeName(rows[idx][2]), rows[idx][3], cleanLocation(rows[idx][4]))
+		bot.Send(photo)
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+// Кнопки фото для отметок на странице журнала
+func journalPhotoRows(userID string, entries [][]string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, e := range entries {
+		if markPhoto(e) == "" {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, e[0], time.Local)
+		if err != nil {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("📷 "+t.Format("02.01 15:04"),
+			fmt.Sprintf("jphoto_%s_%d", userID, t.Unix())))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// /photos — список, /photos add <локация>, /photos del <локация>
+func handlePhotosCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	locs := photoLocations(ctx)
+	if len(fields) == 2 && (fields[0] == "add" || fields[0] == "del") {
+		loc := ""
+		for _, l := range leaveLocations {
+			if strings.EqualFold(cleanLocation(l), strings.TrimSpace(fields[1])) {
+				loc = l
+			}
+		}
+		if loc == "" {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Такой локации нет в списке."))
+			return
+		}
+		var out []string
+		for _, l := range locs {
+			if l != loc {
+				out = append(out, l)
+			}
+		}
+		if fields[0] == "add" {
+			out = append(out, loc)
+		}
+		setSetting(ctx, photoLocationsKey, strings.Join(out, "|"))
+		writeAudit(ctx, adminID, "photo_locations", strings.Join(out, "|"))
+		locs = out
+	}
+	text := "📷 Фото обязательно при убытии в:\n"
+	if len(locs) == 0 {
+		text += "никуда (фото можно приложить по желанию)\n"
+	}
+	for _, l := range locs {
+		text += "— " + l + "\n"
+	}
+	text += "\nДобавить: /photos add Госпиталь\nУбрать: /photos del Госпиталь"
+	bot.Send(tgbotapi.NewMessage(chatID, text))
+}
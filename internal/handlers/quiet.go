@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Тихие часы ---
+//
+// Интервал задаётся командой /quiet 23:00-06:00 (или QUIET_HOURS).
+// Напоминания и некритичные уведомления в это время копятся в
+// quiet_queue.csv и отправляются по окончании тихих часов — в том числе
+// после перезапуска. Очередь входит в резервную копию, чтобы переезд на
+// другой сервер не терял отложенное; давно неактуальные напоминания из
+// старой копии не уйдут — пролежавшее дольше quietQueueMaxAge не
+// отправляется.
+
+const (
+	quietQueueFile   = "quiet_queue.csv"
+	quietQueueMaxAge = 24 * time.Hour
+)
+
+func init() {
+	backupFiles = append(backupFiles, quietQueueFile)
+}
+
+func quietHoursSetting(ctx context.Context) string {
+	return getSetting(ctx, "quiet_hours", os.Getenv("QUIET_HOURS"))
+}
+
+func isQuietTime(ctx context.Context, t time.Time) bool {
+	start, end, ok := validate.QuietHours(quietHoursSetting(ctx))
+	if !ok {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	// Интервал через полночь
+	return m >= start || m < end
+}
+
+// Отправка некритичного сообщения с учётом тихих часов
+func sendNonCritical(ctx context.Context, bot Sender, msg tgbotapi.MessageConfig) {
+	if isQuietTime(ctx, clock.Now()) {
+		queueQuietMessage(ctx, msg)
+		return
+	}
+	deliver(bot, msg, "уведомление")
+}
+
+// Строка очереди: когда, чат, текст, разметка, клавиатура (JSON)
+func queueQuietMessage(ctx context.Context, msg tgbotapi.MessageConfig) {
+	markup := ""
+	if msg.ReplyMarkup != nil {
+		if data, err := json.Marshal(msg.ReplyMarkup); err == nil {
+			markup = string(data)
+		}
+	}
+	if err := appendCSV(ctx, quietQueueFile, []string{clock.Now().Format(dateFormat),
+		strconv.FormatInt(msg.ChatID, 10), msg.Text, msg.ParseMode, markup}); err != nil {
+		log.Printf("quiet: %v", err)
+	}
+}
+
+// В очередь попадают только инлайн-клавиатуры (кнопки «заглушить»)
+func quietQueueMessage(row []string) (tgbotapi.MessageConfig, bool) {
+	chatID, err := strconv.ParseInt(row[1], 10, 64)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, false
+	}
+	msg := tgbotapi.NewMessage(chatID, row[2])
+	msg.ParseMode = row[3]
+	if row[4] != "" {
+		var kb tgbotapi.InlineKeyboardMarkup
+		if json.Unmarshal([]byte(row[4]), &kb) == nil {
+			msg.ReplyMarkup = kb
+		}
+	}
+	return msg, true
+}
+
+// Забирает очередь целиком: при сбое во время рассылки остаток не
+// повторится — лучше потерять напоминание, чем прислать его дважды
+func takeQuietQueue(ctx context.Context) [][]string {
+	if len(readCSV(ctx, quietQueueFile)) == 0 {
+		return nil
+	}
+	var queued [][]string
+	updateCSV(ctx, quietQueueFile, func(rows [][]string) [][]string {
+		queued = rows
+		return nil
+	})
+	return queued
+}
+
+func quietQueueFlusher(ctx context.Context, bot Sender) {
+	for {
+		clock.Sleep(time.Minute)
+		now := clock.Now()
+		if isQuietTime(ctx, now) {
+			continue
+		}
+		queued := takeQuietQueue(ctx)
+		if len(queued) > 0 {
+			log.Printf("quiet: отправка %d отложенных сообщений", len(queued))
+		}
+		for _, row := range queued {
+			if at, err := time.ParseInLocation(dateFormat, row[0], time.Local); err == nil && now.Sub(at) > quietQueueMaxAge {
+				continue
+			}
+			msg, ok := quietQueueMessage(row)
+			if !ok {
+				continue
+			}
+			deliver(bot, msg, "отложенное уведомление")
+			clock.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func handleQuietCommand(ctx context.Context, bot Sender, chatID int64, args string) {
+	args = strings.TrimSpace(args)
+	switch {
+	case args == "":
+		current := quietHoursSetting(ctx)
+		if current == "" || current == "off" {
+			current = "выключены"
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🌙 Тихие часы: %s\nИзменить: /quiet 23:00-06:00, выключить: /quiet off", current)))
+	case args == "off":
+		setSetting(ctx, "quiet_hours", "off")
+		bot.Send(tgbotapi.NewMessage(chatID, "🌙 Тихие часы выключены."))
+	default:
+		if _, _, ok := validate.QuietHours(args); !ok {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /quiet 23:00-06:00"))
+			return
+		}
+		setSetting(ctx, "quiet_hours", strings.ReplaceAll(args, " ", ""))
+		bot.Send(tgbotapi.NewMessage(chatID, "🌙 Тихие часы: "+args))
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Ограничение частоты действий ---
+//
+// Не больше RATE_LIMIT_PER_MIN (по умолчанию 20) нажатий и сообщений от
+// одного пользователя за минуту. Лишние отбрасываются: на кнопку приходит
+// ответ «не так быстро», сообщения молча игнорируются. Отметки из Mini App
+// считаются в тот же лимит (webAppMark).
+
+const rateWindow = time.Minute
+
+var actionLimiter = &Limiter{Window: rateWindow, Limit: rateLimitPerMin}
+
+func rateLimitPerMin() int {
+	if n, err := strconv.Atoi(os.Getenv("RATE_LIMIT_PER_MIN")); err == nil && n > 0 {
+		return n
+	}
+	return 20
+}
+
+// Учитывает действие; false — лимит исчерпан
+func allowAction(userID int, now time.Time) bool {
+	return actionLimiter.Allow(userID, now)
+}
+
+// true — обновление надо отбросить
+func throttled(bot Sender, update tgbotapi.Update) bool {
+	switch {
+	case update.CallbackQuery != nil:
+		if allowAction(update.CallbackQuery.From.ID, clock.Now()) {
+			return false
+		}
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(update.CallbackQuery.ID, "🐢 Не так быстро, попробуйте через минуту"))
+		return true
+	case update.Message != nil && update.Message.From != nil && !isGroupChat(update.Message.Chat):
+		return !allowAction(update.Message.From.ID, clock.Now())
+	}
+	return false
+}
+
+// Скользящее окно: не больше Limit() действий пользователя за Window
+type Limiter struct {
+	Window time.Duration
+	Limit  func() int
+
+	mu   sync.Mutex
+	hits map[int][]time.Time
+}
+
+// Учитывает действие; false — лимит исчерпан
+func (l *Limiter) Allow(userID int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.hits == nil {
+		l.hits = make(map[int][]time.Time)
+	}
+	hits := l.hits[userID]
+	fresh := hits[:0]
+	for _, t := range hits {
+		if now.Sub(t) < l.Window {
+			fresh = append(fresh, t)
+		}
+	}
+	if len(fresh) >= l.Limit() {
+		l.hits[userID] = fresh
+		return false
+	}
+	l.hits[userID] = append(fresh, now)
+	return true
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Правка и удаление записей журнала админом (право edit_records) ---
+
+type recordEdit struct {
+	UID   int
+	DT    string
+	Field string // date — поиск по дате, location, time — ввод нового значения
+}
+
+var pendingRecordEdit = make(map[int]recordEdit)
+
+func canEditRecords(ctx context.Context, userID int) bool {
+	return isRootAdmin(ctx, userID) || isAdminWithRight(ctx, userID, "edit_records")
+}
+
+// Ищет запись в рабочем файле и архивах
+func findRecord(ctx context.Context, uid int, dt string) (file string, rows [][]string, idx int) {
+	idStr := strconv.Itoa(uid)
+	for _, f := range append([]string{dataFile}, reverseStrings(journal.Archives())...) {
+		rows := readCSV(ctx, f)
+		for i := len(rows) - 1; i >= 0; i-- {
+			if len(rows[i]) >= 5 && rows[i][1] == idStr && rows[i][0] == dt {
+				return f, rows, i
+			}
+		}
+	}
+	return "", nil, -1
+}
+
+// Меняет запись uid/dt под блокировкой её файла: apply получает копию
+// строки и возвращает новую или nil — удалить запись. ok=false — записи нет.
+func modifyRecord(ctx context.Context, uid int, dt string, apply func(row []string) []string) (file string, before, after []string, ok bool) {
+	file, _, idx := findRecord(ctx, uid, dt)
+	if idx < 0 {
+		return "", nil, nil, false
+	}
+	idStr := strconv.Itoa(uid)
+	updateCSV(ctx, file, func(rows [][]string) [][]string {
+		for i := len(rows) - 1; i >= 0; i-- {
+			if len(rows[i]) < 5 || rows[i][1] != idStr || rows[i][0] != dt {
+				continue
+			}
+			before, ok = rows[i], true
+			after = apply(append([]string(nil), rows[i]...))
+			if after == nil {
+				return append(rows[:i], rows[i+1:]...)
+			}
+			rows[i] = after
+			sortRowsByTime(rows)
+			return rows
+		}
+		return rows
+	})
+	return file, before, after, ok
+}
+
+// Ставит значение в колонку записи, дополняя короткую строку
+func setRecordField(ctx context.Context, uid int, dt string, col int, value string) bool {
+	_, _, _, ok := modifyRecord(ctx, uid, dt, func(row []string) []string {
+		for len(row) <= col {
+			row = append(row, "")
+		}
+		row[col] = value
+		return row
+	})
+	return ok
+}
+
+func recordCallback(prefix string, uid int, dt string) string {
+	t, _ := time.ParseInLocation(dateFormat, dt, time.Local)
+	return fmt.Sprintf("%s_%d_%d", prefix, uid, t.Unix())
+}
+
+// Разбор callback вида <prefix>_<uid>_<unix>
+func parseRecordCallback(data string) (uid int, dt string, ok bool) {
+	parts := strings.Split(data, "_")
+	if len(parts) != 3 {
+		return 0, "", false
+	}
+	uid, err1 := strconv.Atoi(parts[1])
+	ts, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, "", false
+	}
+	return uid, time.Unix(ts, 0).Format(dateFormat), true
+}
+
+// Чьи записи затрагивает callback правки
+func recordCallbackUser(data string) (int, bool) {
+	for _, prefix := range []string{"erecent_", "erec_"} {
+		if strings.HasPrefix(data, prefix) {
+			uid, err := strconv.Atoi(strings.TrimPrefix(data, prefix))
+			return uid, err == nil
+		}
+	}
+	uid, _, ok := parseRecordCallback(data)
+	return uid, ok
+}
+
+func sendRecordSearchPrompt(ctx context.Context, bot Sender, chatID int64, adminID, uid int) {
+	pendingRecordEdit[adminID] = recordEdit{UID: uid, Field: "date"}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✏️ Записи: %s\nВведите дату в формате ДД.ММ.ГГГГ или откройте последние записи.", getUserName(ctx, uid, nil)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🕘 Последние 10", fmt.Sprintf("erecent_%d", uid)),
+	))
+	bot.Send(msg)
+}
+
+// Список записей кнопками: одна кнопка — одна запись
+func sendRecordChoice(bot Sender, chatID int64, uid int, records [][]string, title string) {
+	if len(records) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Записей не найдено."))
+		return
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range records {
+		label := fmt.Sprintf("%s %s %s", r[0], actionEmoji(r[3]), r[3])
+		if r[3] != "Прибыл" {
+			label += " " + cleanLocation(r[4])
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, recordCallback("erow", uid, r[0])),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, title)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+func sendRecordCard(ctx context.Context, bot Sender, chatID int64, uid int, dt string) {
+	_, rows, idx := findRecord(ctx, uid, dt)
+	if idx < 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Запись не найдена (возможно, уже изменена)."))
+		return
+	}
+	r := rows[idx]
+	text := fmt.Sprintf("📝 Запись\n👤 %s\n⏰ %s\n⚡ %s %s\n📍 %s", r[2], r[0], actionEmoji(r[3]), r[3], cleanLocation(r[4]))
+	toggle := "🔴 Сделать «Убыл»"
+	if r[3] != "Прибыл" {
+		toggle = "🟢 Сделать «Прибыл»"
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(toggle, recordCallback("eact", uid, dt)),
+			tgbotapi.NewInlineKeyboardButtonData("📍 Локация", recordCallback("eloc", uid, dt)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Время", recordCallback("etime", uid, dt)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", recordCallback("edel", uid, dt)),
+		),
+	)
+	bot.Send(msg)
+}
+
+// Применяет изменение к записи и пишет его в аудит
+func updateRecord(ctx context.Context, adminID, uid int, dt string, apply func(row []string) []string) ([]string, bool) {
+	file, before, updated, ok := modifyRecord(ctx, uid, dt, apply)
+	if !ok {
+		return nil, false
+	}
+	if updated == nil {
+		moveToTrash(ctx, newTrashID(), adminID, file, before)
+		writeAudit(ctx, adminID, "delete_record", strings.Join(before, " | "))
+	} else {
+		writeAudit(ctx, adminID, "edit_record", strings.Join(before, " | ")+" → "+strings.Join(updated, " | "))
+	}
+	refreshStatusBoard()
+	return updated, true
+}
+
+func sortRowsByTime(rows [][]string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, err1 := time.ParseInLocation(dateFormat, rows[i][0], time.Local)
+		tj, err2 := time.ParseInLocation(dateFormat, rows[j][0], time.Local)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return ti.Before(tj)
+	})
+}
+
+func handleRecordEditAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	adminID := query.From.ID
+	chatID := query.Message.Chat.ID
+	data := query.Data
+	if !canEditRecords(ctx, adminID) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нет права на правку записей"))
+		return
+	}
+	if uid, ok := recordCallbackUser(data); !ok || !adminSeesUser(ctx, int64(adminID), strconv.Itoa(uid)) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Человек не из вашего подразделения"))
+		return
+	}
+	defer bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	switch {
+	case strings.HasPrefix(data, "erecent_"):
+		uid, _ := strconv.Atoi(strings.TrimPrefix(data, "erecent_"))
+		delete(pendingRecordEdit, adminID)
+		history := getUserHistory(ctx, strconv.Itoa(uid), time.Time{})
+		if len(history) > 10 {
+			history = history[:10]
+		}
+		sendRecordChoice(bot, chatID, uid, history, "Выберите запись:")
+		return
+	case strings.HasPrefix(data, "erec_"):
+		uid, _ := strconv.Atoi(strings.TrimPrefix(data, "erec_"))
+		sendRecordSearchPrompt(ctx, bot, chatID, adminID, uid)
+		return
+	}
+	uid, dt, ok := parseRecordCallback(data)
+	if !ok {
+		return
+	}
+	switch {
+	case strings.HasPrefix(data, "erow_"):
+		sendRecordCard(ctx, bot, chatID, uid, dt)
+	case strings.HasPrefix(data, "eact_"):
+		updated, ok := updateRecord(ctx, adminID, uid, dt, func(row []string) []string {
+			if row[3] == "Прибыл" {
+				row[3] = "Убыл"
+			} else {
+				row[3] = "Прибыл"
+				row[4] = "-"
+			}
+			return row
+		})
+		if ok {
+			bot.Send(tgbotapi.NewMessage(chatID, "✅ Действие изменено на «"+updated[3]+"»"))
+		}
+		sendRecordCard(ctx, bot, chatID, uid, dt)
+	case strings.HasPrefix(data, "eloc_"):
+		pendingRecordEdit[adminID] = recordEdit{UID: uid, DT: dt, Field: "location"}
+		bot.Send(tgbotapi.NewMessage(chatID, "Введите новую локацию:"))
+	case strings.HasPrefix(data, "etime_"):
+		pendingRecordEdit[adminID] = recordEdit{UID: uid, DT: dt, Field: "time"}
+		bot.Send(tgbotapi.NewMessage(chatID, "Введите новое время (ЧЧ:ММ или ДД.ММ.ГГГГ ЧЧ:ММ):"))
+	case strings.HasPrefix(data, "edelok_"):
+		if _, ok := updateRecord(ctx, adminID, uid, dt, func([]string) []string { return nil }); ok {
+			bot.Send(tgbotapi.NewMessage(chatID, "🗑 Запись перенесена в корзину (/trash)."))
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, "Запись не найдена."))
+		}
+	case strings.HasPrefix(data, "edel_"):
+		msg := tgbotapi.NewMessage(chatID, "Удалить запись "+dt+"?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Да, удалить", recordCallback("edelok", uid, dt)),
+			tgbotapi.NewInlineKeyboardButtonData("Отмена", recordCallback("erow", uid, dt)),
+		))
+		bot.Send(msg)
+	}
+}
+
+// Текстовый ввод в режиме правки записей
+func handleRecordEditInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	edit := pendingRecordEdit[adminID]
+	text := strings.TrimSpace(msg.Text)
+	switch edit.Field {
+	case "date":
+		day, err := time.ParseInLocation("02.01.2006", text, time.Local)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Дата в формате ДД.ММ.ГГГГ, например 01.03.2025"))
+			return
+		}
+		delete(pendingRecordEdit, adminID)
+		var records [][]string
+		for _, row := range journal.ReadRange(ctx, day, day.AddDate(0, 0, 1)) {
+			if len(row) >= 5 && row[1] == strconv.Itoa(edit.UID) {
+				records = append(records, row)
+			}
+		}
+		sendRecordChoice(bot, msg.Chat.ID, edit.UID, records, "Записи за "+text+":")
+	case "location":
+		if len([]rune(text)) < 3 {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Введите корректную локацию (не менее 3 символов)."))
+			return
+		}
+		delete(pendingRecordEdit, adminID)
+		updateRecord(ctx, adminID, edit.UID, edit.DT, func(row []string) []string {
+			row[4] = text
+			return row
+		})
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Локация изменена."))
+		sendRecordCard(ctx, bot, msg.Chat.ID, edit.UID, edit.DT)
+	case "time":
+		old, _ := time.ParseInLocation(dateFormat, edit.DT, time.Local)
+		var t time.Time
+		var err error
+		if strings.Contains(text, ".") {
+			t, err = time.ParseInLocation("02.01.2006 15:04", text, time.Local)
+		} else {
+			t, err = time.ParseInLocation("15:04", text, time.Local)
+			t = time.Date(old.Year(), old.Month(), old.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		}
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Формат: ЧЧ:ММ или ДД.ММ.ГГГГ ЧЧ:ММ"))
+			return
+		}
+		delete(pendingRecordEdit, adminID)
+		newDT := t.Format(dateFormat)
+		updateRecord(ctx, adminID, edit.UID, edit.DT, func(row []string) []string {
+			row[0] = newDT
+			return row
+		})
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "✅ Время изменено на "+newDT))
+		sendRecordCard(ctx, bot, msg.Chat.ID, edit.UID, newDT)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// --- Кэш users.csv ---
+//
+// Реестр пользователей держится в памяти с индексами по ID и по ФИО, так
+// что проверки на каждое нажатие кнопки не читают файл. writeCSV сбрасывает
+// кэш; размер и время изменения файла страхуют от правок в обход бота.
+// Строки реестра общие — изменять их нельзя, для записи файл читается заново.
+
+type userRegistry struct {
+	Size    int64
+	ModTime time.Time
+	Rows    [][]string
+	ByID    map[string][]string
+	ByName  map[string]string // ФИО -> ID первого с таким ФИО
+}
+
+var (
+	registryMu sync.Mutex
+	registry   *userRegistry
+)
+
+func invalidateUserRegistry() {
+	registryMu.Lock()
+	registry = nil
+	registryMu.Unlock()
+}
+
+func loadUserRegistry(ctx context.Context) *userRegistry {
+	info, err := os.Stat(usersFile)
+	var size int64
+	var mod time.Time
+	if err == nil {
+		size, mod = info.Size(), info.ModTime()
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if registry != nil && registry.Size == size && registry.ModTime.Equal(mod) {
+		return registry
+	}
+	reg := &userRegistry{Size: size, ModTime: mod, ByID: make(map[string][]string), ByName: make(map[string]string)}
+	if err == nil {
+		reg.Rows = readCSV(ctx, usersFile)
+	}
+	for _, row := range reg.Rows {
+		if len(row) == 0 {
+			continue
+		}
+		if _, ok := reg.ByID[row[0]]; !ok {
+			reg.ByID[row[0]] = row
+		}
+		if len(row) > 1 {
+			if _, ok := reg.ByName[row[1]]; !ok {
+				reg.ByName[row[1]] = row[0]
+			}
+		}
+	}
+	registry = reg
+	return reg
+}
+
+// Колонка пользователя или ""
+func (r *userRegistry) field(userID string, col int) string {
+	if row, ok := r.ByID[userID]; ok && len(row) > col {
+		return row[col]
+	}
+	return ""
+}
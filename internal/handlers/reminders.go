@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Вечерние напоминания по личному времени ---
+//
+// Время напоминания у каждого своё: remind:<ID> в settings.csv, «ЧЧ:ММ»
+// или «off»; без записи — общее 18:30. Задача reminders (jobs.go)
+// срабатывает каждую минуту и напоминает тем, чьё время наступило с
+// прошлого запуска. После простоя догоняются напоминания за последний час:
+// момент последней рассылки хранится в remind_done.
+//
+// Кто так и не отметил прибытие, получает второе напоминание через
+// REMIND_ESCALATE_MIN минут (по умолчанию 30), третье, уже жёсткое, —
+// ещё через столько же, и тогда же о нём узнаёт дежурный по графику
+// (если графика нет — админы с правом уведомлений). Этап цепочки хранится
+// в settings.csv, так что перезапуск бота её не обрывает.
+
+const (
+	remindOff     = "off"
+	remindCatchUp = time.Hour
+	// До какого момента напоминания разосланы; пишется только когда
+	// кому-то было пора, а не каждую минуту
+	remindDoneKey = "remind_done"
+)
+
+// Прошлый запуск задачи reminders в этом процессе
+var (
+	remindTickMu   sync.Mutex
+	remindLastTick time.Time
+)
+
+var pendingRemindFor = make(map[int]int) // ID админа -> ID пользователя
+
+// Цепочка повторных напоминаний хранится в settings.csv, чтобы пережить
+// перезапуск: remindchain:<ID>|<время отметки «Убыл»> = <unix первого
+// напоминания>|<сколько отправлено>
+const remindChainPrefix = "remindchain:"
+
+type remindChain struct {
+	UserID int
+	Mark   string    // время отметки «Убыл»
+	First  time.Time // первое напоминание
+	Stage  int       // сколько напоминаний отправлено
+}
+
+var remindChainMu sync.Mutex
+
+func (c remindChain) key() string {
+	return fmt.Sprintf("%s%d|%s", remindChainPrefix, c.UserID, c.Mark)
+}
+
+func saveRemindChain(ctx context.Context, c remindChain) {
+	setSetting(ctx, c.key(), fmt.Sprintf("%d|%d", c.First.Unix(), c.Stage))
+}
+
+func dropRemindChain(ctx context.Context, c remindChain) {
+	setSetting(ctx, c.key(), "")
+}
+
+func loadRemindChains(ctx context.Context) []remindChain {
+	var chains []remindChain
+	for _, row := range readCSV(ctx, settingsFile) {
+		if len(row) < 2 || !strings.HasPrefix(row[0], remindChainPrefix) {
+			continue
+		}
+		uid, mark, ok := strings.Cut(strings.TrimPrefix(row[0], remindChainPrefix), "|")
+		first, stage, ok2 := strings.Cut(row[1], "|")
+		id, err := strconv.Atoi(uid)
+		sec, err2 := strconv.ParseInt(first, 10, 64)
+		n, err3 := strconv.Atoi(stage)
+		if !ok || !ok2 || err != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		chains = append(chains, remindChain{UserID: id, Mark: mark, First: time.Unix(sec, 0), Stage: n})
+	}
+	return chains
+}
+
+var remindFollowUps = []string{
+	"⚠️ Ты всё ещё числишься убывшим. Если уже вернулся — отметь прибытие прямо сейчас.",
+	"🚨 Прибытие так и не отмечено. Дежурный уведомлён — отметься или свяжись с ним.",
+}
+
+func remindEscalationStep() time.Duration {
+	if m, err := strconv.Atoi(os.Getenv("REMIND_ESCALATE_MIN")); err == nil && m > 0 {
+		return time.Duration(m) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+func remindKey(userID int) string {
+	return fmt.Sprintf("remind:%d", userID)
+}
+
+func defaultRemindTime() string {
+	return fmt.Sprintf("%02d:%02d", reminderHour, reminderMinute)
+}
+
+func remindTimeOf(ctx context.Context, userID int) string {
+	return getSetting(ctx, remindKey(userID), defaultRemindTime())
+}
+
+// Личные времена напоминаний: ID -> «ЧЧ:ММ» или off
+func remindTimes(ctx context.Context) map[int]string {
+	times := make(map[int]string)
+	for _, row := range readCSV(ctx, settingsFile) {
+		if len(row) < 2 || !strings.HasPrefix(row[0], "remind:") {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimPrefix(row[0], "remind:")); err == nil {
+			times[id] = row[1]
+		}
+	}
+	return times
+}
+
+// «21:00», «9:05», «off»/«выкл», «default»/«сброс»; пустая строка — общее время
+func parseRemindTime(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off", "выкл", "нет":
+		return remindOff, nil
+	case "default", "сброс", "по умолчанию":
+		return "", nil
+	}
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return "", fmt.Errorf("время в формате ЧЧ:ММ, «выкл» или «сброс»")
+	}
+	return t.Format("15:04"), nil
+}
+
+func remindTimeText(hm string) string {
+	if hm == remindOff {
+		return "выключены"
+	}
+	return "в " + hm
+}
+
+// Момент ЧЧ:ММ, попавший в (from, to]: сегодня или вчера, если интервал
+// перешёл через полночь
+func remindMoment(hm string, from, to time.Time) (time.Time, bool) {
+	t, err := time.Parse("15:04", hm)
+	if err != nil {
+		return time.Time{}, false
+	}
+	for d := 0; d <= 1; d++ {
+		day := to.AddDate(0, 0, -d)
+		at := time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, to.Location())
+		if at.After(from) && !at.After(to) {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Запуск задачи reminders: интервал от прошлого запуска, а после старта —
+// от сохранённой отметки, но не больше чем за remindCatchUp
+func runReminders(ctx context.Context, bot Sender, now time.Time) {
+	remindTickMu.Lock()
+	defer remindTickMu.Unlock()
+	from := remindLastTick
+	if from.IsZero() {
+		from = now.Add(-time.Minute)
+		if sec, err := strconv.ParseInt(getSetting(ctx, remindDoneKey, ""), 10, 64); err == nil {
+			from = time.Unix(sec, 0)
+		}
+	}
+	if now.Sub(from) > remindCatchUp {
+		from = now.Add(-remindCatchUp)
+	}
+	remindLastTick = now
+	if sendDueReminders(ctx, bot, from, now) > 0 {
+		setSetting(ctx, remindDoneKey, strconv.FormatInt(now.Unix(), 10))
+	}
+	advanceRemindChains(ctx, bot, now)
+}
+
+// Напоминания всем, чьё время попало в (from, to], по минутам;
+// возвращает, скольким было пора
+func sendDueReminders(ctx context.Context, bot Sender, from, to time.Time) int {
+	times := remindTimes(ctx)
+	byMinute := make(map[time.Time][]User)
+	for _, u := range getSortedUsers(ctx) {
+		hm, ok := times[u.ID]
+		if !ok {
+			hm = defaultRemindTime()
+		}
+		if hm == remindOff {
+			continue
+		}
+		at, ok := remindMoment(hm, from, to)
+		if !ok || !isDutyDay(ctx, at) {
+			continue
+		}
+		byMinute[at] = append(byMinute[at], u)
+	}
+	due := 0
+	minutes := make([]time.Time, 0, len(byMinute))
+	for at := range byMinute {
+		minutes = append(minutes, at)
+	}
+	sort.Slice(minutes, func(i, j int) bool { return minutes[i].Before(minutes[j]) })
+	for _, at := range minutes {
+		sent := 0
+		for _, u := range byMinute[at] {
+			if remindUser(ctx, bot, u) {
+				sent++
+			}
+		}
+		log.Printf("reminders: %s — %d из %d", at.Format("15:04"), sent, len(byMinute[at]))
+		due += len(byMinute[at])
+	}
+	return due
+}
+
+// Напоминания всем сразу, без учёта личного времени (своя задача «напоминание»)
+func sendReminders(ctx context.Context, bot Sender) {
+	times := remindTimes(ctx)
+	for _, u := range getSortedUsers(ctx) {
+		if times[u.ID] != remindOff {
+			remindUser(ctx, bot, u)
+		}
+	}
+}
+
+// Напоминает, если человек убыл или в статусе с напоминанием;
+// убывшему запускается цепочка повторных напоминаний
+func remindUser(ctx context.Context, bot Sender, u User) bool {
+	if isBanned(ctx, u.ID) {
+		return false
+	}
+	row := findLastRow(ctx, strconv.Itoa(u.ID))
+	if row == nil {
+		return false
+	}
+	st, isStatus := findStatus(row[3])
+	if row[3] != "Убыл" && !(isStatus && st.Remind) {
+		return false
+	}
+	txt := reminderTexts[randText.Intn(len(reminderTexts))]
+	sendNonCritical(ctx, bot, tgbotapi.NewMessage(u.ChatID, txt))
+	incMetric("tabel_reminders_sent_total", `kind="evening"`)
+	if row[3] == "Убыл" {
+		c := remindChain{UserID: u.ID, Mark: row[0], First: clock.Now(), Stage: 1}
+		remindChainMu.Lock()
+		if getSetting(ctx, c.key(), "") == "" {
+			saveRemindChain(ctx, c)
+		}
+		remindChainMu.Unlock()
+	}
+	return true
+}
+
+// Повторные напоминания тем, кто после первого так и не вернулся
+func advanceRemindChains(ctx context.Context, bot Sender, now time.Time) {
+	step := remindEscalationStep()
+	remindChainMu.Lock()
+	defer remindChainMu.Unlock()
+	reg := loadUserRegistry(ctx)
+	for _, c := range loadRemindChains(ctx) {
+		if now.Sub(c.First) < time.Duration(c.Stage)*step {
+			continue
+		}
+		uid := strconv.Itoa(c.UserID)
+		row := findLastRow(ctx, uid)
+		chatID, err := strconv.ParseInt(reg.field(uid, 2), 10, 64)
+		if err != nil || row == nil || row[0] != c.Mark || row[3] != "Убыл" || isBanned(ctx, c.UserID) ||
+			c.Stage > len(remindFollowUps) {
+			// Вернулся, отметился заново или удалён — цепочка закончена
+			dropRemindChain(ctx, c)
+			continue
+		}
+		u := User{ID: c.UserID, Name: reg.field(uid, 1), ChatID: chatID}
+		sendNonCritical(ctx, bot, tgbotapi.NewMessage(u.ChatID, remindFollowUps[c.Stage-1]))
+		incMetric("tabel_reminders_sent_total", `kind="followup"`)
+		if c.Stage >= len(remindFollowUps) {
+			dropRemindChain(ctx, c)
+			notifyDutyNotReturned(ctx, bot, u, row, now)
+			continue
+		}
+		c.Stage++
+		saveRemindChain(ctx, c)
+	}
+}
+
+// Дежурный по графику, а без графика — админы с правом уведомлений
+func notifyDutyNotReturned(ctx context.Context, bot Sender, u User, row []string, now time.Time) {
+	txt := fmt.Sprintf(
+		"🚨 <b>Не отмечает прибытие</b>\n"+
+			"👤 <b>ФИО:</b> %s\n"+
+			"📍 <b>Локация:</b> %s\n"+
+			"🚶 <b>Убыл:</b> %s\n"+
+			"🔕 Три напоминания без ответа",
+		capitalizeName(u.Name), cleanLocation(row[4]), row[0])
+	if left, err := time.ParseInLocation(dateFormat, row[0], time.Local); err == nil {
+		txt += fmt.Sprintf("\n⌛ <b>Отсутствует:</b> %s", formatDuration(now.Sub(left)))
+	}
+	if phone := userPhones(ctx)[strconv.Itoa(u.ID)]; phone != "" {
+		txt += "\n📞 <b>Телефон:</b> " + phone
+	}
+	var chats []int64
+	if current, _ := dutyShiftsAround(ctx, now); current != nil && current.UserID != u.ID {
+		chats = []int64{int64(current.UserID)}
+	} else {
+		for _, chatID := range adminRecipients(ctx, "notifications") {
+			if adminSeesUser(ctx, chatID, strconv.Itoa(u.ID)) {
+				chats = append(chats, chatID)
+			}
+		}
+	}
+	for _, chatID := range chats {
+		msg := tgbotapi.NewMessage(chatID, txt)
+		msg.ParseMode = "HTML"
+		if _, err := bot.Send(msg); err != nil {
+			log.Printf("reminders: не удалось уведомить %d: %v", chatID, err)
+		}
+	}
+}
+
+// /remind — своё время напоминания: /remind 21:00, /remind выкл, /remind сброс
+func handleRemindCommand(ctx context.Context, bot Sender, chatID int64, userID int, args string) {
+	if strings.TrimSpace(args) == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "⏰ Напоминания "+remindTimeText(remindTimeOf(ctx, userID))+
+			".\n\nИзменить: /remind 21:00\nВыключить: /remind выкл\nОбщее время ("+defaultRemindTime()+"): /remind сброс"))
+		return
+	}
+	hm, err := parseRemindTime(args)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ "+err.Error()))
+		return
+	}
+	setSetting(ctx, remindKey(userID), hm)
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ Напоминания "+remindTimeText(remindTimeOf(ctx, userID))+"."))
+}
+
+// Кнопка «⏰ Напоминание» в карточке человека
+func handleRemindAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	uid, err := strconv.Atoi(strings.TrimPrefix(query.Data, "uremind_"))
+	if err != nil || !isUserRegistered(ctx, uid) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Пользователь не найден"))
+		return
+	}
+	pendingRemindFor[query.From.ID] = uid
+	bot.Send(tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"⏰ %s: напоминания %s.\nВведите время ЧЧ:ММ, «выкл» или «сброс» (или «отмена»):",
+		capitalizeName(getUserName(ctx, uid, nil)), remindTimeText(remindTimeOf(ctx, uid)))))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handleRemindInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	uid := pendingRemindFor[adminID]
+	text := strings.TrimSpace(msg.Text)
+	if strings.EqualFold(text, "отмена") {
+		delete(pendingRemindFor, adminID)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Отменено."))
+		return
+	}
+	hm, err := parseRemindTime(text)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ "+err.Error()))
+		return
+	}
+	delete(pendingRemindFor, adminID)
+	setSetting(ctx, remindKey(uid), hm)
+	writeAudit(ctx, adminID, "remind_time", fmt.Sprintf("%d %s", uid, hm))
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("✅ %s: напоминания %s.",
+		capitalizeName(getUserName(ctx, uid, nil)), remindTimeText(remindTimeOf(ctx, uid)))))
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/validate"
+)
+
+// --- Исправление ФИО админом ---
+//
+// Новое ФИО записывается в users.csv, admins.csv и во все отметки
+// пользователя (включая архивы), чтобы выгрузки не расходились.
+
+var pendingRename = make(map[int]int) // ID админа -> ID пользователя
+
+func renameUser(ctx context.Context, userID int, name string) (old string, ok bool) {
+	idStr := strconv.Itoa(userID)
+	updateCSV(ctx, usersFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) > 1 && row[0] == idStr {
+				old = row[1]
+				rows[i][1] = name
+				ok = true
+			}
+		}
+		return rows
+	})
+	if !ok {
+		return "", false
+	}
+
+	updateCSV(ctx, adminsFile, func(rows [][]string) [][]string {
+		for i, row := range rows {
+			if len(row) > 1 && row[0] == idStr {
+				rows[i][1] = name
+				break
+			}
+		}
+		return rows
+	})
+
+	for _, file := range append(journal.Archives(), dataFile) {
+		updateCSV(ctx, file, func(rows [][]string) [][]string {
+			for i, row := range rows {
+				if len(row) > 2 && row[1] == idStr {
+					rows[i][2] = name
+				}
+			}
+			return rows
+		})
+	}
+	refreshStatusBoard()
+	return old, true
+}
+
+func handleRenameAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	adminID := query.From.ID
+	if !isAdminWithRight(ctx, adminID, "manage_users") {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нет прав"))
+		return
+	}
+	uid, err := strconv.Atoi(strings.TrimPrefix(query.Data, "urename_"))
+	if err != nil || !isUserRegistered(ctx, uid) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Пользователь не найден"))
+		return
+	}
+	pendingRename[adminID] = uid
+	bot.Send(tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"✍️ Текущее ФИО: %s\nВведите новое в формате Фамилия И.О. (или «отмена»):", capitalizeName(getUserName(ctx, uid, nil)))))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+func handleRenameInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	uid := pendingRename[adminID]
+	text := strings.TrimSpace(msg.Text)
+	if strings.EqualFold(text, "отмена") {
+		delete(pendingRename, adminID)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Переименование отменено."))
+		return
+	}
+	name, ok := validate.Name(text)
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Формат неверный. Введите ФИО так: Иванов И.И."))
+		return
+	}
+	delete(pendingRename, adminID)
+	old, ok := renameUser(ctx, uid, name)
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Пользователь не найден"))
+		return
+	}
+	writeAudit(ctx, adminID, "rename_user", fmt.Sprintf("%d: %s → %s", uid, old, name))
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("✅ ФИО изменено: %s → %s", capitalizeName(old), name)))
+	for _, u := range getAllUsers(ctx) {
+		if u.ID == uid && u.ChatID != 0 && u.ChatID != msg.Chat.ID {
+			bot.Send(tgbotapi.NewMessage(u.ChatID, "✏️ Администратор исправил ваше ФИО: "+name))
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Ожидаемое время возвращения ---
+//
+// Хранится в 7-й колонке записи «Убыл» в формате dateFormat.
+
+const (
+	colSource         = 5
+	colExpectedReturn = 6
+)
+
+// Пользователь -> время отметки, для которой вводится время возвращения
+var pendingReturnInput = make(map[int]string)
+
+// Подтверждение убытия: кнопка отмены и быстрый выбор времени возвращения
+func departureConfirmation(chatID int64, text, dt string) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, text+"\n\n⏳ Когда планируете вернуться?")
+	t, err := time.ParseInLocation(dateFormat, dt, time.Local)
+	if err != nil {
+		return msg
+	}
+	ts := t.Unix()
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("1 ч", fmt.Sprintf("ret_%d_60", ts)),
+			tgbotapi.NewInlineKeyboardButtonData("2 ч", fmt.Sprintf("ret_%d_120", ts)),
+			tgbotapi.NewInlineKeyboardButtonData("4 ч", fmt.Sprintf("ret_%d_240", ts)),
+			tgbotapi.NewInlineKeyboardButtonData("к 18:00", fmt.Sprintf("retat_%d_1800", ts)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⌨️ Ввести", fmt.Sprintf("retin_%d", ts)),
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Отменить", fmt.Sprintf("undo_%d", ts)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📷 Фото", fmt.Sprintf("mphoto_%d", ts)),
+			commentButton(ts),
+		),
+	)
+	return msg
+}
+
+func expectedReturn(row []string) (time.Time, bool) {
+	if len(row) <= colExpectedReturn || row[colExpectedReturn] == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(dateFormat, row[colExpectedReturn], time.Local)
+	return t, err == nil
+}
+
+// «15:30» сегодня или «15:30 02.03» для другого дня
+func formatExpectedReturn(t time.Time) string {
+	now := clock.Now()
+	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+		return "к " + t.Format("15:04")
+	}
+	return "к " + t.Format("15:04 02.01")
+}
+
+func setExpectedReturn(ctx context.Context, uid int, dt string, ret time.Time) bool {
+	if !setRecordField(ctx, uid, dt, colExpectedReturn, ret.Format(dateFormat)) {
+		return false
+	}
+	refreshStatusBoard()
+	return true
+}
+
+// Ближайшее время HH:MM не раньше from
+func nextClock(from time.Time, hour, minute int) time.Time {
+	t := time.Date(from.Year(), from.Month(), from.Day(), hour, minute, 0, 0, from.Location())
+	if t.Before(from) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func handleReturnAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	userID := query.From.ID
+	chatID := query.Message.Chat.ID
+	// На каждый выход нужен ответ, иначе кнопка крутится до таймаута Telegram
+	answer := func(text string) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, text))
+	}
+	parts := strings.Split(query.Data, "_")
+	if len(parts) < 2 {
+		answer("")
+		return
+	}
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		answer("")
+		return
+	}
+	markTime := time.Unix(ts, 0)
+	dt := markTime.Format(dateFormat)
+	var ret time.Time
+	switch parts[0] {
+	case "retin":
+		pendingReturnInput[userID] = dt
+		bot.Send(tgbotapi.NewMessage(chatID, "Введите время возвращения в формате ЧЧ:ММ:"))
+		answer("Жду время")
+		return
+	case "ret":
+		if len(parts) != 3 {
+			answer("")
+			return
+		}
+		minutes, _ := strconv.Atoi(parts[2])
+		ret = markTime.Add(time.Duration(minutes) * time.Minute)
+	case "retat":
+		if len(parts) != 3 || len(parts[2]) != 4 {
+			answer("")
+			return
+		}
+		h, _ := strconv.Atoi(parts[2][:2])
+		m, _ := strconv.Atoi(parts[2][2:])
+		ret = nextClock(markTime, h, m)
+	default:
+		answer("")
+		return
+	}
+	if !setExpectedReturn(ctx, userID, dt, ret) {
+		answer("Отметка не найдена")
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, "⏳ Ожидаемое возвращение: "+formatExpectedReturn(ret)))
+	answer("Записано!")
+}
+
+func handleReturnInput(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	userID := msg.From.ID
+	dt := pendingReturnInput[userID]
+	t, err := time.ParseInLocation("15:04", strings.TrimSpace(msg.Text), time.Local)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Формат времени: ЧЧ:ММ, например 16:30"))
+		return
+	}
+	delete(pendingReturnInput, userID)
+	markTime, _ := time.ParseInLocation(dateFormat, dt, time.Local)
+	ret := nextClock(markTime, t.Hour(), t.Minute())
+	if !setExpectedReturn(ctx, userID, dt, ret) {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Отметка не найдена."))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "⏳ Ожидаемое возвращение: "+formatExpectedReturn(ret)))
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Главный админ и передача роли ---
+//
+// ID главного админа хранится в settings.csv (root_admin_id), до первой
+// передачи — defaultRootID. Передачу начинает текущий главный админ
+// (/transferroot <ID>), подтверждает новый. Прежний остаётся админом со
+// всеми правами.
+
+const (
+	rootSettingKey     = "root_admin_id"
+	transferSettingKey = "root_transfer" // "<от>:<кому>"
+)
+
+func rootAdminID(ctx context.Context) int {
+	if id, err := strconv.Atoi(getSetting(ctx, rootSettingKey, "")); err == nil && id > 0 {
+		return id
+	}
+	return defaultRootID
+}
+
+func pendingRootTransfer(ctx context.Context) (from, to int, ok bool) {
+	parts := strings.SplitN(getSetting(ctx, transferSettingKey, ""), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	from, err1 := strconv.Atoi(parts[0])
+	to, err2 := strconv.Atoi(parts[1])
+	return from, to, err1 == nil && err2 == nil
+}
+
+func handleTransferRootCommand(ctx context.Context, bot Sender, chatID int64, rootID int, args string) {
+	to, err := strconv.Atoi(strings.TrimSpace(args))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "👑 Передача роли главного админа: /transferroot <Telegram ID>\nОтменить начатую передачу: /transferroot 0"))
+		return
+	}
+	if to == 0 {
+		setSetting(ctx, transferSettingKey, "")
+		bot.Send(tgbotapi.NewMessage(chatID, "Передача роли отменена."))
+		return
+	}
+	if to == rootID || !isUserRegistered(ctx, to) {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Пользователь не найден среди зарегистрированных."))
+		return
+	}
+	var target User
+	for _, u := range getSortedUsers(ctx) {
+		if u.ID == to {
+			target = u
+		}
+	}
+	if target.ChatID == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Пользователь не найден среди зарегистрированных."))
+		return
+	}
+	setSetting(ctx, transferSettingKey, fmt.Sprintf("%d:%d", rootID, to))
+	writeAudit(ctx, rootID, "root_transfer_start", fmt.Sprintf("%d %s", to, target.Name))
+	msg := tgbotapi.NewMessage(target.ChatID, fmt.Sprintf(
+		"👑 %s передаёт вам роль главного админа бота. Принять?", getUserName(ctx, rootID, nil)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Принять", fmt.Sprintf("rootaccept_%d", rootID)),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отказаться", fmt.Sprintf("rootdecline_%d", rootID)),
+	))
+	bot.Send(msg)
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("⏳ Запрос отправлен %s. Роль перейдёт после подтверждения.", target.Name)))
+}
+
+func handleRootTransferAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	userID := int(query.From.ID)
+	chatID := query.Message.Chat.ID
+	from, to, ok := pendingRootTransfer(ctx)
+	parts := strings.SplitN(query.Data, "_", 2)
+	// Запрос должен быть актуальным: тот же отправитель, он всё ещё главный, нажал адресат
+	if !ok || to != userID || parts[1] != strconv.Itoa(from) || from != rootAdminID(ctx) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Запрос больше не действует"))
+		return
+	}
+	setSetting(ctx, transferSettingKey, "")
+	bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+	if parts[0] == "rootdecline" {
+		writeAudit(ctx, userID, "root_transfer_decline", strconv.Itoa(from))
+		bot.Send(tgbotapi.NewMessage(chatID, "Вы отказались от роли."))
+		bot.Send(tgbotapi.NewMessage(int64(from), "❌ "+getUserName(ctx, userID, nil)+" отказался принять роль главного админа."))
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	all := make(map[string]bool)
+	for _, r := range adminRights {
+		all[r.Code] = true
+	}
+	before := getAdminRights(ctx, from)
+	saveAdminRights(ctx, from, getUserName(ctx, from, nil), all)
+	recordRightsChange(ctx, userID, from, before, all)
+	removeUserRows(ctx, adminsFile, userID)
+	setSetting(ctx, rootSettingKey, strconv.Itoa(userID))
+	writeAudit(ctx, userID, "root_transfer_accept", fmt.Sprintf("%d -> %d", from, userID))
+	updateUserCommands(ctx, bot, userID)
+	updateUserCommands(ctx, bot, from)
+	bot.Send(tgbotapi.NewMessage(chatID, "👑 Теперь вы главный админ."))
+	bot.Send(tgbotapi.NewMessage(int64(from), "👑 Роль главного админа передана "+getUserName(ctx, userID, nil)+". У вас остались все права админа."))
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Готово"))
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/xuri/excelize/v2"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Список личного состава, загруженный заранее ---
+//
+// Админ присылает xlsx со столбцами ФИО, Telegram ID и телефон (ID и телефон
+// необязательны). Строки с ID сразу становятся пользователями, остальные
+// попадают в roster.csv и «забираются» при /start по совпадению ФИО.
+
+const rosterFile = "roster.csv" // ФИО, телефон, кто добавил
+
+func init() {
+	backupFiles = append(backupFiles, rosterFile)
+}
+
+type rosterEntry struct {
+	Name  string
+	Phone string
+}
+
+// «Иванов Иван Иванович» -> «Иванов И.И.»; короткая форма остаётся как есть
+func shortFIO(s string) string {
+	parts := strings.Fields(s)
+	if len(parts) != 3 {
+		return strings.Join(parts, " ")
+	}
+	first := []rune(parts[1])
+	middle := []rune(parts[2])
+	return fmt.Sprintf("%s %s.%s.", parts[0], strings.ToUpper(string(first[0])), strings.ToUpper(string(middle[0])))
+}
+
+// Ключ для сравнения ФИО: без регистра, пробелов и с ё -> е
+func nameKey(s string) string {
+	s = strings.ToLower(shortFIO(s))
+	s = strings.ReplaceAll(s, "ё", "е")
+	return strings.ReplaceAll(s, " ", "")
+}
+
+func loadRoster(ctx context.Context) []rosterEntry {
+	var list []rosterEntry
+	for _, row := range readCSV(ctx, rosterFile) {
+		if len(row) > 1 {
+			list = append(list, rosterEntry{Name: row[0], Phone: row[1]})
+		}
+	}
+	return list
+}
+
+func findRosterEntry(ctx context.Context, name string) (rosterEntry, bool) {
+	key := nameKey(name)
+	for _, e := range loadRoster(ctx) {
+		if nameKey(e.Name) == key {
+			return e, true
+		}
+	}
+	return rosterEntry{}, false
+}
+
+// Добавляет запись в список; false, если такое ФИО уже есть в списке или среди пользователей
+func addRosterEntry(ctx context.Context, name, phone string, adminID int) bool {
+	name = shortFIO(name)
+	if _, ok := findRosterEntry(ctx, name); ok {
+		return false
+	}
+	key := nameKey(name)
+	for _, n := range getAllUserNames(ctx) {
+		if nameKey(n) == key {
+			return false
+		}
+	}
+	appendCSV(ctx, rosterFile, []string{name, phone, strconv.Itoa(adminID)})
+	return true
+}
+
+// Пользователь указал ФИО из списка — привязываем запись к его Telegram ID
+func claimRosterEntry(ctx context.Context, userID int, name string, chatID int64) bool {
+	entry, ok := findRosterEntry(ctx, name)
+	if !ok {
+		return false
+	}
+	key := nameKey(entry.Name)
+	// Две одновременные заявки на одну запись: привязывается только первая
+	if !removeRows(ctx, rosterFile, func(row []string) bool { return nameKey(row[0]) == key }) {
+		return false
+	}
+	saveUserName(ctx, userID, entry.Name, chatID)
+	if entry.Phone != "" {
+		setUserPhone(ctx, userID, entry.Phone)
+	}
+	writeAudit(ctx, userID, "claim_roster", entry.Name)
+	return true
+}
+
+// Колонки файла по заголовку; без заголовка — A: ФИО, B: ID, C: телефон
+func rosterColumns(header []string) (name, id, phone int, hasHeader bool) {
+	name, id, phone = 0, 1, 2
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		switch {
+		case strings.Contains(h, "фио") || strings.Contains(h, "фамилия"):
+			name, hasHeader = i, true
+		case strings.Contains(h, "id") || strings.Contains(h, "telegram") || strings.Contains(h, "телеграм"):
+			id, hasHeader = i, true
+		case strings.Contains(h, "тел"):
+			phone, hasHeader = i, true
+		}
+	}
+	return
+}
+
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return strings.TrimSpace(row[i])
+	}
+	return ""
+}
+
+func handleRosterUpload(ctx context.Context, bot Sender, msg *tgbotapi.Message) {
+	adminID := msg.From.ID
+	data, err := downloadTelegramFile(ctx, bot, msg.Document.FileID)
+	if err != nil {
+		log.Printf("roster: %v", err)
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "Ошибка загрузки файла"))
+		return
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ Не удалось прочитать xlsx-файл."))
+		return
+	}
+	defer f.Close()
+	rows, err := f.GetRows(f.GetSheetName(0))
+	if err != nil || len(rows) == 0 {
+		bot.Send(tgbotapi.NewMessage(msg.Chat.ID, "❗ В файле нет данных."))
+		return
+	}
+	nameCol, idCol, phoneCol, hasHeader := rosterColumns(rows[0])
+	if hasHeader {
+		rows = rows[1:]
+	}
+	var created, listed, skipped int
+	var bad []string
+	for n, row := range rows {
+		raw := strings.TrimSpace(cell(row, nameCol))
+		if raw == "" {
+			continue
+		}
+		name, ok := validate.Name(raw)
+		if !ok {
+			bad = append(bad, fmt.Sprintf("строка %d: %s", n+1, raw))
+			continue
+		}
+		if id, err := strconv.Atoi(cell(row, idCol)); err == nil && id > 0 {
+			if isUserRegistered(ctx, id) {
+				skipped++
+				continue
+			}
+			// В личном чате chat ID совпадает с ID пользователя
+			saveUserName(ctx, id, name, int64(id))
+			created++
+			continue
+		}
+		if addRosterEntry(ctx, name, cell(row, phoneCol), adminID) {
+			listed++
+		} else {
+			skipped++
+		}
+	}
+	writeAudit(ctx, adminID, "import_roster", fmt.Sprintf("пользователей %d, в списке %d, пропущено %d", created, listed, skipped))
+	var b strings.Builder
+	b.WriteString("📋 Импорт завершён\n")
+	b.WriteString(fmt.Sprintf("👤 Создано пользователей (по Telegram ID): %d\n", created))
+	b.WriteString(fmt.Sprintf("📝 Ожидают /start: %d\n", listed))
+	b.WriteString(fmt.Sprintf("⏭ Уже есть: %d\n", skipped))
+	if len(bad) > 0 {
+		b.WriteString(fmt.Sprintf("\n❗ Не распознано ФИО (%d):\n", len(bad)))
+		for i, s := range bad {
+			if i == 10 {
+				b.WriteString("…\n")
+				break
+			}
+			b.WriteString("— " + s + "\n")
+		}
+	}
+	bot.Send(tgbotapi.NewMessage(msg.Chat.ID, b.String()))
+}
+
+// /adduser Фамилия И.О. [телефон] — добавить человека до его /start
+func handleAddUserCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	fields := strings.Fields(args)
+	phone := ""
+	if n := len(fields); n > 0 && strings.ContainsAny(fields[n-1][:1], "+0123456789") {
+		phone = fields[n-1]
+		fields = fields[:n-1]
+	}
+	name, ok := validate.Name(strings.Join(fields, " "))
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, "✏️ Введите: /adduser Фамилия И.О. [телефон]\nНапример: /adduser Иванов И.И. +79001234567"))
+		return
+	}
+	if !addRosterEntry(ctx, name, phone, adminID) {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ "+name+" уже есть в списке или среди пользователей."))
+		return
+	}
+	writeAudit(ctx, adminID, "add_roster", strings.TrimSpace(name+" "+phone))
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ "+name+" добавлен. Когда он запустит бота и введёт это ФИО, запись будет привязана к его Telegram."))
+}
+
+// /roster — кто добавлен, но ещё не запустил бота
+func sendRosterList(ctx context.Context, bot Sender, chatID int64) {
+	list := loadRoster(ctx)
+	if len(list) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "📋 Все добавленные уже запустили бота."))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📋 Ожидают /start (%d):\n", len(list)))
+	for _, e := range list {
+		if e.Phone != "" {
+			b.WriteString(fmt.Sprintf("— %s, %s\n", e.Name, e.Phone))
+		} else {
+			b.WriteString("— " + e.Name + "\n")
+		}
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Команда «/name». Right — нужное право (пустая строка — доступна всем);
+// без права команда молча игнорируется. Description попадает в меню «/»,
+// Hidden — команда работает, но в меню не показывается.
+type Command struct {
+	Name        string
+	Description string
+	Right       string
+	Hidden      bool
+	Run         func(ctx context.Context, bot Sender, msg *tgbotapi.Message)
+}
+
+// Callback-кнопка: Data — точное совпадение, иначе Prefix. Без права
+// пользователь получает ответ «недостаточно прав».
+type Callback struct {
+	Data   string
+	Prefix string
+	Right  string
+	Run    func(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery)
+}
+
+// Маршруты команд и кнопок вместе с правами. Точные callback проверяются
+// раньше префиксов, префиксы — в порядке регистрации. ctx апдейта доходит
+// до проверки прав и обработчика.
+type Router struct {
+	// Есть ли у пользователя право; пустое право проверять не нужно
+	Allowed func(ctx context.Context, userID int, right string) bool
+	// Дополнительный допуск к кнопке без права (например, командиру —
+	// журнал своего человека); nil — нет
+	AllowCallback func(ctx context.Context, userID int, data string) bool
+
+	commands []*Command
+	byName   map[string]*Command
+	exact    map[string]*Callback
+	prefixes []*Callback
+}
+
+func (r *Router) Command(c Command) {
+	if r.byName == nil {
+		r.byName = make(map[string]*Command)
+	}
+	r.commands = append(r.commands, &c)
+	r.byName[c.Name] = &c
+}
+
+func (r *Router) Callback(c Callback) {
+	if c.Data != "" {
+		if r.exact == nil {
+			r.exact = make(map[string]*Callback)
+		}
+		r.exact[c.Data] = &c
+		return
+	}
+	r.prefixes = append(r.prefixes, &c)
+}
+
+// Команды в порядке регистрации — для меню «/» и /help
+func (r *Router) Commands() []Command {
+	out := make([]Command, 0, len(r.commands))
+	for _, c := range r.commands {
+		out = append(out, *c)
+	}
+	return out
+}
+
+func (r *Router) allowed(ctx context.Context, userID int, right string) bool {
+	return right == "" || r.Allowed(ctx, userID, right)
+}
+
+// Выполняет команду; false — такой команды нет
+func (r *Router) RunCommand(ctx context.Context, bot Sender, msg *tgbotapi.Message) bool {
+	c, ok := r.byName[msg.Command()]
+	if !ok {
+		return false
+	}
+	if r.allowed(ctx, int(msg.From.ID), c.Right) {
+		c.Run(ctx, bot, msg)
+	}
+	return true
+}
+
+func (r *Router) findCallback(data string) *Callback {
+	if c, ok := r.exact[data]; ok {
+		return c
+	}
+	for _, c := range r.prefixes {
+		if strings.HasPrefix(data, c.Prefix) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Право, нужное для кнопки; пустая строка — доступна всем
+func (r *Router) CallbackRight(data string) string {
+	if c := r.findCallback(data); c != nil {
+		return c.Right
+	}
+	return ""
+}
+
+// Выполняет обработчик кнопки; false — маршрута нет, разбирает вызывающий
+func (r *Router) RunCallback(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) bool {
+	c := r.findCallback(query.Data)
+	if c == nil {
+		return false
+	}
+	userID := int(query.From.ID)
+	if !r.allowed(ctx, userID, c.Right) &&
+		(r.AllowCallback == nil || !r.AllowCallback(ctx, userID, query.Data)) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "⛔ Недостаточно прав"))
+		return true
+	}
+	c.Run(ctx, bot, query)
+	return true
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/crypto/scrypt"
+
+	"tabel-go/internal/config"
+	"tabel-go/internal/storage"
+)
+
+// --- Автоматические копии в S3-совместимое хранилище ---
+//
+// Переменные окружения:
+//   S3_ENDPOINT            — https://s3.eu-central-1.amazonaws.com, https://s3.us-west-004.backblazeb2.com, MinIO и т.п.
+//   S3_BUCKET, S3_REGION   — бакет и регион (по умолчанию us-east-1)
+//   S3_ACCESS_KEY, S3_SECRET_KEY
+//   S3_PREFIX              — префикс ключей (по умолчанию "tabel-backups/")
+//   S3_BACKUP_INTERVAL     — период в часах (по умолчанию 24)
+//   S3_BACKUP_RETENTION    — сколько дней хранить копии (по умолчанию 30, 0 — не удалять)
+//   BACKUP_ENCRYPTION_KEY  — пароль для шифрования AES-GCM (обязателен)
+//
+// Ключ шифрования выводится из пароля через scrypt со случайной солью,
+// соль лежит в начале объекта: s3SealMagic, соль, nonce и шифртекст.
+// Копии без s3SealMagic сделаны до появления соли — их ключ sha256(пароль).
+// Восстановление — /restore s3.
+
+const (
+	s3KeyLayout  = "2006-01-02_15-04-05"
+	s3SealMagic  = "TBS3K1\n"
+	s3SaltSize   = 16
+	s3RestoreMax = 10 // сколько последних копий показывает /restore s3
+)
+
+type s3Config struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Prefix    string
+	Interval  time.Duration
+	Retention int
+	Password  string
+}
+
+var s3Client = &http.Client{Timeout: 60 * time.Second}
+
+func loadS3Config() (*s3Config, bool) {
+	if sandboxMode {
+		return nil, false
+	}
+	cfg := &s3Config{
+		Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		Region:    os.Getenv("S3_REGION"),
+		AccessKey: config.Secret("S3_ACCESS_KEY"),
+		SecretKey: config.Secret("S3_SECRET_KEY"),
+		Prefix:    os.Getenv("S3_PREFIX"),
+		Interval:  24 * time.Hour,
+		Retention: 30,
+	}
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, false
+	}
+	pass := config.Secret("BACKUP_ENCRYPTION_KEY")
+	if pass == "" {
+		log.Printf("s3: BACKUP_ENCRYPTION_KEY не задан, выгрузка копий отключена")
+		return nil, false
+	}
+	cfg.Password = pass
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "tabel-backups/"
+	}
+	if h, err := strconv.Atoi(os.Getenv("S3_BACKUP_INTERVAL")); err == nil && h > 0 {
+		cfg.Interval = time.Duration(h) * time.Hour
+	}
+	if d, err := strconv.Atoi(os.Getenv("S3_BACKUP_RETENTION")); err == nil && d >= 0 {
+		cfg.Retention = d
+	}
+	return cfg, true
+}
+
+// Расписание задачи s3backup по S3_BACKUP_INTERVAL: интервал меньше суток —
+// каждые N часов, иначе — раз в N/24 дней в полночь. CRON_S3BACKUP важнее.
+func s3BackupSpec(cfg *s3Config) string {
+	h := int(cfg.Interval / time.Hour)
+	if h < 24 {
+		return jobSpec("s3backup", fmt.Sprintf("0 */%d * * *", h))
+	}
+	return jobSpec("s3backup", fmt.Sprintf("0 0 */%d * *", h/24))
+}
+
+// Один запуск задачи s3backup: выгрузка копии и очистка старых
+func runS3Backup(ctx context.Context, cfg *s3Config, now time.Time) error {
+	if err := uploadS3Backup(ctx, cfg, now); err != nil {
+		return fmt.Errorf("выгрузка: %w", err)
+	}
+	if err := pruneS3Backups(ctx, cfg, now); err != nil {
+		return fmt.Errorf("очистка старых копий: %w", err)
+	}
+	return nil
+}
+
+func uploadS3Backup(ctx context.Context, cfg *s3Config, now time.Time) error {
+	data, err := buildBackupArchive()
+	if err != nil {
+		return err
+	}
+	sealed, err := sealS3Backup(cfg.Password, data)
+	if err != nil {
+		return err
+	}
+	key := cfg.Prefix + "backup_" + now.UTC().Format(s3KeyLayout) + ".zip.enc"
+	_, err = s3Do(ctx, cfg, "PUT", key, nil, sealed)
+	return err
+}
+
+// Ключ копии из пароля и соли
+func s3BackupKey(pass string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(pass), salt, 1<<15, 8, 1, 32)
+}
+
+func sealS3Backup(pass string, data []byte) ([]byte, error) {
+	salt := make([]byte, s3SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := s3BackupKey(pass, salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := storage.Encrypt(key, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(s3SealMagic), salt...), sealed...), nil
+}
+
+func openS3Backup(pass string, obj []byte) ([]byte, error) {
+	if !bytes.HasPrefix(obj, []byte(s3SealMagic)) {
+		key := sha256.Sum256([]byte(pass))
+		return storage.Decrypt(key[:], obj)
+	}
+	rest := obj[len(s3SealMagic):]
+	if len(rest) < s3SaltSize {
+		return nil, errors.New("копия повреждена")
+	}
+	key, err := s3BackupKey(pass, rest[:s3SaltSize])
+	if err != nil {
+		return nil, err
+	}
+	return storage.Decrypt(key, rest[s3SaltSize:])
+}
+
+// Метки времени копий в бакете, от новых к старым
+func listS3Backups(ctx context.Context, cfg *s3Config) ([]string, error) {
+	keys, err := listS3Keys(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	var stamps []string
+	for _, key := range keys {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(key, cfg.Prefix+"backup_"), ".zip.enc")
+		if _, err := time.Parse(s3KeyLayout, stamp); err == nil {
+			stamps = append(stamps, stamp)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(stamps)))
+	return stamps, nil
+}
+
+func downloadS3Backup(ctx context.Context, cfg *s3Config, stamp string) ([]byte, error) {
+	obj, err := s3Do(ctx, cfg, "GET", cfg.Prefix+"backup_"+stamp+".zip.enc", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := openS3Backup(cfg.Password, obj)
+	if err != nil {
+		return nil, fmt.Errorf("не расшифровать (BACKUP_ENCRYPTION_KEY): %w", err)
+	}
+	return data, nil
+}
+
+// /restore s3 — последние копии, /restore s3 <метка> — восстановить копию
+// после того же подтверждения, что и для присланного архива
+func handleS3RestoreCommand(ctx context.Context, bot Sender, chatID int64, userID int, stamp string) {
+	cfg, ok := loadS3Config()
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Выгрузка в S3 не настроена."))
+		return
+	}
+	if stamp == "" {
+		stamps, err := listS3Backups(ctx, cfg)
+		if err != nil {
+			log.Printf("s3: список копий: %v", err)
+			bot.Send(tgbotapi.NewMessage(chatID, "Ошибка чтения списка копий из S3"))
+			return
+		}
+		if len(stamps) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "В S3 нет копий."))
+			return
+		}
+		if len(stamps) > s3RestoreMax {
+			stamps = stamps[:s3RestoreMax]
+		}
+		var b strings.Builder
+		b.WriteString("☁️ Копии в S3 (UTC), от новых к старым:\n")
+		for _, s := range stamps {
+			b.WriteString("/restore s3 " + s + "\n")
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+		return
+	}
+	if _, err := time.Parse(s3KeyLayout, stamp); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Метка копии в формате 2006-01-02_15-04-05, список: /restore s3"))
+		return
+	}
+	data, err := downloadS3Backup(ctx, cfg, stamp)
+	if err != nil {
+		log.Printf("s3: загрузка %s: %v", stamp, err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка загрузки копии: "+err.Error()))
+		return
+	}
+	askRestoreConfirm(bot, chatID, userID, data)
+}
+
+// Удаляет копии старше срока хранения
+func pruneS3Backups(ctx context.Context, cfg *s3Config, now time.Time) error {
+	if cfg.Retention == 0 {
+		return nil
+	}
+	keys, err := listS3Keys(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	cutoff := now.AddDate(0, 0, -cfg.Retention)
+	for _, key := range keys {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(key, cfg.Prefix+"backup_"), ".zip.enc")
+		t, err := time.Parse(s3KeyLayout, stamp)
+		if err != nil || !t.Before(cutoff) {
+			continue
+		}
+		if _, err := s3Do(ctx, cfg, "DELETE", key, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listS3Keys(ctx context.Context, cfg *s3Config) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {cfg.Prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		body, err := s3Do(ctx, cfg, "GET", "", q, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := xml.Unmarshal(body, &res); err != nil {
+			return nil, err
+		}
+		for _, c := range res.Contents {
+			keys = append(keys, c.Key)
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+// --- Минимальный клиент S3 с подписью AWS Signature V4 (path-style) ---
+
+func s3Do(ctx context.Context, cfg *s3Config, method, key string, query url.Values, body []byte) ([]byte, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	path := "/" + cfg.Bucket
+	if key != "" {
+		path += "/" + key
+	}
+	canonicalURI := s3EscapePath(path)
+	canonicalQuery := s3CanonicalQuery(query)
+	endpoint := u.Scheme + "://" + u.Host + canonicalURI
+	if canonicalQuery != "" {
+		endpoint += "?" + canonicalQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	now := clock.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if body != nil {
+		req.ContentLength = int64(len(body))
+	}
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + u.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		method, canonicalURI, canonicalQuery, canonicalHeaders, signedHeaders, payloadHash,
+	}, "\n")
+	scope := day + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	signingKey := hmacSHA256([]byte("AWS4"+cfg.SecretKey), day)
+	signingKey = hmacSHA256(signingKey, cfg.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKey, scope, signedHeaders, signature))
+
+	resp, err := s3Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, key, resp.Status, respBody)
+	}
+	return respBody, nil
+}
+
+func s3EscapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = s3Escape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func s3CanonicalQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			pairs = append(pairs, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// URI-кодирование по правилам SigV4: всё, кроме A-Z a-z 0-9 - _ . ~
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"tabel-go/internal/storage"
+)
+
+func TestS3BackupSealing(t *testing.T) {
+	data := []byte("PK архив")
+	a, err := sealS3Backup("пароль", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := sealS3Backup("пароль", data)
+	if bytes.Equal(a[:len(s3SealMagic)+s3SaltSize], b[:len(s3SealMagic)+s3SaltSize]) {
+		t.Fatal("соль не меняется между копиями")
+	}
+	if got, err := openS3Backup("пароль", a); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("openS3Backup = %q, %v", got, err)
+	}
+	if _, err := openS3Backup("другой", a); err == nil {
+		t.Fatal("открыто чужим паролем")
+	}
+
+	// Копия, сделанная до появления соли
+	key := sha256.Sum256([]byte("пароль"))
+	legacy, _ := storage.Encrypt(key[:], data)
+	if got, err := openS3Backup("пароль", legacy); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("старая копия: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/config"
+)
+
+// --- Песочница (--sandbox) ---
+//
+// Запуск с флагом --sandbox (или SANDBOX=1) переносит все данные в
+// отдельный каталог SANDBOX_DIR (по умолчанию ./sandbox). При первом
+// запуске туда копируются текущие CSV-файлы, дальше песочница живёт
+// своей жизнью: очистка, смена прав и прочее не трогают рабочие данные.
+// Каждое исходящее сообщение начинается с 🧪. Выгрузка в Google Sheets,
+// копии в S3 и публикации в канал отключены. Если задан
+// SANDBOX_TELEGRAM_TOKEN, песочница работает через отдельного бота —
+// так рабочий бот не конфликтует с ней за апдейты, а подразделение не
+// получает напоминаний.
+
+const sandboxPrefix = "🧪 "
+
+var sandboxMode bool
+
+var sandboxFlag = flag.Bool("sandbox", false, "песочница: отдельный каталог данных, 🧪 в сообщениях")
+
+// Вызывается первым в Run, до любой работы с файлами
+func setupSandbox() {
+	flag.Parse()
+	sandboxMode = *sandboxFlag || os.Getenv("SANDBOX") == "1"
+	if !sandboxMode {
+		return
+	}
+	dir := os.Getenv("SANDBOX_DIR")
+	if dir == "" {
+		dir = "sandbox"
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := seedSandbox(dir); err != nil {
+			log.Fatalf("sandbox: %v", err)
+		}
+		log.Printf("sandbox: данные скопированы в %s", dir)
+	}
+	if err := os.Chdir(dir); err != nil {
+		log.Fatalf("sandbox: %v", err)
+	}
+	// Подпись к логам, чтобы их не спутать с рабочими
+	log.SetPrefix("[sandbox] ")
+	log.Printf("sandbox: режим песочницы, данные в %s", dir)
+}
+
+// Копия текущих данных — отправная точка песочницы
+func seedSandbox(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	files, _ := filepath.Glob("*.csv")
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sandboxBotToken() string {
+	if t := config.Secret("SANDBOX_TELEGRAM_TOKEN"); t != "" {
+		return t
+	}
+	return config.Secret("TELEGRAM_TOKEN")
+}
+
+// Клиент с таймаутом: зависший запрос к Telegram не держит обработчик
+// вечно. Запас сверху — на long polling getUpdates (u.Timeout в Run).
+func newBot(token string) (*tgbotapi.BotAPI, error) {
+	client := &http.Client{Timeout: telegramRequestTimeout}
+	if sandboxMode {
+		client.Transport = sandboxTransport{http.DefaultTransport}
+	}
+	return tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, client)
+}
+
+// Дописывает 🧪 к text и caption в каждом запросе к Bot API
+type sandboxTransport struct{ next http.RoundTripper }
+
+func (t sandboxTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.next.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for _, key := range []string{"text", "caption"} {
+				if v := values.Get(key); v != "" {
+					values.Set(key, sandboxPrefix+v)
+				}
+			}
+			body = []byte(values.Encode())
+		}
+	case "multipart/form-data":
+		if rewritten, err := prefixMultipart(body, params["boundary"]); err == nil {
+			body = rewritten
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return t.next.RoundTrip(req)
+}
+
+// Пересобирает multipart-запрос (отправка файлов) с 🧪 в подписи
+func prefixMultipart(body []byte, boundary string) ([]byte, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var out bytes.Buffer
+	writer := multipart.NewWriter(&out)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		if name := part.FormName(); (name == "caption" || name == "text") && part.FileName() == "" && len(data) > 0 {
+			data = append([]byte(sandboxPrefix), data...)
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		w.Write(data)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
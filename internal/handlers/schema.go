@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"tabel-go/internal/journal"
+	"tabel-go/internal/storage"
+)
+
+// --- Версия формата данных и миграции ---
+//
+// Номер версии хранится в настройке schema_version. При запуске
+// runMigrations по очереди применяет недостающие миграции, сохраняя номер
+// после каждой. Перед первой из них делается копия всех данных в
+// schema_backup_v<версия>_<время>.zip. Новая миграция добавляется в конец
+// списка со следующим номером; менять уже выпущенные нельзя.
+
+const schemaVersionKey = "schema_version"
+
+type migration struct {
+	Version int
+	Title   string
+	Apply   func(ctx context.Context) error
+}
+
+var migrations = []migration{
+	{1, "users.csv: колонки архива, подразделения и телефона у всех строк", migratePadUsers},
+	{2, "журнал: колонки источника, возвращения, геометки, фото и комментария у всех строк", migratePadJournal},
+	{3, "заголовки во всех CSV-файлах", migrateAddHeaders},
+}
+
+func schemaVersion(ctx context.Context) int {
+	v, _ := strconv.Atoi(getSetting(ctx, schemaVersionKey, "0"))
+	return v
+}
+
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Дополняет строки пустыми колонками до n
+func padRows(ctx context.Context, file string, n int) {
+	storage.Rewrite(ctx, file, func(rows [][]string) [][]string {
+		for i := range rows {
+			for len(rows[i]) < n {
+				rows[i] = append(rows[i], "")
+			}
+		}
+		return rows
+	})
+}
+
+func migratePadUsers(ctx context.Context) error {
+	padRows(ctx, usersFile, colUserPhone+1)
+	return nil
+}
+
+func migratePadJournal(ctx context.Context) error {
+	for _, f := range append(journal.Archives(), dataFile) {
+		padRows(ctx, f, colComment+1)
+	}
+	return nil
+}
+
+// Перезапись без отбрасывания коротких строк (их потом покажет
+// /checkdata) добавляет заголовок (csvtable.go) файлам, где его ещё нет
+func migrateAddHeaders(ctx context.Context) error {
+	files := append(journal.Archives(), adminsFile)
+	for name := range csvTables {
+		files = append(files, name)
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			storage.Rewrite(ctx, f, func(rows [][]string) [][]string { return rows })
+		}
+	}
+	return nil
+}
+
+// При запуске, до любой работы с данными
+func runMigrations(ctx context.Context) error {
+	current := schemaVersion(ctx)
+	if current > latestSchemaVersion() {
+		log.Printf("schema: данные версии %d новее, чем понимает эта сборка (%d)", current, latestSchemaVersion())
+		return nil
+	}
+	if current == latestSchemaVersion() {
+		return nil
+	}
+	if data, err := buildBackupArchive(); err == nil {
+		name := fmt.Sprintf("schema_backup_v%d_%d.zip", current, clock.Now().Unix())
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			return fmt.Errorf("копия перед миграцией: %w", err)
+		}
+	} else {
+		return fmt.Errorf("копия перед миграцией: %w", err)
+	}
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		log.Printf("schema: миграция %d — %s", m.Version, m.Title)
+		if err := m.Apply(ctx); err != nil {
+			return fmt.Errorf("миграция %d: %w", m.Version, err)
+		}
+		setSetting(ctx, schemaVersionKey, strconv.Itoa(m.Version))
+	}
+	return nil
+}
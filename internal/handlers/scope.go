@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/scheduler"
+)
+
+// --- Несколько подразделений в одном боте ---
+//
+// Главный админ распределяет людей по подразделениям (карточка, /units) и
+// закрепляет админа за подразделением (/scope). Такой админ видит в
+// карточках ЛС, сводках, табеле, аналитике, опозданиях, выгрузках и
+// оповещениях только людей своего подразделения и правит только их
+// записи; вечерняя сводка приходит ему по его подразделению — в общее
+// время или по своему расписанию подразделения (/units report, задача
+// report:<подразделение>). Главный админ видит всех.
+//
+// Файлы данных на подразделения не делятся: журнал, архивы и WAL общие,
+// разделение — по колонке подразделения в users.csv, а выгрузка по
+// подразделению — /tabel и «🏷 По подразделению». Отдельные файлы
+// потребовали бы переписать всё чтение журнала, ротацию, WAL и копии ради
+// того же результата.
+
+func scopeKey(adminID int) string {
+	return "scope:" + strconv.Itoa(adminID)
+}
+
+// Подразделение, за которым закреплён админ; пусто — видит всех
+func adminScope(ctx context.Context, adminID int) string {
+	if isRootAdmin(ctx, adminID) {
+		return ""
+	}
+	return getSetting(ctx, scopeKey(adminID), "")
+}
+
+// Личный состав, видимый в этом чате
+func scopedUsers(ctx context.Context, chatID int64) []User {
+	users := getSortedUsers(ctx)
+	unit := adminScope(ctx, int(chatID))
+	if unit == "" {
+		return users
+	}
+	members := unitMembers(ctx, unit)
+	var out []User
+	for _, u := range users {
+		if members[strconv.Itoa(u.ID)] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// Строки журнала, которые видит админ этого чата
+func scopedRows(ctx context.Context, chatID int64, rows [][]string) [][]string {
+	unit := adminScope(ctx, int(chatID))
+	if unit == "" {
+		return rows
+	}
+	members := unitMembers(ctx, unit)
+	var out [][]string
+	for _, row := range rows {
+		if len(row) > 1 && members[row[1]] {
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// Чужое подразделение для закреплённого админа закрыто
+func adminSeesUnit(ctx context.Context, adminID int, unit string) bool {
+	scope := adminScope(ctx, adminID)
+	return scope == "" || scope == unit
+}
+
+// --- Своё расписание вечерней сводки подразделения ---
+
+func unitReportKey(unit string) string {
+	return "unitreport:" + unit
+}
+
+// cron-расписание сводки подразделения; пусто — общее время задачи report
+func unitReportSpec(ctx context.Context, unit string) string {
+	return getSetting(ctx, unitReportKey(unit), "")
+}
+
+// В /jobs имя задачи — одно слово
+func unitReportJobName(unit string) string {
+	return "report:" + strings.ReplaceAll(unit, " ", "_")
+}
+
+func unitReportJob(ctx context.Context, bot Sender, unit, spec string) scheduler.Job {
+	name := unitReportJobName(unit)
+	return scheduler.Job{Name: name, Spec: spec, CatchUp: 4 * time.Hour, Enabled: jobEnabled(ctx, name),
+		Run: func(ctx context.Context, now time.Time) error {
+			if isDutyDay(ctx, now) {
+				sendUnitReport(ctx, bot, unit, now)
+			}
+			return nil
+		}}
+}
+
+func unitReportJobs(ctx context.Context, bot Sender) []scheduler.Job {
+	var list []scheduler.Job
+	for _, unit := range loadUnits(ctx) {
+		if spec := unitReportSpec(ctx, unit); spec != "" {
+			list = append(list, unitReportJob(ctx, bot, unit, spec))
+		}
+	}
+	return list
+}
+
+// Сводка на момент now админам, закреплённым за подразделением
+func sendUnitReport(ctx context.Context, bot Sender, unit string, now time.Time) {
+	for _, chatID := range scopedAdmins(ctx) {
+		if adminScope(ctx, int(chatID)) == unit {
+			adminSummaryAt(ctx, bot, chatID, now)
+		}
+	}
+}
+
+// Пустой spec возвращает подразделение к общему времени
+func setUnitReportSpec(ctx context.Context, unit, spec string) error {
+	if spec != "" {
+		if _, err := scheduler.ParseCron(spec); err != nil {
+			return err
+		}
+	}
+	setSetting(ctx, unitReportKey(unit), spec)
+	if jobs == nil {
+		return nil
+	}
+	jobs.Remove(unitReportJobName(unit))
+	if spec == "" {
+		return nil
+	}
+	return jobs.Add(unitReportJob(ctx, jobsBot, unit, spec))
+}
+
+// /units report <подразделение> = <cron> | off
+func handleUnitReportCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	name, spec, ok := strings.Cut(args, "=")
+	unit := findUnit(ctx, strings.TrimSpace(name))
+	spec = strings.TrimSpace(spec)
+	if !ok || unit == "" || spec == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "Использование: /units report 1 взвод = 0 20 * * 1-5 (off — общее время)"))
+		return
+	}
+	if spec == "off" {
+		spec = ""
+	}
+	if err := setUnitReportSpec(ctx, unit, spec); err != nil {
+		log.Printf("units report %s: %v", unit, err)
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ "+err.Error()))
+		return
+	}
+	writeAudit(ctx, adminID, "unit_report", unit+" = "+spec)
+	if spec == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ "+unit+": сводка в общее время"))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ "+unit+": сводка по расписанию "+spec+", задача "+unitReportJobName(unit)))
+}
+
+// Админы, закреплённые за подразделениями
+func scopedAdmins(ctx context.Context) []int64 {
+	var ids []int64
+	for _, a := range getAdmins(ctx) {
+		if adminScope(ctx, a.ID) != "" {
+			ids = append(ids, int64(a.ID))
+		}
+	}
+	return ids
+}
+
+func adminSeesUser(ctx context.Context, chatID int64, userID string) bool {
+	unit := adminScope(ctx, int(chatID))
+	return unit == "" || userUnits(ctx)[userID] == unit
+}
+
+// /scope — список, /scope <ID> <подразделение> — закрепить, /scope <ID> — снять
+func handleScopeCommand(ctx context.Context, bot Sender, chatID int64, rootID int, args string) {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	if fields[0] == "" {
+		text := "🏷 Закрепление админов за подразделениями:\n"
+		count := 0
+		for _, a := range getAdmins(ctx) {
+			if unit := adminScope(ctx, a.ID); unit != "" {
+				text += fmt.Sprintf("— %s: %s\n", capitalizeName(a.Name), unit)
+				count++
+			}
+		}
+		if count == 0 {
+			text += "нет, все админы видят весь личный состав\n"
+		}
+		text += "\nЗакрепить: /scope <ID> 1 взвод\nСнять: /scope <ID>"
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+		return
+	}
+	adminID, err := strconv.Atoi(fields[0])
+	if err != nil || !isAdminAny(ctx, adminID) || isRootAdmin(ctx, adminID) {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Админ с таким ID не найден."))
+		return
+	}
+	unit := ""
+	if len(fields) == 2 {
+		if unit = findUnit(ctx, fields[1]); unit == "" {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Подразделение не найдено. Список: /units"))
+			return
+		}
+	}
+	setSetting(ctx, scopeKey(adminID), unit)
+	writeAudit(ctx, rootID, "set_admin_scope", fmt.Sprintf("%d: %s", adminID, unit))
+	name := capitalizeName(getUserName(ctx, adminID, nil))
+	if unit == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ "+name+" снова видит весь личный состав."))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, "✅ "+name+" закреплён за подразделением «"+unit+"»."))
+}
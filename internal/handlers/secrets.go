@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"log"
+	"os"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/config"
+)
+
+// --- Секреты в логах ---
+//
+// Секреты загружает config.Secret (TELEGRAM_TOKEN, *_FILE, SECRETS_DIR).
+// Загруженные значения вычёркиваются из лога: клиент Telegram пишет токен
+// в URL запроса, и он попадает в тексты сетевых ошибок.
+
+func init() {
+	log.SetOutput(config.RedactingWriter{W: os.Stderr})
+	// У библиотеки Telegram свой логгер, он пишет ошибки запросов с URL
+	tgbotapi.SetLogger(log.New(config.RedactingWriter{W: os.Stderr}, "", log.LstdFlags))
+}
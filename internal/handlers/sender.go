@@ -0,0 +1,109 @@
+// Package handlers — бот: путь апдейта от Telegram до обработчика и сами
+// обработчики команд, кнопок, Mini App и веб-админки вместе с данными,
+// которые им нужны (реестр пользователей, права, ожидания ввода).
+//
+// Слои ниже живут отдельно и от обработчиков не зависят: хранение файлов —
+// internal/storage, журнал отметок на диске — internal/journal, проверка
+// ввода — internal/validate, расписания — internal/scheduler, выгрузка в
+// Excel — internal/export, секреты — internal/config. В package main
+// остаётся только запуск.
+package handlers
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/scheduler"
+)
+
+// --- Зависимости: Telegram и часы ---
+//
+// Обработчики и планировщики получают Sender вместо конкретного
+// *tgbotapi.BotAPI и берут время у clock вместо time.Now и time.Sleep.
+// В работе это botSender и scheduler.System; подставив свои реализации, можно
+// прогнать обработчик или планировщик без Telegram и с заданным временем —
+// так устроены тесты в handlers_test.go (fakeSender, fakeClock).
+
+// То, чем код пользуется у клиента Telegram
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+	AnswerCallbackQuery(c tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
+	BotUsername() string
+}
+
+type botSender struct {
+	*tgbotapi.BotAPI
+}
+
+func newSender(api *tgbotapi.BotAPI) Sender {
+	return botSender{api}
+}
+
+// Ответ на нажатие кнопки — обычный запрос answerCallbackQuery
+func (b botSender) AnswerCallbackQuery(c tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error) {
+	resp, err := b.Request(c)
+	if resp == nil {
+		return tgbotapi.APIResponse{}, err
+	}
+	return *resp, err
+}
+
+func (b botSender) BotUsername() string {
+	return b.Self.UserName
+}
+
+type Clock = scheduler.Clock
+
+var clock Clock = scheduler.System{}
+
+// Sender, привязанный к контексту апдейта: после отмены (таймаут
+// обработки, остановка бота) ничего не отправляет
+type contextSender struct {
+	Sender
+	ctx context.Context
+}
+
+func WithContext(ctx context.Context, bot Sender) Sender {
+	return contextSender{bot, ctx}
+}
+
+// Sender без привязки к апдейту — для того, что отправляется после
+// возврата из обработчика (отложенное удаление, фоновые задачи)
+func Detach(bot Sender) Sender {
+	if cs, ok := bot.(contextSender); ok {
+		return cs.Sender
+	}
+	return bot
+}
+
+func (s contextSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if err := s.ctx.Err(); err != nil {
+		return tgbotapi.Message{}, err
+	}
+	return s.Sender.Send(c)
+}
+
+func (s contextSender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Sender.Request(c)
+}
+
+func (s contextSender) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Sender.MakeRequest(endpoint, params)
+}
+
+func (s contextSender) AnswerCallbackQuery(c tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error) {
+	if err := s.ctx.Err(); err != nil {
+		return tgbotapi.APIResponse{}, err
+	}
+	return s.Sender.AnswerCallbackQuery(c)
+}
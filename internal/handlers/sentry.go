@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/config"
+)
+
+// --- Отправка ошибок в Sentry ---
+//
+// Включается переменной SENTRY_DSN (подходит и любой совместимый сервис,
+// например GlitchTip). События уходят напрямую через HTTP envelope API,
+// без SDK: паники обработчиков с контекстом апдейта, паники фоновых задач
+// и ошибки доставки. Текст сообщений пользователей не отправляется.
+
+type sentryDSN struct {
+	Endpoint string
+	Key      string
+	Raw      string
+}
+
+var sentryClient = &http.Client{Timeout: 10 * time.Second}
+
+func loadSentryDSN() (*sentryDSN, bool) {
+	raw := config.Secret("SENTRY_DSN")
+	if raw == "" {
+		return nil, false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil || u.Host == "" {
+		log.Printf("SENTRY_DSN: неверный формат")
+		return nil, false
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project, prefix := path, ""
+	if i >= 0 {
+		project, prefix = path[i+1:], "/"+path[:i]
+	}
+	return &sentryDSN{
+		Endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		Key:      u.User.Username(),
+		Raw:      raw,
+	}, true
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// level: "fatal", "error", "warning"; extra — контекст события
+func captureEvent(level, message string, tags map[string]string, extra map[string]interface{}) {
+	dsn, ok := loadSentryDSN()
+	if !ok {
+		return
+	}
+	env := os.Getenv("SENTRY_ENVIRONMENT")
+	if env == "" {
+		env = "production"
+	}
+	if sandboxMode {
+		env = "sandbox"
+	}
+	host, _ := os.Hostname()
+	id := newEventID()
+	event := map[string]interface{}{
+		"event_id":    id,
+		"timestamp":   clock.Now().UTC().Format(time.RFC3339),
+		"platform":    "go",
+		"level":       level,
+		"logger":      "tabel-go",
+		"release":     "tabel-go@" + buildVersion + "+" + shortCommit(),
+		"environment": env,
+		"server_name": host,
+		"message":     map[string]string{"formatted": config.Redact(message)},
+		"tags":        tags,
+		"extra":       extra,
+	}
+	if uid, ok := tags["user_id"]; ok {
+		event["user"] = map[string]string{"id": uid}
+	}
+	header, _ := json.Marshal(map[string]string{"event_id": id, "dsn": dsn.Raw, "sent_at": clock.Now().UTC().Format(time.RFC3339)})
+	payload, _ := json.Marshal(event)
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteString("\n{\"type\":\"event\"}\n")
+	body.Write(payload)
+	body.WriteString("\n")
+
+	req, err := http.NewRequest(http.MethodPost, dsn.Endpoint, &body)
+	if err != nil {
+		log.Printf("sentry: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=tabel-go/"+buildVersion+", sentry_key="+dsn.Key)
+	resp, err := sentryClient.Do(req)
+	if err != nil {
+		log.Printf("sentry: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("sentry: ответ %s", resp.Status)
+	}
+}
+
+// Ошибка без паники; отправляется в фоне, чтобы не задерживать обработчик
+func captureError(err error, tags map[string]string) {
+	if _, ok := loadSentryDSN(); !ok || err == nil {
+		return
+	}
+	go captureEvent("error", err.Error(), tags, nil)
+}
+
+// Кто и что прислал: ID, чат, команда или данные кнопки
+func updateContext(update tgbotapi.Update) map[string]string {
+	tags := make(map[string]string)
+	var from *tgbotapi.User
+	switch {
+	case update.Message != nil:
+		from = update.Message.From
+		tags["update"] = "message"
+		tags["chat_id"] = strconv.FormatInt(update.Message.Chat.ID, 10)
+		if update.Message.IsCommand() {
+			tags["command"] = update.Message.Command()
+		}
+	case update.EditedMessage != nil:
+		from = update.EditedMessage.From
+		tags["update"] = "edited_message"
+		tags["chat_id"] = strconv.FormatInt(update.EditedMessage.Chat.ID, 10)
+	case update.CallbackQuery != nil:
+		from = update.CallbackQuery.From
+		tags["update"] = "callback"
+		tags["callback"] = update.CallbackQuery.Data
+	case update.InlineQuery != nil:
+		from = update.InlineQuery.From
+		tags["update"] = "inline_query"
+	}
+	if from != nil {
+		tags["user_id"] = strconv.Itoa(from.ID)
+	}
+	return tags
+}
+
+// Паника, уже отправленная в Sentry; reportCrash не шлёт её повторно
+type capturedPanic struct {
+	Value interface{}
+}
+
+func (p capturedPanic) String() string {
+	return fmt.Sprint(p.Value)
+}
+
+// Через defer в обработке апдейта: паника уходит в Sentry с контекстом
+// и продолжается дальше, до reportCrash
+func captureUpdatePanic(update tgbotapi.Update) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	captureEvent("fatal", fmt.Sprintf("panic: %v", r), updateContext(update),
+		map[string]interface{}{"stack": string(debug.Stack())})
+	panic(capturedPanic{r})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import "context"
+
+// --- Настройки бота (ключ-значение в settings.csv) ---
+
+const settingsFile = "settings.csv"
+
+func init() {
+	backupFiles = append(backupFiles, settingsFile)
+}
+
+// Значение настройки или def, если она не задана
+func getSetting(ctx context.Context, key, def string) string {
+	for _, row := range readCSV(ctx, settingsFile) {
+		if len(row) > 1 && row[0] == key {
+			return row[1]
+		}
+	}
+	return def
+}
+
+// Пустое значение удаляет настройку
+func setSetting(ctx context.Context, key, value string) {
+	updateCSV(ctx, settingsFile, func(rows [][]string) [][]string {
+		found := false
+		for i := 0; i < len(rows); i++ {
+			if len(rows[i]) > 0 && rows[i][0] == key {
+				if value == "" {
+					rows = append(rows[:i], rows[i+1:]...)
+					i--
+				} else {
+					rows[i] = []string{key, value}
+				}
+				found = true
+			}
+		}
+		if !found && value != "" {
+			rows = append(rows, []string{key, value})
+		}
+		return rows
+	})
+}
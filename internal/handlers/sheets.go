@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tabel-go/internal/config"
+)
+
+// --- Синхронизация с Google Sheets ---
+//
+// Включается переменными окружения:
+//   GOOGLE_SHEETS_ID      — ID таблицы (из URL)
+//   GOOGLE_SHEETS_RANGE   — лист/диапазон для добавления строк (по умолчанию "Табель!A:E")
+//   GOOGLE_SERVICE_ACCOUNT — JSON ключ сервисного аккаунта или путь к файлу с ним
+// Таблицу нужно расшарить на client_email сервисного аккаунта.
+
+const (
+	sheetsScope        = "https://www.googleapis.com/auth/spreadsheets"
+	sheetsDefaultRange = "Табель!A:E"
+	sheetsAPIBase      = "https://sheets.googleapis.com/v4/spreadsheets/"
+)
+
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+var (
+	sheetsOnce    sync.Once
+	sheetsAccount *serviceAccount
+	sheetsKey     *rsa.PrivateKey
+	sheetsID      string
+	sheetsRange   string
+	sheetsMu      sync.Mutex
+	sheetsToken   string
+	sheetsExpiry  time.Time
+	sheetsClient  = &http.Client{Timeout: 15 * time.Second}
+)
+
+func sheetsEnabled() bool {
+	sheetsOnce.Do(loadSheetsConfig)
+	return sheetsKey != nil
+}
+
+func loadSheetsConfig() {
+	if sandboxMode {
+		return
+	}
+	sheetsID = os.Getenv("GOOGLE_SHEETS_ID")
+	raw := config.Secret("GOOGLE_SERVICE_ACCOUNT")
+	if sheetsID == "" || raw == "" {
+		return
+	}
+	sheetsRange = os.Getenv("GOOGLE_SHEETS_RANGE")
+	if sheetsRange == "" {
+		sheetsRange = sheetsDefaultRange
+	}
+	data := []byte(raw)
+	if !strings.HasPrefix(strings.TrimSpace(raw), "{") {
+		b, err := os.ReadFile(raw)
+		if err != nil {
+			log.Printf("sheets: не удалось прочитать ключ: %v", err)
+			return
+		}
+		data = b
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		log.Printf("sheets: неверный JSON сервисного аккаунта: %v", err)
+		return
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	key, err := parseRSAKey(sa.PrivateKey)
+	if err != nil {
+		log.Printf("sheets: %v", err)
+		return
+	}
+	sheetsAccount = &sa
+	sheetsKey = key
+	log.Printf("sheets: синхронизация включена (%s)", sheetsRange)
+}
+
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("не удалось разобрать private_key")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if rk, ok := k.(*rsa.PrivateKey); ok {
+			return rk, nil
+		}
+		return nil, errors.New("private_key не RSA")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// Токен доступа по JWT сервисного аккаунта, кэшируется до истечения
+func sheetsAccessToken(ctx context.Context) (string, error) {
+	sheetsMu.Lock()
+	defer sheetsMu.Unlock()
+	if sheetsToken != "" && clock.Now().Before(sheetsExpiry.Add(-time.Minute)) {
+		return sheetsToken, nil
+	}
+	now := clock.Now()
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   sheetsAccount.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   sheetsAccount.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, sheetsKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + enc.EncodeToString(sig)},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", sheetsAccount.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := sheetsClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("token: %s: %s", resp.Status, body)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	sheetsToken = tok.AccessToken
+	sheetsExpiry = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return sheetsToken, nil
+}
+
+// Добавляет строки в конец диапазона таблицы
+func appendSheetRows(ctx context.Context, rows [][]string) error {
+	token, err := sheetsAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	values := make([][]interface{}, 0, len(rows))
+	for _, r := range rows {
+		row := make([]interface{}, len(r))
+		for i, v := range r {
+			row[i] = v
+		}
+		values = append(values, row)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"values": values})
+	endpoint := sheetsAPIBase + url.PathEscape(sheetsID) + "/values/" + url.PathEscape(sheetsRange) +
+		":append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := sheetsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("append: %s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// Отправляет отметку в таблицу в фоне, не задерживая ответ пользователю
+func syncMarkToSheet(dt, name, action, location string) {
+	if !sheetsEnabled() {
+		return
+	}
+	date, timePart := splitDateTime(dt)
+	row := []string{date, timePart, name, action, cleanLocation(location)}
+	go func() {
+		if err := appendSheetRows(shutdownCtx, [][]string{row}); err != nil {
+			log.Printf("sheets: не удалось добавить отметку: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Личная статистика (/stats) ---
+
+// Отлучка: от «Убыл» до следующего «Прибыл»
+type absence struct {
+	UID      string
+	Name     string
+	Location string
+	Left     time.Time
+	Returned time.Time
+	Open     bool // ещё не вернулся
+}
+
+func (a absence) Duration(now time.Time) time.Duration {
+	if a.Open {
+		return now.Sub(a.Left)
+	}
+	return a.Returned.Sub(a.Left)
+}
+
+// Отлучки, начавшиеся в [from, to). Если uid пустой — по всем пользователям.
+func collectAbsences(rows [][]string, uid string, from, to time.Time) []absence {
+	open := make(map[string]*absence)
+	var result []absence
+	for _, row := range rows {
+		if len(row) < 5 || (uid != "" && row[1] != uid) {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil {
+			continue
+		}
+		switch row[3] {
+		case "Убыл":
+			if a, ok := open[row[1]]; ok {
+				// Повторное «Убыл» без прибытия — закрываем предыдущую отлучку
+				a.Returned = t
+				a.Open = false
+				result = append(result, *a)
+			}
+			open[row[1]] = &absence{UID: row[1], Name: capitalizeName(row[2]), Location: cleanLocation(row[4]), Left: t, Open: true}
+		case "Прибыл":
+			if a, ok := open[row[1]]; ok {
+				a.Returned = t
+				a.Open = false
+				result = append(result, *a)
+				delete(open, row[1])
+			}
+		}
+	}
+	for _, a := range open {
+		result = append(result, *a)
+	}
+	var filtered []absence
+	for _, a := range result {
+		if !a.Left.Before(from) && a.Left.Before(to) {
+			filtered = append(filtered, a)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Left.Before(filtered[j].Left) })
+	return filtered
+}
+
+func formatDuration(d time.Duration) string {
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	if h == 0 {
+		return fmt.Sprintf("%d мин", m)
+	}
+	if h >= 24 {
+		return fmt.Sprintf("%d д %d ч", h/24, h%24)
+	}
+	return fmt.Sprintf("%d ч %d мин", h, m)
+}
+
+type locationCount struct {
+	Name  string
+	Count int
+}
+
+func topLocations(list []absence, n int) []locationCount {
+	counts := make(map[string]int)
+	for _, a := range list {
+		counts[a.Location]++
+	}
+	var res []locationCount
+	for name, c := range counts {
+		res = append(res, locationCount{name, c})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Name < res[j].Name
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+func sendUserStats(ctx context.Context, bot Sender, chatID int64, userID int) {
+	now := clock.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	rows := journal.ReadSince(ctx, monthStart.AddDate(0, -1, 0))
+	list := collectAbsences(rows, strconv.Itoa(userID), monthStart, now.Add(time.Minute))
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📊 Статистика за %s\n\n", strings.ToLower(monthNames[now.Month()-1])))
+	if len(list) == 0 {
+		b.WriteString("Убытий в этом месяце не было.")
+		bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+		return
+	}
+	var total time.Duration
+	var returnMinutes, returns int
+	for _, a := range list {
+		total += a.Duration(now)
+		if !a.Open {
+			returnMinutes += a.Returned.Hour()*60 + a.Returned.Minute()
+			returns++
+		}
+	}
+	b.WriteString(fmt.Sprintf("🚶 Убытий: %d\n", len(list)))
+	b.WriteString(fmt.Sprintf("⏱ Всего вне части: %s\n", formatDuration(total)))
+	b.WriteString(fmt.Sprintf("⌛ В среднем за убытие: %s\n", formatDuration(total/time.Duration(len(list)))))
+	if returns > 0 {
+		avg := returnMinutes / returns
+		b.WriteString(fmt.Sprintf("🕕 Среднее время возвращения: %02d:%02d\n", avg/60, avg%60))
+	}
+	b.WriteString("\n📍 Частые локации:\n")
+	for _, l := range topLocations(list, 3) {
+		b.WriteString(fmt.Sprintf("— %s: %d\n", l.Name, l.Count))
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, b.String()))
+}
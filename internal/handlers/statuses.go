@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Длительные статусы: отпуск, командировка, наряд, болен ---
+//
+// Статус пишется в журнал как отдельное действие и действует до отметки
+// «Прибыл». В отличие от «Убыл» по статусу не шлются вечерние напоминания
+// (если не задано Remind), в выгрузке у каждого статуса свой цвет,
+// в сводке — свой раздел.
+
+type Status struct {
+	Code   string // для callback
+	Action string // значение колонки «Действие»
+	Emoji  string
+	Fill   string // цвет строки в Excel
+	Remind bool   // напоминать вечером, как при «Убыл»
+}
+
+var statuses = []Status{
+	{"vacation", "Отпуск", "🏖", "#FFF2CC", false},
+	{"trip", "Командировка", "🧳", "#DDEBF7", false},
+	{"duty", "Наряд", "🪖", "#E2EFDA", false},
+	{"sick", "Болен", "🏥", "#F8CBAD", false},
+}
+
+func findStatus(action string) (Status, bool) {
+	for _, s := range statuses {
+		if s.Action == action {
+			return s, true
+		}
+	}
+	return Status{}, false
+}
+
+func statusMenu() tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(statuses); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(statuses[i].Emoji+" "+statuses[i].Action, "status_"+statuses[i].Code),
+		}
+		if i+1 < len(statuses) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(statuses[i+1].Emoji+" "+statuses[i+1].Action, "status_"+statuses[i+1].Code))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// status_menu — выбор, status_<код> — установка
+func handleStatusAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	userID := query.From.ID
+	if query.Data == "status_menu" {
+		msg := tgbotapi.NewMessage(chatID, "📋 Выберите статус. Он действует до отметки «Прибыл».")
+		msg.ReplyMarkup = statusMenu()
+		bot.Send(msg)
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	code := strings.TrimPrefix(query.Data, "status_")
+	for _, s := range statuses {
+		if s.Code != code {
+			continue
+		}
+		if isDuplicateMark(ctx, userID, s.Action, "-", clock.Now()) {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+			return
+		}
+		if last, _ := getLastAction(ctx, userID); last == s.Action {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Этот статус уже установлен"))
+			return
+		}
+		name := getUserName(ctx, userID, query.From)
+		now := clock.Now().Format(dateFormat)
+		saveAttendance(ctx, now, strconv.Itoa(userID), name, s.Action, "-")
+		notifyAdminAboutMark(ctx, bot, userID, name, s.Action, "-", now)
+		bot.Send(markConfirmation(chatID, s.Emoji+" Статус «"+s.Action+"» установлен!", now))
+		sendMainMenu(ctx, bot, chatID, query.From)
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Записано!"))
+		return
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
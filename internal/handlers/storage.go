@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"tabel-go/internal/storage"
+)
+
+// --- Доступ к CSV-файлам ---
+//
+// Сам слой хранения — в internal/storage: блокировки, атомарная запись,
+// заголовки, шифрование. Здесь он подключается к приложению: таблицы
+// файлов (csvtable.go), ключ (dataenc.go) и сброс кэшей после записи.
+//
+// Каждая операция получает контекст вызова: апдейта, задачи планировщика
+// или HTTP-запроса. Если файл занят дольше, чем живёт контекст, операция
+// отказывается: чтение возвращает nil, запись не выполняется, и ошибка
+// попадает в лог.
+
+func init() {
+	storage.Tables = csvTableFor
+	storage.Key = dataKey
+	storage.OnWrite = invalidateCaches
+}
+
+// Ошибки записи storage пишет в лог сам; здесь — отказ по контексту
+func storageFailed(op, filename string, err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("%s %s: не дождались файла: %v", op, filename, err)
+	}
+}
+
+func readCSV(ctx context.Context, filename string) [][]string {
+	rows, err := storage.Read(ctx, filename)
+	storageFailed("readCSV", filename, err)
+	return rows
+}
+
+// Все строки, включая слишком короткие; для проверки данных
+func readCSVUnfiltered(ctx context.Context, filename string) [][]string {
+	rows, err := storage.ReadUnfiltered(ctx, filename)
+	storageFailed("readCSV", filename, err)
+	return rows
+}
+
+func writeCSV(ctx context.Context, filename string, rows [][]string) {
+	storageFailed("writeCSV", filename, storage.Write(ctx, filename, rows))
+}
+
+// Дописывает одну строку в конец файла, не переписывая остальное;
+// nil — строка уже на диске (после fsync)
+func appendCSV(ctx context.Context, filename string, row []string) error {
+	return storage.Append(ctx, filename, row)
+}
+
+// Чтение, изменение и запись файла под одной блокировкой
+func updateCSV(ctx context.Context, filename string, apply func(rows [][]string) [][]string) {
+	storageFailed("updateCSV", filename, storage.Update(ctx, filename, apply))
+}
+
+// Перенос части строк в другие файлы под блокировками всех участников
+func moveCSV(ctx context.Context, filename string, apply func(rows [][]string) (keep [][]string, moved map[string][][]string)) error {
+	return storage.Move(ctx, filename, apply)
+}
+
+// Чтение-изменение-запись нескольких файлов под их блокировками
+func updateCSVs(ctx context.Context, files []string, apply func(rows map[string][][]string) map[string][][]string) error {
+	return storage.UpdateAll(ctx, files, apply)
+}
+
+// Удаляет строки, для которых match вернул true; false — таких не было
+func removeRows(ctx context.Context, filename string, match func(row []string) bool) bool {
+	found := false
+	updateCSV(ctx, filename, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if len(row) > 0 && match(row) {
+				found = true
+				continue
+			}
+			keep = append(keep, row)
+		}
+		return keep
+	})
+	return found
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// --- Живая лента отметок /api/stream ---
+//
+// Server-Sent Events: каждая новая отметка сразу уходит всем подключённым
+// клиентам (веб-панель, монитор у дежурного). Доступ — область read;
+// EventSource не умеет заголовки, поэтому токен можно передать и в
+// адресе: /api/stream?token=…
+
+const streamKeepAlive = 25 * time.Second
+
+var (
+	streamMu      sync.Mutex
+	streamClients = make(map[chan apiMark]bool)
+)
+
+func init() {
+	handleHTTP("/api/stream", requireScope("read", serveMarkStream))
+}
+
+// Рассылка отметки; медленный клиент пропускает событие, а не тормозит запись
+func publishMark(row []string) {
+	if len(row) < 5 {
+		return
+	}
+	mark := apiMark{row[0], row[1], capitalizeName(row[2]), row[3], cleanLocation(row[4])}
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	for ch := range streamClients {
+		select {
+		case ch <- mark:
+		default:
+		}
+	}
+}
+
+func serveMarkStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan apiMark, 16)
+	streamMu.Lock()
+	streamClients[ch] = true
+	streamMu.Unlock()
+	defer func() {
+		streamMu.Lock()
+		delete(streamClients, ch)
+		streamMu.Unlock()
+	}()
+
+	// Поток живёт дольше WriteTimeout сервера
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			// Комментарий не даёт прокси закрыть простаивающее соединение
+			fmt.Fprint(w, ": ping\n\n")
+		case mark := <-ch:
+			data, _ := json.Marshal(mark)
+			fmt.Fprintf(w, "event: mark\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
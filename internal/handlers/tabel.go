@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/xuri/excelize/v2"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Месячный табель (Excel) ---
+//
+// Строки — личный состав, столбцы — дни месяца. «Я» — был в части в этот день,
+// для нерабочих дней без явки ставится обозначение из календаря (В, П),
+// для парковых дней — «ПД». Тип дня берётся из производственного календаря.
+
+func handleTabelCommand(ctx context.Context, bot Sender, chatID int64, args string) {
+	now := clock.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if args = strings.TrimSpace(args); args != "" {
+		t, err := time.ParseInLocation("01.2006", args, time.Local)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Формат: /tabel ММ.ГГГГ, например /tabel 03.2025"))
+			return
+		}
+		month = t
+	}
+	sendTabel(ctx, bot, chatID, month)
+}
+
+// Дни месяца, в которые пользователь был в части
+func presenceDays(rows [][]string, userID string, from, to time.Time) map[int]bool {
+	days := make(map[int]bool)
+	inside := false
+	lastDay := from
+	for _, row := range rows {
+		if len(row) < 5 || row[1] != userID {
+			continue
+		}
+		t, err := time.ParseInLocation(dateFormat, row[0], time.Local)
+		if err != nil || !t.Before(to) {
+			continue
+		}
+		if t.Before(from) {
+			inside = row[3] == "Прибыл"
+			continue
+		}
+		// Между отметками статус не меняется: отмечаем дни, проведённые в части
+		if inside {
+			for d := lastDay; !d.After(t); d = d.AddDate(0, 0, 1) {
+				days[d.Day()] = true
+			}
+		}
+		if row[3] == "Прибыл" {
+			days[t.Day()] = true
+		}
+		inside = row[3] == "Прибыл"
+		lastDay = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	if inside {
+		end := to
+		if now := clock.Now(); now.Before(end) {
+			end = now
+		}
+		for d := lastDay; d.Before(end); d = d.AddDate(0, 0, 1) {
+			days[d.Day()] = true
+		}
+	}
+	return days
+}
+
+func sendTabel(ctx context.Context, bot Sender, chatID int64, month time.Time) {
+	from := month
+	to := month.AddDate(0, 1, 0)
+	// Берём месяц раньше, чтобы знать статус на начало периода
+	rows := scopedRows(ctx, chatID, journal.ReadSince(ctx, from.AddDate(0, -1, 0)))
+	// Архивные попадают в табель, только если были в части в этом месяце
+	var users []User
+	for _, u := range getAllUsers(ctx) {
+		if !adminSeesUser(ctx, chatID, strconv.Itoa(u.ID)) {
+			continue
+		}
+		if !u.Archived || len(presenceDays(rows, strconv.Itoa(u.ID), from, to)) > 0 {
+			users = append(users, u)
+		}
+	}
+	if len(users) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Нет данных о личном составе."))
+		return
+	}
+	cal := loadWorkCalendar(ctx)
+	daysInMonth := to.AddDate(0, 0, -1).Day()
+
+	f := excelize.NewFile()
+	sheet := "Табель"
+	f.SetSheetName("Sheet1", sheet)
+	offStyle, _ := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1}})
+	parkStyle, _ := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFF2CC"}, Pattern: 1}})
+	f.SetCellValue(sheet, "A1", "ФИО")
+	f.SetCellValue(sheet, "A2", "Тип дня")
+	dayTypes := make([]DayType, daysInMonth+1)
+	for d := 1; d <= daysInMonth; d++ {
+		dt, _ := cal.Day(time.Date(month.Year(), month.Month(), d, 0, 0, 0, 0, time.Local))
+		dayTypes[d] = dt
+		col, _ := excelize.ColumnNumberToName(d + 1)
+		f.SetCellValue(sheet, col+"1", d)
+		f.SetCellValue(sheet, col+"2", dt.Short())
+	}
+	totalCol, _ := excelize.ColumnNumberToName(daysInMonth + 2)
+	f.SetCellValue(sheet, totalCol+"1", "Явок")
+	for i, u := range users {
+		r := i + 3
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", r), u.Name)
+		present := presenceDays(rows, strconv.Itoa(u.ID), from, to)
+		total := 0
+		for d := 1; d <= daysInMonth; d++ {
+			col, _ := excelize.ColumnNumberToName(d + 1)
+			cell := fmt.Sprintf("%s%d", col, r)
+			value := ""
+			if present[d] {
+				value = "Я"
+				total++
+			} else if dayTypes[d] == dayWeekend || dayTypes[d] == dayHoliday {
+				value = dayTypes[d].Short()
+			}
+			f.SetCellValue(sheet, cell, value)
+		}
+		f.SetCellValue(sheet, fmt.Sprintf("%s%d", totalCol, r), total)
+	}
+	last := len(users) + 2
+	for d := 1; d <= daysInMonth; d++ {
+		col, _ := excelize.ColumnNumberToName(d + 1)
+		switch dayTypes[d] {
+		case dayWeekend, dayHoliday:
+			f.SetCellStyle(sheet, col+"1", fmt.Sprintf("%s%d", col, last), offStyle)
+		case dayPark:
+			f.SetCellStyle(sheet, col+"1", fmt.Sprintf("%s%d", col, last), parkStyle)
+		}
+		f.SetColWidth(sheet, col, col, 4)
+	}
+	f.SetColWidth(sheet, "A", "A", 24)
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		log.Printf("tabel: %v", err)
+		bot.Send(tgbotapi.NewMessage(chatID, "Ошибка создания Excel файла"))
+		return
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("Табель_%s.xlsx", month.Format("2006-01")),
+		Bytes: buf.Bytes(),
+	})
+	doc.Caption = fmt.Sprintf("🗓 Табель за %s %d", strings.ToLower(monthNames[month.Month()-1]), month.Year())
+	bot.Send(doc)
+	incMetric("tabel_exports_total", `via="tabel"`)
+}
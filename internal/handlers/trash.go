@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Корзина ---
+//
+// Удалённые записи журнала и пользователи не стираются, а переносятся
+// в trash.csv: ID элемента, исходный файл, когда удалено, кто удалил,
+// затем сама строка. Из обычных списков они пропадают сразу. Элемент
+// может состоять из нескольких строк (пользователь вместе с админскими
+// правами и отметками) и восстанавливается или удаляется целиком.
+
+const (
+	trashFile     = "trash.csv"
+	trashPageSize = 8
+)
+
+func init() {
+	backupFiles = append(backupFiles, trashFile)
+}
+
+type trashItem struct {
+	ID        string
+	DeletedAt string
+	DeletedBy int
+	Rows      map[string][][]string // файл -> строки
+}
+
+func newTrashID() string {
+	return strconv.FormatInt(clock.Now().UnixNano(), 36)
+}
+
+func moveToTrash(ctx context.Context, itemID string, adminID int, file string, rows ...[]string) {
+	now := clock.Now().Format(dateFormat)
+	updateCSV(ctx, trashFile, func(trash [][]string) [][]string {
+		for _, row := range rows {
+			trash = append(trash, append([]string{itemID, file, now, strconv.Itoa(adminID)}, row...))
+		}
+		return trash
+	})
+}
+
+// Элементы корзины, новые первыми
+func trashItems(ctx context.Context) []trashItem {
+	byID := make(map[string]*trashItem)
+	var order []string
+	for _, row := range readCSV(ctx, trashFile) {
+		if len(row) < 5 {
+			continue
+		}
+		item, ok := byID[row[0]]
+		if !ok {
+			by, _ := strconv.Atoi(row[3])
+			item = &trashItem{ID: row[0], DeletedAt: row[2], DeletedBy: by, Rows: make(map[string][][]string)}
+			byID[row[0]] = item
+			order = append(order, row[0])
+		}
+		item.Rows[row[1]] = append(item.Rows[row[1]], row[4:])
+	}
+	var items []trashItem
+	for i := len(order) - 1; i >= 0; i-- {
+		items = append(items, *byID[order[i]])
+	}
+	return items
+}
+
+func isJournalFile(name string) bool {
+	return name == dataFile || journal.IsArchive(name)
+}
+
+func describeTrashItem(item trashItem) string {
+	if users := item.Rows[usersFile]; len(users) > 0 && len(users[0]) > 1 {
+		text := "👤 " + capitalizeName(users[0][1])
+		marks := 0
+		for file, rows := range item.Rows {
+			if isJournalFile(file) {
+				marks += len(rows)
+			}
+		}
+		if marks > 0 {
+			text += fmt.Sprintf(", отметок: %d", marks)
+		}
+		return text
+	}
+	for file, rows := range item.Rows {
+		if isJournalFile(file) && len(rows[0]) >= 5 {
+			r := rows[0]
+			return fmt.Sprintf("%s %s %s — %s", actionEmoji(r[3]), r[0], capitalizeName(r[2]), r[3])
+		}
+	}
+	return "❓ " + item.ID
+}
+
+func trashItemHasUser(item trashItem) bool {
+	return len(item.Rows[usersFile]) > 0
+}
+
+// Возвращает строки на место. Пользователь, успевший зарегистрироваться
+// заново, не дублируется.
+func restoreTrashItem(ctx context.Context, adminID int, item trashItem) {
+	for file, rows := range item.Rows {
+		updateCSV(ctx, file, func(current [][]string) [][]string {
+			for _, row := range rows {
+				if (file == usersFile || file == adminsFile) && rowWithID(current, row[0]) {
+					continue
+				}
+				current = append(current, row)
+			}
+			if isJournalFile(file) {
+				sortRowsByTime(current)
+			}
+			return current
+		})
+	}
+	removeTrashItem(ctx, item.ID)
+	writeAudit(ctx, adminID, "trash_restore", describeTrashItem(item))
+	refreshStatusBoard()
+}
+
+func rowWithID(rows [][]string, id string) bool {
+	for _, r := range rows {
+		if len(r) > 0 && r[0] == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTrashItem(ctx context.Context, itemID string) {
+	removeRows(ctx, trashFile, func(row []string) bool { return row[0] == itemID })
+}
+
+func findTrashItem(ctx context.Context, itemID string) (trashItem, bool) {
+	for _, item := range trashItems(ctx) {
+		if item.ID == itemID {
+			return item, true
+		}
+	}
+	return trashItem{}, false
+}
+
+// Пользователей в корзине видит только главный админ: удалять их может только он
+func visibleTrashItems(ctx context.Context, adminID int) []trashItem {
+	var items []trashItem
+	for _, item := range trashItems(ctx) {
+		if !trashItemHasUser(item) || isRootAdmin(ctx, adminID) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func sendTrash(ctx context.Context, bot Sender, chatID int64, adminID int, page int) {
+	items := visibleTrashItems(ctx, adminID)
+	if len(items) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "🗑 Корзина пуста."))
+		return
+	}
+	pages := (len(items) + trashPageSize - 1) / trashPageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	start := page * trashPageSize
+	end := start + trashPageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🗑 Корзина — стр. %d из %d\n\n", page+1, pages))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, item := range items[start:end] {
+		n := start + i + 1
+		b.WriteString(fmt.Sprintf("%d. %s\n   удалено %s, %s\n", n, describeTrashItem(item), item.DeletedAt, capitalizeName(getUserName(ctx, item.DeletedBy, nil))))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("↩️ %d", n), "trash_res_"+item.ID),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🧹 %d", n), "trash_del_"+item.ID),
+		))
+	}
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("trash_page_%d", page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("trash_page_%d", page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	if isRootAdmin(ctx, adminID) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🧹 Очистить корзину", "trash_empty"),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+// trash_page_<n>, trash_res_<ID>, trash_del_<ID>, trash_delok_<ID>,
+// trash_empty, trash_emptyok
+func handleTrashAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	adminID := query.From.ID
+	parts := strings.SplitN(query.Data, "_", 3)
+	if len(parts) == 2 && (parts[1] == "empty" || parts[1] == "emptyok") {
+		if !isRootAdmin(ctx, adminID) {
+			bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Только для главного админа"))
+			return
+		}
+		if parts[1] == "empty" {
+			msg := tgbotapi.NewMessage(chatID, "🧹 Удалить всё из корзины навсегда?")
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🧹 Да, навсегда", "trash_emptyok"),
+				tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+			))
+			bot.Send(msg)
+		} else {
+			writeCSV(ctx, trashFile, nil)
+			writeAudit(ctx, adminID, "trash_empty", "")
+			bot.Send(tgbotapi.NewMessage(chatID, "🧹 Корзина очищена."))
+		}
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	if len(parts) != 3 {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	if parts[1] == "page" {
+		page, _ := strconv.Atoi(parts[2])
+		sendTrash(ctx, bot, chatID, adminID, page)
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	item, ok := findTrashItem(ctx, parts[2])
+	if !ok {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Элемент уже восстановлен или удалён"))
+		return
+	}
+	if trashItemHasUser(item) && !isRootAdmin(ctx, adminID) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Только для главного админа"))
+		return
+	}
+	switch parts[1] {
+	case "res":
+		restoreTrashItem(ctx, adminID, item)
+		bot.Send(tgbotapi.NewMessage(chatID, "↩️ Восстановлено: "+describeTrashItem(item)))
+	case "del":
+		msg := tgbotapi.NewMessage(chatID, "🧹 Удалить навсегда: "+describeTrashItem(item)+"?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🧹 Да, навсегда", "trash_delok_"+item.ID),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+		))
+		bot.Send(msg)
+	case "delok":
+		removeTrashItem(ctx, item.ID)
+		writeAudit(ctx, adminID, "trash_purge", describeTrashItem(item))
+		bot.Send(tgbotapi.NewMessage(chatID, "🧹 Удалено навсегда."))
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Отмена ошибочной отметки ---
+
+const undoGracePeriod = 5 * time.Minute
+
+// Сообщение-подтверждение отметки с кнопкой отмены
+func markConfirmation(chatID int64, text, dt string) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, text)
+	t, err := time.ParseInLocation(dateFormat, dt, time.Local)
+	if err != nil {
+		return msg
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Отменить", fmt.Sprintf("undo_%d", t.Unix())),
+		commentButton(t.Unix()),
+	))
+	return msg
+}
+
+// Удаляет запись пользователя с указанным временем, если она последняя.
+// Отметка, сделанная перед сменой месяца, могла уже уехать в архив —
+// тогда ищем и там, как findRecord.
+func removeMark(ctx context.Context, userID int, dt string) (removed []string, ok bool) {
+	idStr := strconv.Itoa(userID)
+	files := []string{dataFile}
+	if t, err := time.ParseInLocation(dateFormat, dt, time.Local); err == nil {
+		now := clock.Now()
+		if t.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)) {
+			if _, err := os.Stat(journal.ArchiveFile(t)); err == nil {
+				files = append(files, journal.ArchiveFile(t))
+			}
+		}
+	}
+	updateCSVs(ctx, files, func(current map[string][][]string) map[string][][]string {
+		for _, f := range files {
+			rows := current[f]
+			for i := len(rows) - 1; i >= 0; i-- {
+				if len(rows[i]) < 5 || rows[i][1] != idStr {
+					continue
+				}
+				if rows[i][0] != dt {
+					// Отменить можно только последнюю отметку
+					return nil
+				}
+				removed, ok = rows[i], true
+				keep := append(append([][]string{}, rows[:i]...), rows[i+1:]...)
+				return map[string][][]string{f: keep}
+			}
+		}
+		return nil
+	})
+	if ok {
+		refreshStatusBoard()
+	}
+	return removed, ok
+}
+
+func handleUndoMark(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	userID := query.From.ID
+	chatID := query.Message.Chat.ID
+	ts, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "undo_"), 10, 64)
+	if err != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	markTime := time.Unix(ts, 0)
+	if clock.Now().Sub(markTime) > undoGracePeriod {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Время для отмены истекло"))
+		return
+	}
+	dt := markTime.Format(dateFormat)
+	removed, ok := removeMark(ctx, userID, dt)
+	if !ok {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Отметка уже изменена"))
+		return
+	}
+	writeAudit(ctx, userID, "undo_mark", strings.Join(removed, " | "))
+	bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+	bot.Send(tgbotapi.NewMessage(chatID, "↩️ Отметка отменена."))
+	notifyAdminAboutUndo(ctx, bot, userID, removed)
+	sendMainMenu(ctx, bot, chatID, query.From)
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Отменено"))
+}
+
+func notifyAdminAboutUndo(ctx context.Context, bot Sender, userID int, row []string) {
+	txt := fmt.Sprintf(
+		"↩️ <b>Отметка отменена</b>\n"+
+			"👤 <b>ФИО:</b> %s\n"+
+			"🆔 <b>ID:</b> %d\n"+
+			"⏰ <b>Время отметки:</b> %s\n"+
+			"⚡ <b>Действие:</b> %s %s",
+		row[2], userID, row[0], row[3], cleanLocation(row[4]))
+	for _, chatID := range adminRecipients(ctx, "notifications") {
+		sendAdminNotification(ctx, bot, chatID, txt)
+	}
+}
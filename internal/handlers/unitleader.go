@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Командир подразделения ---
+//
+// Командир видит сводку, журналы и выгрузки только по своему подразделению
+// и не получает прав админа. Назначается командой /units leader.
+
+// Название подразделения -> ID командира
+func unitLeaders(ctx context.Context) map[string]int {
+	leaders := make(map[string]int)
+	for _, row := range readCSV(ctx, unitsFile) {
+		if len(row) < 2 {
+			continue
+		}
+		if id, err := strconv.Atoi(row[1]); err == nil && id != 0 {
+			leaders[row[0]] = id
+		}
+	}
+	return leaders
+}
+
+// Подразделение, которым командует пользователь; пусто — не командир
+func leaderUnit(ctx context.Context, userID int) string {
+	for _, row := range readCSV(ctx, unitsFile) {
+		if len(row) > 1 && row[1] == strconv.Itoa(userID) {
+			return row[0]
+		}
+	}
+	return ""
+}
+
+// ID пользователей подразделения
+func unitMembers(ctx context.Context, unit string) map[string]bool {
+	members := make(map[string]bool)
+	for id, u := range userUnits(ctx) {
+		if u == unit {
+			members[id] = true
+		}
+	}
+	return members
+}
+
+// Может ли командир открыть этот callback, не имея прав админа.
+// Пока это только журнал человека из его подразделения.
+func leaderCanView(ctx context.Context, userID int, data string) bool {
+	if !strings.HasPrefix(data, "ujpage_") {
+		return false
+	}
+	unit := leaderUnit(ctx, userID)
+	if unit == "" {
+		return false
+	}
+	uid, _, _, ok := parseUserJournalCallback(data)
+	return ok && userUnits(ctx)[uid] == unit
+}
+
+// /units leader <название> <ID>; ID 0 снимает командира
+func handleUnitLeaderCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	i := strings.LastIndex(args, " ")
+	if i <= 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "Формат: /units leader 1 взвод <ID>"))
+		return
+	}
+	unit := strings.TrimSpace(args[:i])
+	leaderID, err := strconv.Atoi(strings.TrimSpace(args[i+1:]))
+	if err != nil || (leaderID != 0 && !isUserRegistered(ctx, leaderID)) {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Пользователь не найден среди зарегистрированных."))
+		return
+	}
+	found := false
+	oldLeader := 0
+	updateCSV(ctx, unitsFile, func(rows [][]string) [][]string {
+		for j, row := range rows {
+			if len(row) == 0 || row[0] != unit {
+				continue
+			}
+			found = true
+			if len(row) < 2 {
+				rows[j] = append(row, "")
+			}
+			oldLeader, _ = strconv.Atoi(rows[j][1])
+			rows[j][1] = ""
+			if leaderID != 0 {
+				rows[j][1] = strconv.Itoa(leaderID)
+			}
+		}
+		return rows
+	})
+	if !found {
+		bot.Send(tgbotapi.NewMessage(chatID, "❗ Подразделение не найдено."))
+		return
+	}
+	writeAudit(ctx, adminID, "set_unit_leader", fmt.Sprintf("%s: %d", unit, leaderID))
+	updateUserCommands(ctx, bot, oldLeader)
+	updateUserCommands(ctx, bot, leaderID)
+	if leaderID == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Командир подразделения «"+unit+"» снят."))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ %s — командир подразделения «%s».",
+		capitalizeName(getUserName(ctx, leaderID, nil)), unit)))
+	bot.Send(tgbotapi.NewMessage(int64(leaderID), "🏷 Вы назначены командиром подразделения «"+unit+"». Сводка и журналы — /unit"))
+}
+
+func sendLeaderMenu(ctx context.Context, bot Sender, chatID int64, userID int) {
+	unit := leaderUnit(ctx, userID)
+	if unit == "" {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, "🏷 "+unit)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Сводка", "lead_summary"),
+			tgbotapi.NewInlineKeyboardButtonData("📖 Журналы", "lead_people"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 Отчёт за 7 дней", "lead_export_7"),
+			tgbotapi.NewInlineKeyboardButtonData("📥 Отчёт за 30 дней", "lead_export_30"),
+		),
+	)
+	bot.Send(msg)
+}
+
+func handleLeaderAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	unit := leaderUnit(ctx, query.From.ID)
+	members := unitMembers(ctx, unit)
+	switch query.Data {
+	case "lead_menu":
+		sendLeaderMenu(ctx, bot, chatID, query.From.ID)
+	case "lead_summary":
+		bot.Send(tgbotapi.NewMessage(chatID, unitSummaryText(ctx, unit)))
+	case "lead_people":
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for _, u := range getSortedUsers(ctx) {
+			if members[strconv.Itoa(u.ID)] {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+					capitalizeName(u.Name), fmt.Sprintf("ujpage_%d_all_0", u.ID))))
+			}
+		}
+		if len(rows) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "В подразделении пока никого нет."))
+			break
+		}
+		msg := tgbotapi.NewMessage(chatID, "📖 Чей журнал открыть?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		bot.Send(msg)
+	case "lead_export_7", "lead_export_30":
+		days := 7
+		if query.Data == "lead_export_30" {
+			days = 30
+		}
+		inPeriod := filterLastNDays(days)
+		sendFilteredExcel(ctx, bot, chatID, daysAgo(days+1), func(row []string) bool {
+			return inPeriod(row) && members[row[1]]
+		})
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Подразделения (рота / взвод / отделение) ---
+//
+// Список подразделений — units.csv (название, ID командира), управляется
+// командой /units.
+// Подразделение пользователя — 5-я колонка users.csv, назначается
+// из карточки личного состава.
+
+const (
+	unitsFile   = "units.csv"
+	colUserUnit = 4
+	noUnit      = "Без подразделения"
+)
+
+func init() {
+	backupFiles = append(backupFiles, unitsFile)
+}
+
+func loadUnits(ctx context.Context) []string {
+	var units []string
+	for _, row := range readCSV(ctx, unitsFile) {
+		if len(row) > 0 && strings.TrimSpace(row[0]) != "" {
+			units = append(units, row[0])
+		}
+	}
+	return units
+}
+
+// ID пользователя -> подразделение
+func userUnits(ctx context.Context) map[string]string {
+	units := make(map[string]string)
+	for _, row := range loadUserRegistry(ctx).Rows {
+		if len(row) > colUserUnit && row[colUserUnit] != "" {
+			units[row[0]] = row[colUserUnit]
+		}
+	}
+	return units
+}
+
+func setUserUnit(ctx context.Context, userID int, unit string) bool {
+	if !setUserField(ctx, userID, colUserUnit, unit) {
+		return false
+	}
+	refreshStatusBoard()
+	return true
+}
+
+// Разбивка «в части / вне части» по подразделениям; пусто, если их нет
+func (s presenceSnapshot) unitBreakdown() string {
+	if len(s.Units) == 0 {
+		return ""
+	}
+	type counts struct{ In, Out int }
+	byUnit := make(map[string]*counts)
+	for _, u := range s.Users {
+		row := s.Last[strconv.Itoa(u.ID)]
+		if row == nil {
+			continue
+		}
+		name := s.Units[strconv.Itoa(u.ID)]
+		if name == "" {
+			name = noUnit
+		}
+		c, ok := byUnit[name]
+		if !ok {
+			c = &counts{}
+			byUnit[name] = c
+		}
+		if row[3] == "Прибыл" {
+			c.In++
+		} else {
+			c.Out++
+		}
+	}
+	var names []string
+	for name := range byUnit {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("\n🏷 По подразделениям:\n")
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf("— %s: 🟢 %d / 🔴 %d\n", name, byUnit[name].In, byUnit[name].Out))
+	}
+	return b.String()
+}
+
+// /units — список, /units add <название>, /units del <название>
+func handleUnitsCommand(ctx context.Context, bot Sender, chatID int64, adminID int, args string) {
+	args = strings.TrimSpace(args)
+	cmd, name := args, ""
+	if i := strings.Index(args, " "); i > 0 {
+		cmd, name = args[:i], strings.TrimSpace(args[i+1:])
+	}
+	units := loadUnits(ctx)
+	switch {
+	case cmd == "add" && name != "":
+		for _, u := range units {
+			if strings.EqualFold(u, name) {
+				bot.Send(tgbotapi.NewMessage(chatID, "❗ Такое подразделение уже есть."))
+				return
+			}
+		}
+		appendCSV(ctx, unitsFile, []string{name})
+		writeAudit(ctx, adminID, "add_unit", name)
+		bot.Send(tgbotapi.NewMessage(chatID, "✅ Добавлено: "+name))
+	case cmd == "leader" && name != "":
+		handleUnitLeaderCommand(ctx, bot, chatID, adminID, name)
+	case cmd == "report":
+		handleUnitReportCommand(ctx, bot, chatID, adminID, name)
+	case cmd == "del" && name != "":
+		if !removeRows(ctx, unitsFile, func(row []string) bool { return row[0] == name }) {
+			bot.Send(tgbotapi.NewMessage(chatID, "❗ Подразделение не найдено."))
+			return
+		}
+		if unitReportSpec(ctx, name) != "" {
+			setUnitReportSpec(ctx, name, "")
+		}
+		writeAudit(ctx, adminID, "del_unit", name)
+		bot.Send(tgbotapi.NewMessage(chatID, "🗑 Удалено: "+name+". У людей из него подразделение сохранено, переназначьте их в карточке."))
+	default:
+		text := "🏷 Подразделения:\n"
+		if len(units) == 0 {
+			text += "пока нет\n"
+		}
+		leaders := unitLeaders(ctx)
+		for _, u := range units {
+			text += "— " + u
+			if id := leaders[u]; id != 0 {
+				text += " (командир: " + capitalizeName(getUserName(ctx, id, nil)) + ")"
+			}
+			if spec := unitReportSpec(ctx, u); spec != "" {
+				text += " [сводка: " + spec + "]"
+			}
+			text += "\n"
+		}
+		text += "\nДобавить: /units add 1 взвод\nУдалить: /units del 1 взвод\nНазначить командира: /units leader 1 взвод <ID> (0 — снять)\nСвоё время сводки: /units report 1 взвод = 0 20 * * 1-5 (off — общее)"
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+	}
+}
+
+// uunit_<ID> — выбор подразделения, uunitset_<номер>_<ID> — назначение (-1 — без подразделения)
+func handleUnitAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, "_")
+	uid, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	units := loadUnits(ctx)
+	switch {
+	case parts[0] == "uunit":
+		if len(units) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "Подразделений пока нет. Добавьте: /units add 1 взвод"))
+			break
+		}
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for i, u := range units {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(u, fmt.Sprintf("uunitset_%d_%d", i, uid)),
+			))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➖ "+noUnit, fmt.Sprintf("uunitset_-1_%d", uid)),
+		))
+		msg := tgbotapi.NewMessage(chatID, "🏷 Подразделение для "+capitalizeName(getUserName(ctx, uid, nil))+":")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		bot.Send(msg)
+	case parts[0] == "uunitset" && len(parts) == 3:
+		idx, _ := strconv.Atoi(parts[1])
+		unit := ""
+		if idx >= 0 && idx < len(units) {
+			unit = units[idx]
+		}
+		if setUserUnit(ctx, uid, unit) {
+			writeAudit(ctx, query.From.ID, "set_unit", fmt.Sprintf("%d: %s", uid, unit))
+			if unit == "" {
+				unit = noUnit
+			}
+			bot.Send(tgbotapi.NewMessage(chatID, "✅ "+capitalizeName(getUserName(ctx, uid, nil))+": "+unit))
+		}
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
+
+// --- Сводка и выгрузка по подразделению ---
+
+func findUnit(ctx context.Context, name string) string {
+	for _, u := range loadUnits(ctx) {
+		if strings.EqualFold(u, strings.TrimSpace(name)) {
+			return u
+		}
+	}
+	return ""
+}
+
+func unitSummaryText(ctx context.Context, unit string) string {
+	return unitSummaryAt(ctx, unit, clock.Now())
+}
+
+// Сводка подразделения на момент at (lastRowsAt)
+func unitSummaryAt(ctx context.Context, unit string, at time.Time) string {
+	s := loadPresenceAt(ctx, at)
+	return "🏷 " + unit + "\n\n" + s.text(func(id string) bool { return s.Units[id] == unit })
+}
+
+// Кнопки выбора подразделения: <prefix><номер>
+func unitPickerRows(ctx context.Context, prefix string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, u := range loadUnits(ctx) {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(u, fmt.Sprintf("%s%d", prefix, i)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// usum_<номер> — сводка; uexp — выбор подразделения, uexp_<номер> — выбор
+// периода, uexp_<номер>_<дней> — выгрузка (0 — сегодня)
+func handleUnitReportAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	units := loadUnits(ctx)
+	parts := strings.Split(query.Data, "_")
+	idx := -1
+	if len(parts) > 1 {
+		idx, _ = strconv.Atoi(parts[1])
+	}
+	if len(parts) > 1 && (idx < 0 || idx >= len(units)) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Подразделение не найдено"))
+		return
+	}
+	if idx >= 0 && !adminSeesUnit(ctx, query.From.ID, units[idx]) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "⛔ Недостаточно прав"))
+		return
+	}
+	switch {
+	case parts[0] == "usum" && len(parts) == 2:
+		bot.Send(tgbotapi.NewMessage(chatID, unitSummaryText(ctx, units[idx])))
+	case parts[0] == "uexp" && len(parts) == 1:
+		if len(units) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, "Подразделений пока нет. Добавьте: /units add 1 взвод"))
+			break
+		}
+		msg := tgbotapi.NewMessage(chatID, "🏷 Выберите подразделение:")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(unitPickerRows(ctx, "uexp_")...)
+		bot.Send(msg)
+	case parts[0] == "uexp" && len(parts) == 2:
+		msg := tgbotapi.NewMessage(chatID, "🏷 "+units[idx]+": период отчёта?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Сегодня", fmt.Sprintf("uexp_%d_0", idx)),
+			tgbotapi.NewInlineKeyboardButtonData("🗓️ 7 дней", fmt.Sprintf("uexp_%d_7", idx)),
+			tgbotapi.NewInlineKeyboardButtonData("🗓️ 30 дней", fmt.Sprintf("uexp_%d_30", idx)),
+		))
+		bot.Send(msg)
+	case parts[0] == "uexp" && len(parts) == 3:
+		days, _ := strconv.Atoi(parts[2])
+		inPeriod := filterToday
+		if days > 0 {
+			inPeriod = filterLastNDays(days)
+		}
+		members := unitMembers(ctx, units[idx])
+		sendFilteredExcel(ctx, bot, chatID, daysAgo(days+1), func(row []string) bool {
+			return inPeriod(row) && members[row[1]]
+		})
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
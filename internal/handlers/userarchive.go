@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Архив личного состава ---
+//
+// Переведённые и уволенные не удаляются: в users.csv ставится флаг (4-я
+// колонка), человек пропадает из сводок, напоминаний и списка ЛС, а его
+// отметки остаются в журнале и выгрузках.
+
+const colUserArchived = 3
+
+func setUserArchived(ctx context.Context, userID int, archived bool) bool {
+	value := ""
+	if archived {
+		value = "1"
+	}
+	if !setUserField(ctx, userID, colUserArchived, value) {
+		return false
+	}
+	refreshStatusBoard()
+	return true
+}
+
+// /archived — список архивных с кнопками возврата
+func sendArchivedUsers(ctx context.Context, bot Sender, chatID int64) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var b strings.Builder
+	for _, u := range getAllUsers(ctx) {
+		if !u.Archived {
+			continue
+		}
+		b.WriteString("— " + u.Name + "\n")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("♻️ Вернуть: "+u.Name, fmt.Sprintf("uunarch_%d", u.ID)),
+		))
+	}
+	if len(rows) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, "🗄 Архив пуст."))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🗄 В архиве (%d):\n%s", len(rows), b.String()))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+func handleUserArchiveAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	adminID := query.From.ID
+	chatID := query.Message.Chat.ID
+	if !isAdminWithRight(ctx, adminID, "manage_users") {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Нет прав"))
+		return
+	}
+	parts := strings.SplitN(query.Data, "_", 2)
+	uid, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || uid == rootAdminID(ctx) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	name := capitalizeName(getUserName(ctx, uid, nil))
+	switch parts[0] {
+	case "uarch":
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🗄 Перевести %s в архив?\nИстория отметок сохранится, из сводок и напоминаний он пропадёт.", name))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ В архив", fmt.Sprintf("uarchok_%d", uid)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+		))
+		bot.Send(msg)
+	case "uarchok":
+		if setUserArchived(ctx, uid, true) {
+			writeAudit(ctx, adminID, "archive_user", fmt.Sprintf("%d %s", uid, name))
+			bot.Send(tgbotapi.NewMessage(chatID, "🗄 "+name+" переведён в архив. Вернуть: /archived"))
+		}
+	case "uunarch":
+		if setUserArchived(ctx, uid, false) {
+			writeAudit(ctx, adminID, "unarchive_user", fmt.Sprintf("%d %s", uid, name))
+			bot.Send(tgbotapi.NewMessage(chatID, "♻️ "+name+" снова в списке личного состава."))
+		}
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
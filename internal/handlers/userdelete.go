@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/journal"
+)
+
+// --- Удаление пользователя (только главный админ) ---
+//
+// Переносит пользователя из users.csv и admins.csv в корзину. Отметки можно
+// оставить, удалить (тоже в корзину) или обезличить безвозвратно — во всех
+// архивах. Нужны два подтверждения.
+
+const (
+	deleteKeep      = "keep"
+	deletePurge     = "purge"
+	deleteAnonymize = "anon"
+	anonymizedName  = "Удалённый пользователь"
+)
+
+var deleteModeNames = map[string]string{
+	deleteKeep:      "Вернуть можно из корзины (/trash), отметки сохранятся",
+	deletePurge:     "Вернуть можно из корзины (/trash), отметки уйдут туда вместе с ним",
+	deleteAnonymize: "Это необратимо: отметки будут обезличены",
+}
+
+func removeUserRows(ctx context.Context, filename string, userID int) {
+	idStr := strconv.Itoa(userID)
+	updateCSV(ctx, filename, func(rows [][]string) [][]string {
+		var keep [][]string
+		for _, row := range rows {
+			if len(row) > 0 && row[0] == idStr {
+				continue
+			}
+			keep = append(keep, row)
+		}
+		return keep
+	})
+}
+
+// Удаляет (в корзину под trashID) или обезличивает отметки пользователя;
+// возвращает число затронутых строк
+func eraseAttendance(ctx context.Context, userID int, mode string, adminID int, trashID string) int {
+	idStr := strconv.Itoa(userID)
+	count := 0
+	for _, file := range append(journal.Archives(), dataFile) {
+		var removed [][]string
+		updateCSV(ctx, file, func(rows [][]string) [][]string {
+			var out [][]string
+			for _, row := range rows {
+				if len(row) < 5 || row[1] != idStr {
+					out = append(out, row)
+					continue
+				}
+				count++
+				if mode == deleteAnonymize {
+					row[1] = "0"
+					row[2] = anonymizedName
+					out = append(out, row)
+				} else {
+					removed = append(removed, row)
+				}
+			}
+			return out
+		})
+		if len(removed) > 0 {
+			moveToTrash(ctx, trashID, adminID, file, removed...)
+		}
+	}
+	return count
+}
+
+func deleteUser(ctx context.Context, adminID, userID int, mode string) int {
+	name := getUserName(ctx, userID, nil)
+	trashID := newTrashID()
+	for _, file := range []string{usersFile, adminsFile} {
+		// Обезличивание — просьба стереть данные, в корзине ФИО не оставляем
+		for _, row := range readCSV(ctx, file) {
+			if mode != deleteAnonymize && len(row) > 0 && row[0] == strconv.Itoa(userID) {
+				moveToTrash(ctx, trashID, adminID, file, row)
+			}
+		}
+		removeUserRows(ctx, file, userID)
+	}
+	affected := 0
+	if mode != deleteKeep {
+		affected = eraseAttendance(ctx, userID, mode, adminID, trashID)
+	}
+	// В журнал аудита ФИО не пишем, если человек просил удалить данные
+	details := fmt.Sprintf("%d %s, строк: %d", userID, mode, affected)
+	if mode == deleteKeep {
+		details = fmt.Sprintf("%d %s (%s)", userID, mode, name)
+	}
+	writeAudit(ctx, adminID, "delete_user", details)
+	refreshStatusBoard()
+	return affected
+}
+
+func handleUserDeleteAction(ctx context.Context, bot Sender, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	if !isRootAdmin(ctx, query.From.ID) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Только для главного админа"))
+		return
+	}
+	// udel_<uid>, udelm_<mode>_<uid>, udelok_<mode>_<uid>
+	parts := strings.Split(query.Data, "_")
+	uid, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil || uid == rootAdminID(ctx) || !isUserRegistered(ctx, uid) {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Пользователь не найден"))
+		return
+	}
+	name := capitalizeName(getUserName(ctx, uid, nil))
+	switch {
+	case parts[0] == "udel" && len(parts) == 2:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🗑 Удаление %s.\nЧто сделать с его отметками?", name))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📂 Оставить", fmt.Sprintf("udelm_%s_%d", deleteKeep, uid))),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("👤 Обезличить", fmt.Sprintf("udelm_%s_%d", deleteAnonymize, uid))),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🧹 Удалить все", fmt.Sprintf("udelm_%s_%d", deletePurge, uid))),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop")),
+		)
+		bot.Send(msg)
+	case parts[0] == "udelm" && len(parts) == 3 && deleteModeNames[parts[1]] != "":
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("⚠️ Точно удалить %s? %s.", name, deleteModeNames[parts[1]]))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Да, удалить", fmt.Sprintf("udelok_%s_%d", parts[1], uid)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "noop"),
+		))
+		bot.Send(msg)
+	case parts[0] == "udelok" && len(parts) == 3 && deleteModeNames[parts[1]] != "":
+		affected := deleteUser(ctx, query.From.ID, uid, parts[1])
+		text := fmt.Sprintf("🗑 %s перенесён в корзину.", name)
+		if parts[1] == deleteAnonymize {
+			text = fmt.Sprintf("🗑 %s удалён.", name)
+		}
+		if affected > 0 {
+			text += fmt.Sprintf(" Затронуто отметок: %d.", affected)
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, text))
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// --- Версия сборки ---
+//
+// Задаётся при сборке (переменные — в пакете internal/handlers):
+//
+//	go build -ldflags "-X tabel-go/internal/handlers.buildVersion=1.4.0 -X tabel-go/internal/handlers.buildCommit=$(git rev-parse --short HEAD) -X tabel-go/internal/handlers.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Если коммит не задан, берётся RENDER_GIT_COMMIT или данные VCS,
+// которые Go сам встраивает при сборке из git-репозитория.
+
+var (
+	buildVersion = "dev"
+	buildCommit  = ""
+	buildTime    = ""
+)
+
+var startedAt = clock.Now()
+
+func commitHash() string {
+	if buildCommit != "" {
+		return buildCommit
+	}
+	if c := os.Getenv("RENDER_GIT_COMMIT"); c != "" {
+		return c
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+func shortCommit() string {
+	c := commitHash()
+	if len(c) > 7 {
+		return c[:7]
+	}
+	return c
+}
+
+// Одной строкой: для логов, keep-alive и сообщения о запуске
+func versionString() string {
+	return buildVersion + " (" + shortCommit() + ")"
+}
+
+func sendVersion(ctx context.Context, bot Sender, chatID int64) {
+	built := buildTime
+	if built == "" {
+		built = "неизвестно"
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("🏷 Версия: %s\nКоммит: %s\nСобрано: %s\nGo: %s\nЗапущен: %s\nФормат данных: v%d",
+		buildVersion, commitHash(), built, runtime.Version(), startedAt.Format(dateFormat), schemaVersion(ctx))))
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"tabel-go/internal/validate"
+)
+
+// --- Telegram Mini App для отметок ---
+//
+// Страница /app отдаётся тем же HTTP-сервером, что и keep-alive. Запросы
+// к /api/* подписаны initData из Telegram, подпись проверяется по токену
+// бота. Кнопка меню чата включается, если задан WEBAPP_URL (https-адрес /app).
+
+const webAppInitDataTTL = 24 * time.Hour
+
+// Бот для уведомлений из HTTP-обработчиков; задаётся в Run
+var webAppBot Sender
+
+func init() {
+	handleHTTP("/app", serveWebApp)
+	handleHTTP("/api/state", webAppState)
+	handleHTTP("/api/mark", webAppMark)
+}
+
+// Кнопка «Отметиться» в меню всех чатов с ботом
+func setupWebAppMenuButton(bot Sender) {
+	webAppBot = bot
+	appURL := os.Getenv("WEBAPP_URL")
+	if appURL == "" {
+		return
+	}
+	params := tgbotapi.Params{}
+	params.AddInterface("menu_button", map[string]interface{}{
+		"type":    "web_app",
+		"text":    "Отметиться",
+		"web_app": map[string]string{"url": appURL},
+	})
+	if _, err := bot.MakeRequest("setChatMenuButton", params); err != nil {
+		log.Printf("webapp: не удалось установить кнопку меню: %v", err)
+	}
+}
+
+// Пользователь запроса; при ошибке ответ уже отправлен
+func webAppUser(w http.ResponseWriter, r *http.Request) (int, bool) {
+	ctx := r.Context()
+	userID, ok := validate.InitData(r.Header.Get("X-Init-Data"), botToken, clock.Now(), webAppInitDataTTL)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	if !isUserRegistered(ctx, userID) || isBanned(ctx, userID) {
+		http.Error(w, "not registered", http.StatusForbidden)
+		return 0, false
+	}
+	return userID, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+type webAppHistoryEntry struct {
+	Time     string `json:"time"`
+	Action   string `json:"action"`
+	Location string `json:"location"`
+}
+
+func webAppState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := webAppUser(w, r)
+	if !ok {
+		return
+	}
+	action, location := getLastAction(ctx, userID)
+	var history []webAppHistoryEntry
+	for _, e := range getUserHistory(ctx, strconv.Itoa(userID), journalSince("month")) {
+		history = append(history, webAppHistoryEntry{e[0], actionEmoji(e[3]) + " " + e[3], cleanLocation(e[4])})
+	}
+	// Локации с обязательным фото в Mini App не предлагаются: фото
+	// принимается только в чате
+	var locations []string
+	for _, loc := range leaveLocations {
+		if loc != "📝 Другое" && !photoRequired(ctx, loc) {
+			locations = append(locations, loc)
+		}
+	}
+	writeJSON(w, map[string]interface{}{
+		"name":      capitalizeName(getUserName(ctx, userID, nil)),
+		"action":    action,
+		"location":  cleanLocation(location),
+		"locations": locations,
+		"history":   history,
+	})
+}
+
+func webAppMark(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := webAppUser(w, r)
+	if !ok {
+		return
+	}
+	if !allowAction(userID, clock.Now()) {
+		writeJSON(w, map[string]string{"error": "Не так быстро, попробуйте через минуту"})
+		return
+	}
+	var req struct {
+		Action   string `json:"action"`
+		Location string `json:"location"`
+	}
+	if json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	location := "-"
+	if req.Action == "Убыл" {
+		location = strings.TrimSpace(req.Location)
+	}
+	if isDuplicateMark(ctx, userID, req.Action, location, clock.Now()) {
+		writeJSON(w, map[string]string{"ok": "уже записано"})
+		return
+	}
+	last, _ := getLastAction(ctx, userID)
+	switch req.Action {
+	case "Прибыл":
+		if last == "Прибыл" {
+			writeJSON(w, map[string]string{"error": "Сначала отметьте убытие"})
+			return
+		}
+		if geoRequired(ctx) {
+			writeJSON(w, map[string]string{"error": "Прибытие отмечается с геопозицией — через кнопку в чате"})
+			return
+		}
+	case "Убыл":
+		if last == "Убыл" {
+			writeJSON(w, map[string]string{"error": "Сначала отметьте прибытие"})
+			return
+		}
+		if len([]rune(location)) < 3 {
+			writeJSON(w, map[string]string{"error": "Укажите локацию"})
+			return
+		}
+		if photoRequired(ctx, location) {
+			writeJSON(w, map[string]string{"error": "Для этой локации нужно фото — отметьте убытие через кнопку в чате"})
+			return
+		}
+	default:
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	now := clock.Now().Format(dateFormat)
+	name := getUserName(ctx, userID, nil)
+	saveAttendance(ctx, now, strconv.Itoa(userID), name, req.Action, location)
+	if webAppBot != nil {
+		notifyAdminAboutMark(ctx, webAppBot, userID, name, req.Action, location, now)
+	}
+	writeJSON(w, map[string]string{"ok": now})
+}
+
+func serveWebApp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(webAppPage))
+}
+
+const webAppPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<script src="https://telegram.org/js/telegram-web-app.js"></script>
+<style>
+body { font-family: sans-serif; margin: 0; padding: 16px; background: var(--tg-theme-bg-color, #fff); color: var(--tg-theme-text-color, #000); }
+.big { display: block; width: 100%; padding: 20px; margin: 8px 0; font-size: 22px; border: 0; border-radius: 12px; color: #fff; }
+#in { background: #2e7d32; } #out { background: #c62828; }
+input { width: 100%; padding: 10px; font-size: 16px; box-sizing: border-box; margin: 8px 0; }
+.loc { padding: 10px; border-bottom: 1px solid #ddd; cursor: pointer; }
+.loc.sel { background: var(--tg-theme-secondary-bg-color, #eee); font-weight: bold; }
+.hist { font-size: 14px; padding: 6px 0; border-bottom: 1px solid #eee; }
+#msg { min-height: 20px; margin: 8px 0; }
+</style>
+</head>
+<body>
+<h3 id="title">Загрузка…</h3>
+<div id="status"></div>
+<div id="msg"></div>
+<button class="big" id="in">🟢 Прибыл</button>
+<input id="search" placeholder="🔍 Куда убываете? Поиск или свой вариант">
+<div id="locs"></div>
+<button class="big" id="out">🔴 Убыл</button>
+<h4>📖 История за 30 дней</h4>
+<div id="history"></div>
+<script>
+const tg = window.Telegram.WebApp;
+tg.ready();
+let locations = [], selected = "";
+function api(path, body) {
+  return fetch(path, {
+    method: body ? "POST" : "GET",
+    headers: {"X-Init-Data": tg.initData, "Content-Type": "application/json"},
+    body: body ? JSON.stringify(body) : undefined
+  }).then(r => r.ok ? r.json() : Promise.reject(r.status));
+}
+function renderLocs() {
+  const q = document.getElementById("search").value.toLowerCase();
+  const box = document.getElementById("locs");
+  box.innerHTML = "";
+  locations.filter(l => l.toLowerCase().includes(q)).forEach(l => {
+    const d = document.createElement("div");
+    d.className = "loc" + (l === selected ? " sel" : "");
+    d.textContent = l;
+    d.onclick = () => { selected = l; document.getElementById("search").value = ""; renderLocs(); };
+    box.appendChild(d);
+  });
+}
+function load() {
+  api("api/state").then(s => {
+    document.getElementById("title").textContent = s.name;
+    document.getElementById("status").textContent = s.action ? "Сейчас: " + s.action + (s.action === "Убыл" ? " (" + s.location + ")" : "") : "Отметок ещё нет";
+    locations = s.locations || [];
+    renderLocs();
+    const h = document.getElementById("history");
+    h.innerHTML = "";
+    (s.history || []).forEach(e => {
+      const d = document.createElement("div");
+      d.className = "hist";
+      d.textContent = e.time + " — " + e.action + (e.location && e.location !== "-" ? ", " + e.location : "");
+      h.appendChild(d);
+    });
+  }).catch(() => { document.getElementById("title").textContent = "Откройте приложение из чата с ботом"; });
+}
+function mark(action) {
+  const typed = document.getElementById("search").value.trim();
+  api("api/mark", {action: action, location: selected || typed}).then(r => {
+    document.getElementById("msg").textContent = r.error ? "❗ " + r.error : "✅ Записано: " + r.ok;
+    if (!r.error) { selected = ""; tg.HapticFeedback.notificationOccurred("success"); load(); }
+  });
+}
+document.getElementById("in").onclick = () => mark("Прибыл");
+document.getElementById("out").onclick = () => mark("Убыл");
+document.getElementById("search").oninput = renderLocs;
+load();
+</script>
+</body>
+</html>
+`
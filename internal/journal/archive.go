@@ -0,0 +1,127 @@
+package journal
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tabel-go/internal/storage"
+)
+
+// --- Журнал по месяцам ---
+//
+// Журнал разбит на месячные файлы: attendance.csv — текущий месяц,
+// attendance_YYYY-MM.csv — прошлые. Новые отметки только дописываются в
+// конец текущего файла. В начале месяца (и при первой отметке нового
+// месяца, если бот был выключен) записи прошлых месяцев переносятся в свои
+// файлы. Выборки за период читают только пересекающиеся с ним месяцы.
+
+const archiveMonthLayout = "2006-01"
+
+func ArchiveFile(month time.Time) string {
+	return "attendance_" + month.Format(archiveMonthLayout) + ".csv"
+}
+
+// Архивы, отсортированные от старых к новым
+func Archives() []string {
+	files, _ := filepath.Glob("attendance_*.csv")
+	var valid []string
+	for _, f := range files {
+		if _, ok := ArchiveMonth(f); ok {
+			valid = append(valid, f)
+		}
+	}
+	sort.Strings(valid)
+	return valid
+}
+
+func ArchiveMonth(filename string) (time.Time, bool) {
+	s := strings.TrimSuffix(strings.TrimPrefix(filename, "attendance_"), ".csv")
+	t, err := time.ParseInLocation(archiveMonthLayout, s, time.Local)
+	return t, err == nil
+}
+
+// shardMu держится на всё время разбора: две ротации подряд (первая
+// отметка месяца и задача archive) не должны перенести одни строки дважды.
+var (
+	shardMu    storage.RWLock
+	shardMonth string // месяц, для которого рабочий файл уже разобран
+)
+
+// Перед записью отметки: если наступил новый месяц, сначала разложить
+// рабочий файл по архивам
+func Rotate(ctx context.Context, now time.Time) {
+	// Не дождались — месяц не разобран, попробует следующая отметка
+	if err := shardMu.Lock(ctx); err != nil {
+		log.Printf("archive: %v", err)
+		return
+	}
+	defer shardMu.Unlock()
+	if shardMonth != now.Format(archiveMonthLayout) {
+		archiveLocked(ctx, now)
+	}
+}
+
+// Переносит записи до начала текущего месяца в помесячные архивы
+func Archive(ctx context.Context, now time.Time) {
+	if err := shardMu.Lock(ctx); err != nil {
+		log.Printf("archive: %v", err)
+		return
+	}
+	defer shardMu.Unlock()
+	archiveLocked(ctx, now)
+}
+
+// Вызывается под shardMu. Рабочий файл заблокирован на весь перенос, так
+// что отметка, пришедшая во время разбора, не теряется. При ошибке записи
+// месяц не считается разобранным — следующая отметка попробует снова.
+func archiveLocked(ctx context.Context, now time.Time) {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	byMonth := make(map[string][][]string)
+	err := storage.Move(ctx, File, func(rows [][]string) ([][]string, map[string][][]string) {
+		var keep [][]string
+		for _, row := range rows {
+			if len(row) == 0 {
+				continue
+			}
+			t, err := time.ParseInLocation(DateFormat, row[0], now.Location())
+			if err != nil || !t.Before(monthStart) {
+				keep = append(keep, row)
+				continue
+			}
+			name := ArchiveFile(t)
+			byMonth[name] = append(byMonth[name], row)
+		}
+		return keep, byMonth
+	})
+	if err != nil {
+		log.Printf("archive: %v", err)
+		return
+	}
+	for name, monthRows := range byMonth {
+		log.Printf("archive: %d записей перенесено в %s", len(monthRows), name)
+	}
+	shardMonth = now.Format(archiveMonthLayout)
+}
+
+// Все записи начиная с месяца since: нужные архивы + рабочий файл
+func ReadSince(ctx context.Context, since time.Time) [][]string {
+	sinceMonth := time.Date(since.Year(), since.Month(), 1, 0, 0, 0, 0, time.Local)
+	var rows [][]string
+	for _, f := range Archives() {
+		m, _ := ArchiveMonth(f)
+		if m.Before(sinceMonth) {
+			continue
+		}
+		rows = append(rows, read(ctx, f)...)
+	}
+	return append(rows, read(ctx, File)...)
+}
+
+func IsArchive(name string) bool {
+	_, ok := ArchiveMonth(name)
+	return ok && name == filepath.Base(name) && strings.HasPrefix(name, "attendance_")
+}
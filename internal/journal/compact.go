@@ -0,0 +1,158 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tabel-go/internal/storage"
+)
+
+// --- Чистка журнала ---
+//
+// Переписывает все месячные файлы журнала: выбрасывает битые строки,
+// убирает двойные нажатия (та же отметка того же человека в пределах
+// DebounceWindow), приводит даты к DateFormat, раскладывает записи по
+// своим месяцам и сортирует по времени. Без apply только считает, что
+// изменится, — это показывает /compact до подтверждения.
+
+// Форматы дат, встречающиеся в старых файлах
+var journalDateLayouts = []string{
+	DateFormat,
+	"02.01.2006 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+type CompactStats struct {
+	Files      int
+	Rows       int
+	Kept       int
+	Malformed  int
+	Duplicates int
+	Normalized int
+	Moved      int
+}
+
+// Время отметки в любом из встречавшихся форматов; normalized — формат
+// не DateFormat
+func ParseTime(s string) (t time.Time, normalized bool, ok bool) {
+	s = strings.TrimSpace(s)
+	for i, layout := range journalDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, i > 0, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+type compactRow struct {
+	Row  []string
+	Time time.Time
+	From string
+}
+
+// apply == false — только подсчёт. Чистка идёт под Lock и блокировками
+// всех файлов журнала: отметка, пришедшая во время неё, не потеряется.
+func Compact(ctx context.Context, now time.Time, apply bool) CompactStats {
+	files := append([]string{File}, Archives()...)
+	if !apply {
+		rows := make(map[string][][]string)
+		for _, f := range files {
+			rows[f] = read(ctx, f)
+		}
+		st, _ := compactPlan(now, files, rows)
+		return st
+	}
+	var st CompactStats
+	unlock, err := Lock(ctx)
+	if err != nil {
+		log.Printf("compact: %v", err)
+		return st
+	}
+	defer unlock()
+	err = storage.UpdateAll(ctx, files, func(rows map[string][][]string) map[string][][]string {
+		var byFile map[string][][]string
+		st, byFile = compactPlan(now, files, rows)
+		for _, f := range files {
+			if _, ok := byFile[f]; !ok && f != File {
+				byFile[f] = nil
+			}
+		}
+		if byFile[File] == nil {
+			byFile[File] = [][]string{}
+		}
+		return byFile
+	})
+	if err != nil {
+		log.Printf("compact: %v", err)
+	}
+	return st
+}
+
+// Новое содержимое файлов журнала: файл -> строки
+func compactPlan(now time.Time, files []string, contents map[string][][]string) (CompactStats, map[string][][]string) {
+	var st CompactStats
+	var all []compactRow
+	for _, f := range files {
+		st.Files++
+		for _, row := range contents[f] {
+			st.Rows++
+			for i := range row {
+				row[i] = strings.TrimSpace(row[i])
+			}
+			if len(row) < 5 || row[1] == "" || row[3] == "" {
+				st.Malformed++
+				continue
+			}
+			if _, err := strconv.Atoi(row[1]); err != nil {
+				st.Malformed++
+				continue
+			}
+			t, normalized, ok := ParseTime(row[0])
+			if !ok {
+				st.Malformed++
+				continue
+			}
+			if normalized {
+				st.Normalized++
+				row[0] = t.Format(DateFormat)
+			}
+			all = append(all, compactRow{row, t, f})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	last := make(map[string]compactRow)
+	byFile := make(map[string][][]string)
+	for _, r := range all {
+		if prev, ok := last[r.Row[1]]; ok && prev.Row[3] == r.Row[3] && prev.Row[4] == r.Row[4] &&
+			r.Time.Sub(prev.Time) < DebounceWindow {
+			st.Duplicates++
+			continue
+		}
+		last[r.Row[1]] = r
+		dest := File
+		if r.Time.Before(monthStart) {
+			dest = ArchiveFile(r.Time)
+		}
+		if dest != r.From {
+			st.Moved++
+		}
+		byFile[dest] = append(byFile[dest], r.Row)
+		st.Kept++
+	}
+	return st, byFile
+}
+
+func (st CompactStats) String() string {
+	return fmt.Sprintf("Файлов: %d\nСтрок: %d\nОстанется: %d\n\n"+
+		"🗑 Битых строк: %d\n👆 Двойных нажатий: %d\n📅 Дат в старом формате: %d\n📦 Записей не в своём месяце: %d",
+		st.Files, st.Rows, st.Kept, st.Malformed, st.Duplicates, st.Normalized, st.Moved)
+}
@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// --- Индекс журнала по дням ---
+//
+// Для каждого файла журнала (рабочего и архивов) в памяти держатся
+// разобранные строки и номера строк по дням. Индекс строится один раз на
+// версию файла: запись через storage сбрасывает его (Invalidate из
+// storage.OnWrite), а размер и время изменения
+// страхуют от правок файла в обход бота. Выборка за период читает только
+// строки нужных дней и не разбирает даты заново (кроме границ периода,
+// если они не в полночь).
+
+const dayIndexLayout = "2006-01-02"
+
+type DayIndex struct {
+	Size    int64
+	ModTime time.Time
+	Rows    [][]string
+	Days    map[string][]int // день -> номера строк в файле
+}
+
+var (
+	dayIndexMu sync.Mutex
+	dayIndexes = make(map[string]*DayIndex)
+)
+
+func Invalidate(filename string) {
+	dayIndexMu.Lock()
+	delete(dayIndexes, filename)
+	dayIndexMu.Unlock()
+}
+
+// Индекс файла журнала; строки в нём общие — менять их нельзя
+func Index(ctx context.Context, filename string) *DayIndex {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return &DayIndex{Days: map[string][]int{}}
+	}
+	dayIndexMu.Lock()
+	idx, ok := dayIndexes[filename]
+	dayIndexMu.Unlock()
+	if ok && idx.Size == info.Size() && idx.ModTime.Equal(info.ModTime()) {
+		return idx
+	}
+	idx = &DayIndex{Size: info.Size(), ModTime: info.ModTime(), Rows: read(ctx, filename), Days: make(map[string][]int)}
+	for i, row := range idx.Rows {
+		if len(row) == 0 {
+			continue
+		}
+		t, err := time.ParseInLocation(DateFormat, row[0], time.Local)
+		if err != nil {
+			continue
+		}
+		day := t.Format(dayIndexLayout)
+		idx.Days[day] = append(idx.Days[day], i)
+	}
+	dayIndexMu.Lock()
+	dayIndexes[filename] = idx
+	dayIndexMu.Unlock()
+	return idx
+}
+
+// Записи с from по to (не включая), в порядке файлов: архивы, затем рабочий.
+// Строки копируются — кэш индекса не портится правками вызывающего
+func ReadRange(ctx context.Context, from, to time.Time) [][]string {
+	fromMonth := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.Local)
+	files := []string{}
+	for _, f := range Archives() {
+		if m, _ := ArchiveMonth(f); !m.Before(fromMonth) && m.Before(to) {
+			files = append(files, f)
+		}
+	}
+	files = append(files, File)
+
+	isMidnight := func(t time.Time) bool {
+		return t.Equal(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local))
+	}
+	exact := !isMidnight(from) || !isMidnight(to)
+	var rows [][]string
+	for _, f := range files {
+		idx := Index(ctx, f)
+		var picked []int
+		for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local); day.Before(to); day = day.AddDate(0, 0, 1) {
+			picked = append(picked, idx.Days[day.Format(dayIndexLayout)]...)
+		}
+		sort.Ints(picked)
+		for _, i := range picked {
+			row := idx.Rows[i]
+			if exact {
+				if t, _ := time.ParseInLocation(DateFormat, row[0], time.Local); t.Before(from) || !t.Before(to) {
+					continue
+				}
+			}
+			rows = append(rows, append([]string(nil), row...))
+		}
+	}
+	return rows
+}
@@ -0,0 +1,46 @@
+// Package journal — журнал отметок на диске: рабочий файл текущего месяца,
+// помесячные архивы, индекс по дням, журнал предзаписи (marks.wal) и
+// чистка /compact. Файлы читаются и пишутся через internal/storage; что
+// значат отметки и кто их делает, пакет не знает.
+package journal
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tabel-go/internal/storage"
+)
+
+const (
+	File       = "attendance.csv"
+	DateFormat = "02.01.2006 15:04:05"
+	// Та же отметка того же человека ближе этого — двойное нажатие
+	DebounceWindow = 15 * time.Second
+)
+
+// Чтение файла журнала; пустой результат, если файл не дождались
+func read(ctx context.Context, filename string) [][]string {
+	rows, err := storage.Read(ctx, filename)
+	if err != nil {
+		log.Printf("journal: %s: не дождались файла: %v", filename, err)
+	}
+	return rows
+}
+
+// Блокировка для операций со всем журналом сразу (восстановление, сброс,
+// /compact): ни ротация, ни отметка через marks.wal не вклинятся. Порядок
+// блокировок: shardMu, walMu, затем файлы журнала.
+func Lock(ctx context.Context) (unlock func(), err error) {
+	if err := shardMu.Lock(ctx); err != nil {
+		return nil, err
+	}
+	if err := walMu.Lock(ctx); err != nil {
+		shardMu.Unlock()
+		return nil, err
+	}
+	return func() {
+		walMu.Unlock()
+		shardMu.Unlock()
+	}, nil
+}
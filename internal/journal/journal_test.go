@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"tabel-go/internal/storage"
+)
+
+// Каждый тест — в своём каталоге: файлы журнала лежат в рабочем каталоге
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func mark(at time.Time, userID, action string) []string {
+	return []string{at.Format(DateFormat), userID, "Иванов И.И.", action, "Часть"}
+}
+
+func TestArchiveMovesPastMonths(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	feb := time.Date(2026, 2, 27, 9, 0, 0, 0, time.Local)
+	mar := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	storage.Append(ctx, File, mark(feb, "7", "Прибыл"))
+	storage.Append(ctx, File, mark(mar, "7", "Убыл"))
+
+	Archive(ctx, mar)
+	if rows := read(ctx, File); len(rows) != 1 || rows[0][0] != mar.Format(DateFormat) {
+		t.Fatalf("рабочий файл: %q", rows)
+	}
+	if files := Archives(); len(files) != 1 || files[0] != ArchiveFile(feb) {
+		t.Fatalf("архивы: %v", files)
+	}
+	if rows := ReadSince(ctx, feb); len(rows) != 2 {
+		t.Fatalf("ReadSince: %d строк", len(rows))
+	}
+	day := time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+	if rows := ReadRange(ctx, day, day.AddDate(0, 0, 1)); len(rows) != 1 || rows[0][3] != "Прибыл" {
+		t.Fatalf("ReadRange: %q", rows)
+	}
+}
+
+// После сбоя между marks.wal и журналом дописываются только недостающие отметки
+func TestReplayWAL(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	written, lost := mark(at, "7", "Прибыл"), mark(at.Add(time.Minute), "8", "Прибыл")
+	storage.Append(ctx, File, written)
+	for _, row := range [][]string{written, lost} {
+		if err := walAppend(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ReplayWAL(ctx)
+	if rows := read(ctx, File); len(rows) != 2 || rows[1][1] != "8" {
+		t.Fatalf("журнал: %q", rows)
+	}
+	if info, err := os.Stat(WALFile); err != nil || info.Size() != 0 {
+		t.Fatalf("marks.wal не очищен: %v", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	inTempDir(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	for _, row := range [][]string{
+		mark(at, "7", "Прибыл"),
+		mark(at.Add(5*time.Second), "7", "Прибыл"), // двойное нажатие
+		{"вчера", "7", "Иванов И.И.", "Убыл", "Часть"},
+		{at.Add(time.Hour).Format("2006-01-02 15:04"), "8", "Петров П.П.", "Убыл", "Часть"},
+		mark(time.Date(2026, 2, 20, 9, 0, 0, 0, time.Local), "8", "Прибыл"),
+	} {
+		storage.Append(ctx, File, row)
+	}
+
+	want := CompactStats{Files: 1, Rows: 5, Kept: 3, Malformed: 1, Duplicates: 1, Normalized: 1, Moved: 1}
+	if st := Compact(ctx, now, false); st != want {
+		t.Fatalf("подсчёт: %+v", st)
+	}
+	if rows := read(ctx, File); len(rows) != 5 {
+		t.Fatalf("подсчёт изменил журнал: %d строк", len(rows))
+	}
+	if st := Compact(ctx, now, true); st != want {
+		t.Fatalf("чистка: %+v", st)
+	}
+	rows := read(ctx, File)
+	if len(rows) != 2 || rows[1][0] != at.Add(time.Hour).Format(DateFormat) {
+		t.Fatalf("рабочий файл: %q", rows)
+	}
+	if rows := read(ctx, ArchiveFile(time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local))); len(rows) != 1 {
+		t.Fatalf("архив февраля: %q", rows)
+	}
+}
+
+// Пока журнал заблокирован целиком, ротация не ждёт дольше своего контекста
+func TestRotateGivesUpWhileLocked(t *testing.T) {
+	inTempDir(t)
+	unlock, err := Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		Rotate(ctx, time.Now())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Rotate ждал блокировку дольше контекста")
+	}
+}
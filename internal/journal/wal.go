@@ -0,0 +1,166 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"log"
+	"os"
+	"time"
+
+	"tabel-go/internal/storage"
+)
+
+// --- Журнал предзаписи отметок ---
+//
+// Отметка сначала дописывается в marks.wal с fsync, затем в журнал (тоже
+// с fsync), и только после удачной записи в журнал marks.wal очищается.
+// Если запись в журнал не удалась, отметка остаётся в marks.wal и
+// дописывается при следующей удачной записи. Если бот упал между этими
+// шагами, при запуске ReplayWAL дописывает в журнал отметки, которых
+// там ещё нет.
+
+const WALFile = "marks.wal"
+
+var (
+	walMu      storage.RWLock
+	walPending bool // в marks.wal есть отметки, не попавшие в журнал
+)
+
+func walAppend(row []string) error {
+	if _, ok := storage.Key(); ok {
+		// Зашифрованный WAL переписывается целиком, тоже с fsync
+		data, err := os.ReadFile(WALFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if data, err = storage.Open(data); err != nil {
+			return err
+		}
+		buf := bytes.NewBuffer(data)
+		writer := csv.NewWriter(buf)
+		writer.Write(row)
+		writer.Flush()
+		return storage.WriteFile(WALFile, buf.Bytes(), true)
+	}
+	file, err := os.OpenFile(WALFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func walClear() {
+	if err := os.Truncate(WALFile, 0); err != nil && !os.IsNotExist(err) {
+		log.Printf("wal: %v", err)
+	}
+}
+
+// Запись отметки через WAL. Если вызывающий не дождался marks.wal,
+// отметка уже принята и дописывается в фоне, когда хранилище освободится.
+func WriteMark(ctx context.Context, row []string) {
+	if err := walMu.Lock(ctx); err != nil {
+		log.Printf("wal: отметка %s %s ждёт хранилище в фоне: %v", row[0], row[1], err)
+		go WriteMark(context.Background(), row)
+		return
+	}
+	defer walMu.Unlock()
+	walErr := walAppend(row)
+	if walErr != nil {
+		log.Printf("wal: отметка записывается без журнала предзаписи: %v", walErr)
+	}
+	if err := storage.Append(ctx, File, row); err != nil {
+		if walErr == nil {
+			walPending = true
+			log.Printf("wal: отметка не записана в журнал и ждёт в %s", WALFile)
+		}
+		return
+	}
+	if walPending {
+		replayWALLocked(ctx)
+		return
+	}
+	walClear()
+}
+
+// Такая отметка уже есть в журнале
+func MarkExists(ctx context.Context, row []string) bool {
+	t, err := time.ParseInLocation(DateFormat, row[0], time.Local)
+	if err != nil {
+		return false
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	for _, r := range ReadRange(ctx, day, day.AddDate(0, 0, 1)) {
+		if len(r) >= 5 && r[0] == row[0] && r[1] == row[1] && r[3] == row[3] {
+			return true
+		}
+	}
+	return false
+}
+
+// При запуске, до первого чтения журнала
+func ReplayWAL(ctx context.Context) {
+	if err := walMu.Lock(ctx); err != nil {
+		log.Printf("wal: %v", err)
+		return
+	}
+	defer walMu.Unlock()
+	replayWALLocked(ctx)
+}
+
+// Дописывает в журнал отметки из marks.wal, которых там нет; marks.wal
+// очищается, только если все они записались
+func replayWALLocked(ctx context.Context) {
+	data, err := os.ReadFile(WALFile)
+	if err == nil {
+		data, err = storage.Open(data)
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("wal: %v", err)
+		}
+		return
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	// Последняя строка могла не дописаться — берём всё, что прочиталось
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	restored := 0
+	for _, row := range rows {
+		if len(row) < 5 || MarkExists(ctx, row) {
+			continue
+		}
+		if err := storage.Append(ctx, File, row); err != nil {
+			walPending = true
+			log.Printf("wal: восстановлено %d, остальные остаются в %s", restored, WALFile)
+			return
+		}
+		restored++
+	}
+	if restored > 0 {
+		log.Printf("wal: восстановлено отметок после сбоя: %d", restored)
+	}
+	walPending = false
+	walClear()
+}
+
+// Журнал стёрт целиком: отметки из marks.wal возвращать некуда.
+// Вызывается под Lock.
+func DropWAL() {
+	walPending = false
+	walClear()
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q): ошибки нет", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		spec, from, want string
+	}{
+		{"0 19 * * *", "2026-03-02 18:59", "2026-03-02 19:00"},
+		{"0 19 * * *", "2026-03-02 19:00", "2026-03-03 19:00"},
+		{"*/15 * * * *", "2026-03-02 10:07", "2026-03-02 10:15"},
+		{"30 8-18/2 * * *", "2026-03-02 09:00", "2026-03-02 10:30"},
+		{"0 9 * * 1-5", "2026-03-06 10:00", "2026-03-09 09:00"}, // пятница -> понедельник
+		{"0 0 * * 7", "2026-03-02 00:00", "2026-03-08 00:00"},   // 7 — воскресенье
+		{"0 0 1,15 * *", "2026-03-02 00:00", "2026-03-15 00:00"},
+		{"0 0 13 * 5", "2026-03-02 00:00", "2026-03-06 00:00"}, // день месяца или день недели
+		{"@monthly", "2026-12-31 23:59", "2027-01-01 00:00"},
+		{"@hourly", "2026-03-02 10:00", "2026-03-02 11:00"},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.spec)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", c.spec, err)
+		}
+		if got := s.Next(at(c.from)); !got.Equal(at(c.want)) {
+			t.Errorf("%q после %s: %s, ожидалось %s", c.spec, c.from, got.Format("2006-01-02 15:04"), c.want)
+		}
+	}
+	s, _ := ParseCron("0 0 31 2 *")
+	if got := s.Next(at("2026-03-02 00:00")); !got.IsZero() {
+		t.Errorf("31 февраля: %s", got)
+	}
+}
@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Периодическая задача
+type Job struct {
+	Name    string
+	Spec    string        // cron-расписание
+	Jitter  time.Duration // случайная задержка запуска, от 0 до Jitter
+	CatchUp time.Duration // пропущенный запуск не старше CatchUp выполняется при старте
+	Quiet   bool          // частая задача: успешные запуски не пишутся в лог и не сохраняются через SaveRun
+	Enabled func() bool   // nil — включена всегда
+	// now — момент запуска по расписанию; при догонке это пропущенный
+	// момент, а не время старта, чтобы задача отработала за тот день
+	Run func(ctx context.Context, now time.Time) error
+}
+
+// Состояние задачи для вывода
+type JobStatus struct {
+	Name     string
+	Spec     string
+	Enabled  bool
+	Next     time.Time
+	LastRun  time.Time
+	Duration time.Duration
+	Err      error
+}
+
+type entry struct {
+	job      Job
+	schedule *Schedule
+	next     time.Time
+	lastRun  time.Time
+	duration time.Duration
+	err      error
+	cancel   context.CancelFunc
+}
+
+// Набор задач, каждая в своей горутине. Каждый запуск пишется в лог;
+// паника задачи становится её ошибкой и не останавливает остальные.
+type Scheduler struct {
+	Clock   Clock
+	OnError func(job string, err error)
+	// Хранилище времени последних запусков, чтобы после простоя
+	// догнать пропущенное; без него догонка не работает
+	LastRun func(job string) time.Time
+	SaveRun func(job string, t time.Time)
+
+	mu   sync.Mutex
+	ctx  context.Context // задан после Start
+	jobs map[string]*entry
+}
+
+func New(c Clock) *Scheduler {
+	return &Scheduler{Clock: c, jobs: make(map[string]*entry)}
+}
+
+func (s *Scheduler) Add(job Job) error {
+	sched, err := ParseCron(job.Spec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", job.Name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("%s: задача уже есть", job.Name)
+	}
+	e := &entry{job: job, schedule: sched}
+	s.jobs[job.Name] = e
+	if s.ctx != nil {
+		s.launch(e)
+	}
+	return nil
+}
+
+// Снимает задачу с расписания; false — такой задачи нет
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.jobs[name]
+	if !ok {
+		return false
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+	delete(s.jobs, name)
+	return true
+}
+
+// Запускает задачи; добавленные после Start стартуют сразу
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	for _, e := range s.jobs {
+		s.launch(e)
+	}
+}
+
+func (s *Scheduler) launch(e *entry) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	e.cancel = cancel
+	go s.loop(ctx, e)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	s.catchUp(ctx, e)
+	for {
+		next := e.schedule.Next(s.Clock.Now())
+		if next.IsZero() {
+			log.Printf("scheduler: %s: расписание %q не наступит никогда", e.job.Name, e.job.Spec)
+			return
+		}
+		if e.job.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(e.job.Jitter))))
+		}
+		s.mu.Lock()
+		e.next = next
+		s.mu.Unlock()
+		if !SleepUntil(ctx, s.Clock, next) {
+			return
+		}
+		if e.job.Enabled != nil && !e.job.Enabled() {
+			log.Printf("scheduler: %s пропущена — выключена", e.job.Name)
+			continue
+		}
+		s.run(ctx, e, time.Time{})
+	}
+}
+
+// Запуск, пропущенный пока бот был выключен: первый по расписанию момент
+// после последнего запуска уже прошёл, но не раньше чем CatchUp назад
+func (s *Scheduler) catchUp(ctx context.Context, e *entry) {
+	if e.job.CatchUp <= 0 || s.LastRun == nil {
+		return
+	}
+	now := s.Clock.Now()
+	last := s.LastRun(e.job.Name)
+	if last.IsZero() {
+		// Задача ещё не запускалась — отсчёт пропусков ведётся от этого старта
+		if s.SaveRun != nil {
+			s.SaveRun(e.job.Name, now)
+		}
+		return
+	}
+	missed := e.schedule.Next(last)
+	if missed.IsZero() || missed.After(now) || now.Sub(missed) > e.job.CatchUp {
+		return
+	}
+	if e.job.Enabled != nil && !e.job.Enabled() {
+		return
+	}
+	log.Printf("scheduler: %s — догоняю запуск %s", e.job.Name, missed.Format("02.01 15:04"))
+	s.run(ctx, e, missed)
+}
+
+// at — момент, за который работает задача; нулевой — текущее время
+func (s *Scheduler) run(ctx context.Context, e *entry, at time.Time) {
+	start := s.Clock.Now()
+	if at.IsZero() {
+		at = start
+	}
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return e.job.Run(ctx, at)
+	}()
+	took := s.Clock.Now().Sub(start)
+	s.mu.Lock()
+	e.lastRun, e.duration, e.err = start, took, err
+	s.mu.Unlock()
+	if s.SaveRun != nil && !e.job.Quiet {
+		s.SaveRun(e.job.Name, start)
+	}
+	if err != nil {
+		log.Printf("scheduler: %s — ошибка за %s: %v", e.job.Name, took.Round(time.Millisecond), err)
+		if s.OnError != nil {
+			s.OnError(e.job.Name, err)
+		}
+		return
+	}
+	if !e.job.Quiet {
+		log.Printf("scheduler: %s выполнена за %s", e.job.Name, took.Round(time.Millisecond))
+	}
+}
+
+// Запуск задачи вне расписания; false — такой задачи нет
+func (s *Scheduler) RunNow(ctx context.Context, name string) bool {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	s.run(ctx, e, time.Time{})
+	return true
+}
+
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []JobStatus
+	for _, e := range s.jobs {
+		enabled := e.job.Enabled == nil || e.job.Enabled()
+		out = append(out, JobStatus{e.job.Name, e.job.Spec, enabled, e.next, e.lastRun, e.duration, e.err})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Часы, которые стоят на месте; Sleep только сдвигает время, а таймеры
+// не срабатывают никогда — задача ждёт, пока её не остановят
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func (c *fakeClock) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	return nil, func() bool { return true }
+}
+
+// Планировщик с одной задачей и сохранённым последним запуском last
+func newCatchUpTest(t *testing.T, now, last time.Time, catchUp time.Duration) (*Scheduler, *entry, *[]time.Time) {
+	t.Helper()
+	var runs []time.Time
+	s := New(&fakeClock{now: now})
+	saved := map[string]time.Time{"report": last}
+	s.LastRun = func(job string) time.Time { return saved[job] }
+	s.SaveRun = func(job string, at time.Time) { saved[job] = at }
+	err := s.Add(Job{Name: "report", Spec: "0 19 * * *", CatchUp: catchUp, Run: func(ctx context.Context, now time.Time) error {
+		runs = append(runs, now)
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, s.jobs["report"], &runs
+}
+
+func TestCatchUp(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		name      string
+		now, last time.Time
+		catchUp   time.Duration
+		runs      int
+	}{
+		{"пропущен", day.Add(20 * time.Hour), day.Add(-5 * time.Hour), 6 * time.Hour, 1},
+		{"не пропущен", day.Add(18 * time.Hour), day.Add(-5 * time.Hour), 6 * time.Hour, 0},
+		{"слишком давно", day.Add(26 * time.Hour), day.Add(-5 * time.Hour), 6 * time.Hour, 0},
+		{"без догонки", day.Add(20 * time.Hour), day.Add(-5 * time.Hour), 0, 0},
+		{"первый старт", day.Add(20 * time.Hour), time.Time{}, 6 * time.Hour, 0},
+	}
+	for _, c := range cases {
+		s, e, runs := newCatchUpTest(t, c.now, c.last, c.catchUp)
+		s.catchUp(context.Background(), e)
+		if len(*runs) != c.runs {
+			t.Errorf("%s: запусков %d, ожидалось %d", c.name, len(*runs), c.runs)
+		}
+	}
+}
+
+// Отчёт в 19:00, бот проснулся после полуночи: задача получает пропущенный
+// момент, а не время старта, и отрабатывает за прошедший день
+func TestCatchUpAcrossMidnight(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	now := day.Add(24*time.Hour + 30*time.Minute)
+	s, e, runs := newCatchUpTest(t, now, day.Add(-5*time.Hour), 6*time.Hour)
+	s.catchUp(context.Background(), e)
+	if len(*runs) != 1 {
+		t.Fatalf("запусков %d, ожидался 1", len(*runs))
+	}
+	if want := day.Add(19 * time.Hour); !(*runs)[0].Equal(want) {
+		t.Fatalf("задача получила %s, ожидалось %s", (*runs)[0], want)
+	}
+	if got := s.LastRun("report"); !got.Equal(now) {
+		t.Fatalf("сохранён запуск %s, ожидалось время старта %s", got, now)
+	}
+}
+
+func TestCatchUpRespectsEnabled(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	s, e, runs := newCatchUpTest(t, day.Add(20*time.Hour), day.Add(-5*time.Hour), 6*time.Hour)
+	e.job.Enabled = func() bool { return false }
+	s.catchUp(context.Background(), e)
+	if len(*runs) != 0 {
+		t.Fatal("выключенная задача догнана")
+	}
+}
+
+// Остановка и Remove не ждут следующего запуска по расписанию
+func TestStopDoesNotWaitForNextRun(t *testing.T) {
+	s := New(&fakeClock{now: time.Date(2026, 3, 2, 20, 0, 0, 0, time.Local)})
+	err := s.Add(Job{Name: "report", Spec: "0 19 * * *", Run: func(ctx context.Context, now time.Time) error {
+		t.Error("задача запустилась без срабатывания таймера")
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Remove отменяет тот же контекст задачи, что и остановка бота
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.loop(ctx, s.jobs["report"])
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("цикл задачи не завершился после остановки")
+	}
+}
@@ -0,0 +1,40 @@
+// Package scheduler — часы, cron-расписания и запуск периодических задач.
+//
+// Планировщики берут время у Clock, а не у time.Now и time.Sleep: в работе
+// это System, а подставив свою реализацию, задачу можно прогнать с
+// заданным временем и без реального ожидания.
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	// Таймер на d: канал сработает по истечении, stop освобождает его раньше
+	Timer(d time.Duration) (c <-chan time.Time, stop func() bool)
+}
+
+type System struct{}
+
+func (System) Now() time.Time        { return time.Now() }
+func (System) Sleep(d time.Duration) { time.Sleep(d) }
+
+func (System) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// Ждёт до момента t по часам c; false — ctx отменили раньше
+func SleepUntil(ctx context.Context, c Clock, t time.Time) bool {
+	ch, stop := c.Timer(t.Sub(c.Now()))
+	defer stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ch:
+		return ctx.Err() == nil
+	}
+}
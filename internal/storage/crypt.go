@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Шифрование файлов данных.
+//
+// Пока Key возвращает ключ, файлы пишутся зашифрованными AES-256-GCM:
+// заголовок Magic, затем nonce и шифртекст. Файлы без заголовка читаются
+// как есть и шифруются при следующей записи.
+
+var Magic = []byte("TBENC1\n")
+
+var ErrNoKey = errors.New("файл зашифрован, а ключ не задан")
+
+// Шифрование AES-256-GCM: nonce || ciphertext
+func Encrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func Decrypt(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("слишком короткие данные")
+	}
+	nonce, ct := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ct, nil)
+}
+
+func Encrypted(data []byte) bool {
+	return bytes.HasPrefix(data, Magic)
+}
+
+// Шифрует содержимое файла, если шифрование включено
+func Seal(plain []byte) ([]byte, error) {
+	key, ok := Key()
+	if !ok {
+		return plain, nil
+	}
+	sealed, err := Encrypt(key, plain)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, Magic...), sealed...), nil
+}
+
+// Расшифровывает содержимое файла; незашифрованное возвращается как есть
+func Open(data []byte) ([]byte, error) {
+	if !Encrypted(data) {
+		return data, nil
+	}
+	key, ok := Key()
+	if !ok {
+		return nil, ErrNoKey
+	}
+	plain, err := Decrypt(key, data[len(Magic):])
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать (неверный ключ?): %w", err)
+	}
+	return plain, nil
+}
+
+// Пишет файл целиком через временный; sync — с fsync
+func WriteFile(name string, plain []byte, sync bool) error {
+	data, err := Seal(plain)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if sync {
+		if err := file.Sync(); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	file.Close()
+	return os.Rename(tmp, name)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// --- Блокировка с отменой ---
+//
+// sync.RWMutex ждёт бесконечно: зависшая запись в файл держала бы и цикл
+// апдейтов, и всех, кто ждёт тот же файл. RWLock перестаёт ждать, когда
+// отменён контекст вызова. С отменённым контекстом блокировка не берётся
+// вовсе, даже свободная: если чтение не удалось, то и запись, собранная по
+// его пустому результату, не пройдёт. Ожидающая запись не пропускает
+// вперёд новые чтения, чтобы частые чтения не откладывали её без конца.
+
+type RWLock struct {
+	mu      sync.Mutex
+	readers int
+	writer  bool
+	waiting int           // записи, ждущие своей очереди
+	changed chan struct{} // закрывается при каждом освобождении
+}
+
+func (l *RWLock) Lock(ctx context.Context) error  { return l.acquire(ctx, true) }
+func (l *RWLock) RLock(ctx context.Context) error { return l.acquire(ctx, false) }
+
+func (l *RWLock) Unlock() {
+	l.mu.Lock()
+	l.writer = false
+	l.signalLocked()
+	l.mu.Unlock()
+}
+
+func (l *RWLock) RUnlock() {
+	l.mu.Lock()
+	l.readers--
+	l.signalLocked()
+	l.mu.Unlock()
+}
+
+func (l *RWLock) acquire(ctx context.Context, write bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	if write {
+		l.waiting++
+	}
+	for {
+		if !l.writer && (write && l.readers == 0 || !write && l.waiting == 0) {
+			if write {
+				l.waiting--
+				l.writer = true
+			} else {
+				l.readers++
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			if write {
+				// Чтения, пропускавшие эту запись вперёд, больше её не ждут
+				l.waiting--
+				l.signalLocked()
+			}
+			l.mu.Unlock()
+			return err
+		}
+		if l.changed == nil {
+			l.changed = make(chan struct{})
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+		l.mu.Lock()
+	}
+}
+
+func (l *RWLock) signalLocked() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Занятая запись не держит ожидающего дольше его контекста
+func TestLockGivesUpOnCancel(t *testing.T) {
+	var l RWLock
+	if err := l.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.RLock(ctx); err == nil {
+		t.Fatal("чтение получило блокировку, занятую записью")
+	}
+	if err := l.Lock(ctx); err == nil {
+		t.Fatal("вторая запись получила занятую блокировку")
+	}
+	l.Unlock()
+	// Отказавшаяся запись не оставила после себя очередь
+	if err := l.RLock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.RUnlock()
+}
+
+// С отменённым контекстом не берётся даже свободная блокировка
+func TestLockRefusesCancelledContext(t *testing.T) {
+	var l RWLock
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.RLock(ctx); err == nil {
+		t.Fatal("чтение с отменённым контекстом")
+	}
+	if err := l.Lock(ctx); err == nil {
+		t.Fatal("запись с отменённым контекстом")
+	}
+}
+
+// Чтения идут параллельно; ожидающая запись пропускает их вперёд только до себя
+func TestLockWriterWaitsForReaders(t *testing.T) {
+	var l RWLock
+	ctx := context.Background()
+	if err := l.RLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.RLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	locked := make(chan struct{})
+	go func() {
+		l.Lock(ctx)
+		close(locked)
+	}()
+	l.RUnlock()
+	select {
+	case <-locked:
+		t.Fatal("запись прошла при активном чтении")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.RUnlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("запись не дождалась конца чтений")
+	}
+	l.Unlock()
+}
@@ -0,0 +1,371 @@
+// Package storage — чтение и запись CSV-файлов данных.
+//
+// Апдейты обрабатываются в основном цикле, но планировщики, HTTP-обработчики
+// и фоновые задачи пишут в те же файлы. У каждого файла свой RWLock
+// (lock.go): чтения идут параллельно, запись исключительна, а ожидание
+// блокировки прерывается контекстом вызова — с отменённым контекстом
+// операция возвращает его ошибку и файл не трогает. Write пишет во временный
+// файл и переименовывает его, так что даже чтение в обход бота не увидит
+// файл наполовину записанным. Чтение-изменение-запись одного файла — через
+// Update, чтобы между ними никто не вклинился. Заголовок файла (table.go)
+// читающим не виден: он снимается при чтении и пишется заново при каждой
+// записи. Шифрование (crypt.go) тоже происходит здесь.
+//
+// О файлах приложения пакет узнаёт через Tables, Key и OnWrite — их
+// задаёт бот при запуске (internal/handlers/storage.go).
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	// Таблица файла: колонки заголовка и минимальное число полей
+	Tables = func(filename string) (Table, bool) { return Table{}, false }
+	// Ключ шифрования; false — файлы пишутся открытыми
+	Key = func() ([]byte, bool) { return nil, false }
+	// Вызывается после каждой записи, уже без блокировки файла: кэши,
+	// которые сбрасывает бот, сами читают файлы
+	OnWrite = func(filename string) {}
+)
+
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = make(map[string]*RWLock)
+)
+
+func fileLock(filename string) *RWLock {
+	fileLocksMu.Lock()
+	defer fileLocksMu.Unlock()
+	l, ok := fileLocks[filename]
+	if !ok {
+		l = &RWLock{}
+		fileLocks[filename] = l
+	}
+	return l
+}
+
+func Read(ctx context.Context, filename string) ([][]string, error) {
+	l := fileLock(filename)
+	if err := l.RLock(ctx); err != nil {
+		return nil, err
+	}
+	defer l.RUnlock()
+	return parseFile(filename, false), nil
+}
+
+// Все строки, включая слишком короткие; для проверки данных
+func ReadUnfiltered(ctx context.Context, filename string) ([][]string, error) {
+	l := fileLock(filename)
+	if err := l.RLock(ctx); err != nil {
+		return nil, err
+	}
+	defer l.RUnlock()
+	return parseFile(filename, true), nil
+}
+
+func Write(ctx context.Context, filename string, rows [][]string) error {
+	l := fileLock(filename)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+	err := writeLocked(filename, rows, false)
+	l.Unlock()
+	OnWrite(filename)
+	return err
+}
+
+// Дописывает одну строку в конец файла, не переписывая остальное.
+// Возвращается только после fsync: nil значит, что строка на диске.
+func Append(ctx context.Context, filename string, row []string) error {
+	l := fileLock(filename)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+	err := appendLocked(filename, row)
+	l.Unlock()
+	OnWrite(filename)
+	if err != nil {
+		log.Printf("appendCSV %s: %v", filename, err)
+	}
+	return err
+}
+
+func appendLocked(filename string, row []string) error {
+	if _, ok := Key(); ok {
+		// Зашифрованный файл не дописать в конец — только переписать целиком
+		return writeLocked(filename, append(parseFile(filename, true), row), true)
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 && Header(filename) != nil {
+		writer.Write(Header(filename))
+	}
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Чтение, изменение и запись файла под одной блокировкой
+func Update(ctx context.Context, filename string, apply func(rows [][]string) [][]string) error {
+	l := fileLock(filename)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+	err := writeLocked(filename, apply(parseFile(filename, false)), false)
+	l.Unlock()
+	OnWrite(filename)
+	return err
+}
+
+// Перенос строк из filename в другие файлы под блокировками всех
+// участников. apply возвращает остаток и строки для дописывания (имя файла
+// -> строки). Сначала на диск попадают дописанные строки, потом остаток:
+// при сбое посередине строки задвоятся, но не пропадут. OnWrite — после
+// снятия блокировок, чтобы обработчики могли читать эти файлы.
+func Move(ctx context.Context, filename string, apply func(rows [][]string) (keep [][]string, moved map[string][][]string)) error {
+	l := fileLock(filename)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+	keep, moved := apply(parseFile(filename, false))
+	var err error
+	for name, rows := range moved {
+		dl := fileLock(name)
+		if err = dl.Lock(ctx); err != nil {
+			break
+		}
+		err = writeLocked(name, append(parseFile(name, false), rows...), true)
+		dl.Unlock()
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writeLocked(filename, keep, true)
+	}
+	l.Unlock()
+	for name := range moved {
+		OnWrite(name)
+	}
+	OnWrite(filename)
+	return err
+}
+
+// Чтение-изменение-запись нескольких файлов разом. Блокировки берутся в
+// порядке files, поэтому вызовы с пересекающимися наборами должны
+// перечислять файлы в одном порядке. apply получает содержимое и
+// возвращает новое только для изменённых файлов; nil — файл удаляется.
+// Новые файлы, которых нет в files, блокируются при записи. Первый файл
+// (files не пуст) пишется последним: при сбое посередине он остаётся прежним.
+func UpdateAll(ctx context.Context, files []string, apply func(rows map[string][][]string) map[string][][]string) error {
+	held := make(map[string]*RWLock)
+	unlock := func() {
+		for _, l := range held {
+			l.Unlock()
+		}
+	}
+	current := make(map[string][][]string)
+	for _, f := range files {
+		l := fileLock(f)
+		if err := l.Lock(ctx); err != nil {
+			unlock()
+			return err
+		}
+		held[f] = l
+		current[f] = parseFile(f, false)
+	}
+	changed := apply(current)
+	write := func(name string, rows [][]string) error {
+		if _, ok := held[name]; !ok {
+			l := fileLock(name)
+			if err := l.Lock(ctx); err != nil {
+				return err
+			}
+			defer l.Unlock()
+		}
+		if rows == nil {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		return writeLocked(name, rows, true)
+	}
+	var err error
+	for name, rows := range changed {
+		if name == files[0] {
+			continue
+		}
+		if err = write(name, rows); err != nil {
+			break
+		}
+	}
+	if first, ok := changed[files[0]]; err == nil && ok {
+		err = write(files[0], first)
+	}
+	unlock()
+	for name := range changed {
+		OnWrite(name)
+	}
+	return err
+}
+
+// Подмена файлов целиком (восстановление из копии): files — имя ->
+// расшифрованное содержимое, на диск оно пишется с текущим ключом;
+// nil — файл удаляется.
+// Блокировки берутся на все файлы сразу, в порядке имён. Сначала всё
+// пишется во временные файлы, прежние откладываются в .prev; если какое-то
+// переименование не удалось, уже подменённые файлы возвращаются назад.
+func Replace(ctx context.Context, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var held []*RWLock
+	unlock := func() {
+		for _, l := range held {
+			l.Unlock()
+		}
+	}
+	for _, name := range names {
+		l := fileLock(name)
+		if err := l.Lock(ctx); err != nil {
+			unlock()
+			return err
+		}
+		held = append(held, l)
+	}
+	err := replaceLocked(names, files)
+	unlock()
+	for _, name := range names {
+		OnWrite(name)
+	}
+	return err
+}
+
+func replaceLocked(names []string, files map[string][]byte) error {
+	cleanup := func() {
+		for _, name := range names {
+			os.Remove(name + ".restore")
+		}
+	}
+	for _, name := range names {
+		if files[name] == nil {
+			continue
+		}
+		sealed, err := Seal(files[name])
+		if err == nil {
+			err = writeSynced(name+".restore", sealed)
+		}
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	var done []string
+	existed := make(map[string]bool)
+	rollback := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			name := done[i]
+			if existed[name] {
+				os.Rename(name+".prev", name)
+			} else if files[name] != nil {
+				os.Remove(name)
+			}
+		}
+		cleanup()
+	}
+	for _, name := range names {
+		if _, err := os.Stat(name); err == nil {
+			if err := os.Rename(name, name+".prev"); err != nil {
+				rollback()
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			existed[name] = true
+		}
+		if files[name] == nil {
+			done = append(done, name)
+			continue
+		}
+		if err := os.Rename(name+".restore", name); err != nil {
+			if existed[name] {
+				os.Rename(name+".prev", name)
+			}
+			rollback()
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		done = append(done, name)
+	}
+	for _, name := range names {
+		os.Remove(name + ".prev")
+	}
+	return nil
+}
+
+func writeSynced(name string, data []byte) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Как Update, но без отбрасывания коротких строк: для миграций, которые
+// не должны терять то, что потом покажет проверка данных
+func Rewrite(ctx context.Context, filename string, apply func(rows [][]string) [][]string) error {
+	l := fileLock(filename)
+	if err := l.Lock(ctx); err != nil {
+		return err
+	}
+	err := writeLocked(filename, apply(parseFile(filename, true)), false)
+	l.Unlock()
+	OnWrite(filename)
+	return err
+}
+
+func writeLocked(filename string, rows [][]string, sync bool) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if header := Header(filename); header != nil {
+		writer.Write(header)
+	}
+	writer.WriteAll(rows)
+	writer.Flush()
+	err := writer.Error()
+	if err == nil {
+		err = WriteFile(filename, buf.Bytes(), sync)
+	}
+	if err != nil {
+		log.Printf("writeCSV %s: %v", filename, err)
+	}
+	return err
+}
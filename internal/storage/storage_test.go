@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceRemovesNilFiles(t *testing.T) {
+	dir := t.TempDir()
+	keep, gone := filepath.Join(dir, "keep.csv"), filepath.Join(dir, "gone.csv")
+	for _, name := range []string{keep, gone} {
+		if err := os.WriteFile(name, []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Replace(context.Background(), map[string][]byte{keep: []byte("new\n"), gone: nil}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(keep); string(data) != "new\n" {
+		t.Fatalf("keep.csv = %q", data)
+	}
+	if _, err := os.Stat(gone); !os.IsNotExist(err) {
+		t.Fatal("gone.csv не удалён")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.prev")); len(files) != 0 {
+		t.Fatalf("остались %v", files)
+	}
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Заголовки CSV и разбор по именам колонок.
+//
+// Первая строка каждого известного файла — заголовок с именами колонок.
+// При чтении строки раскладываются в порядок колонок этой сборки по
+// именам из заголовка, так что переставленные или добавленные другой
+// версией колонки не сдвигают данные. Лишние поля в конце строки
+// сохраняются как есть, недостающие считаются пустыми. Строки короче
+// Required и битые строки пропускаются и пишутся в лог, один раз на
+// каждое изменение файла. Файлы без заголовка читаются по позициям, как
+// раньше; заголовок появится при следующей записи.
+
+type Table struct {
+	Columns  []string
+	Required int // меньше полей — строка пропускается
+}
+
+// Заголовок: первое поле и большинство остальных — имена колонок
+func (t Table) isHeader(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	known := 0
+	for _, f := range row {
+		if t.index(f) >= 0 {
+			known++
+		}
+	}
+	return t.index(row[0]) >= 0 && known*2 >= len(row)
+}
+
+func (t Table) index(name string) int {
+	for i, c := range t.Columns {
+		if c == strings.TrimSpace(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Переставляет поля строки из порядка header в порядок колонок таблицы;
+// nil — порядок совпадает и переставлять нечего
+func (t Table) remapper(header []string) func([]string) []string {
+	same := len(header) <= len(t.Columns)
+	for i := 0; same && i < len(header); i++ {
+		same = strings.TrimSpace(header[i]) == t.Columns[i]
+	}
+	if same {
+		return nil
+	}
+	src := make([]int, len(t.Columns))
+	used := make(map[int]bool)
+	for j, c := range t.Columns {
+		src[j] = -1
+		for i, h := range header {
+			if strings.TrimSpace(h) == c && !used[i] {
+				src[j] = i
+				used[i] = true
+				break
+			}
+		}
+	}
+	return func(row []string) []string {
+		out := make([]string, len(t.Columns))
+		for j, i := range src {
+			if i >= 0 && i < len(row) {
+				out[j] = row[i]
+			}
+		}
+		// Колонки, неизвестные этой сборке, и поля за концом заголовка
+		for i, f := range row {
+			if !used[i] {
+				out = append(out, f)
+			}
+		}
+		return out
+	}
+}
+
+var (
+	skipLogMu sync.Mutex
+	skipLog   = make(map[string]time.Time) // файл -> mtime, о котором уже писали
+)
+
+// Пишет в лог пропущенные строки, если об этой версии файла ещё не писали
+func logSkippedRows(filename string, mod time.Time, problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+	skipLogMu.Lock()
+	defer skipLogMu.Unlock()
+	if skipLog[filename].Equal(mod) {
+		return
+	}
+	skipLog[filename] = mod
+	if len(problems) > 10 {
+		problems = append(problems[:10], "…")
+	}
+	log.Printf("readCSV %s: пропущено строк — %s", filename, strings.Join(problems, "; "))
+}
+
+// Разбор файла с заголовком; keepShort оставляет строки короче Required
+// (нужно /checkdata, чтобы показать их, а не потерять молча)
+func parseFile(filename string, keepShort bool) [][]string {
+	file, err := os.OpenFile(filename, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return [][]string{}
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err == nil {
+		data, err = Open(data)
+	}
+	if err != nil {
+		log.Printf("readCSV %s: %v", filename, err)
+		return [][]string{}
+	}
+	table, known := Tables(filename)
+	reader := csv.NewReader(bytes.NewReader(data))
+	// Старые строки короче новых — число полей не проверяем
+	reader.FieldsPerRecord = -1
+	var (
+		rows     [][]string
+		remap    func([]string) []string
+		problems []string
+		first    = true
+	)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				problems = append(problems, err.Error())
+				continue
+			}
+			problems = append(problems, err.Error())
+			break
+		}
+		if known && first && table.isHeader(row) {
+			first = false
+			remap = table.remapper(row)
+			continue
+		}
+		first = false
+		if remap != nil {
+			row = remap(row)
+		}
+		if known && !keepShort && len(row) < table.Required {
+			line, _ := reader.FieldPos(0)
+			problems = append(problems, fmt.Sprintf("строка %d: полей %d", line, len(row)))
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if info, err := file.Stat(); err == nil {
+		logSkippedRows(filename, info.ModTime(), problems)
+	}
+	if rows == nil {
+		rows = [][]string{}
+	}
+	return rows
+}
+
+// Строки без заголовка, для файлов, прочитанных не через Read
+func DropHeader(filename string, rows [][]string) [][]string {
+	if t, ok := Tables(filename); ok && len(rows) > 0 && t.isHeader(rows[0]) {
+		return rows[1:]
+	}
+	return rows
+}
+
+func Header(filename string) []string {
+	if t, ok := Tables(filename); ok {
+		return t.Columns
+	}
+	return nil
+}
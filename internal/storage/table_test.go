@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Таблица "marks.csv" с колонками date, id, action; строки короче двух полей пропускаются
+func withTestTable(t *testing.T) string {
+	t.Helper()
+	prev := Tables
+	Tables = func(filename string) (Table, bool) {
+		if filepath.Base(filename) == "marks.csv" {
+			return Table{Columns: []string{"date", "id", "action"}, Required: 2}, true
+		}
+		return Table{}, false
+	}
+	t.Cleanup(func() { Tables = prev })
+	return filepath.Join(t.TempDir(), "marks.csv")
+}
+
+func TestReadRemapsColumnsByHeader(t *testing.T) {
+	name := withTestTable(t)
+	// Другая версия переставила колонки и добавила свою в конец
+	data := "action,date,id,extra\nПрибыл,01.03.2026 08:00:00,7,x\n"
+	if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"01.03.2026 08:00:00", "7", "Прибыл", "x"}}
+	if got, _ := Read(context.Background(), name); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read = %q, ожидалось %q", got, want)
+	}
+}
+
+func TestReadWithoutHeaderAndShortRows(t *testing.T) {
+	name := withTestTable(t)
+	data := "01.03.2026 08:00:00,7,Прибыл\nбитая\n01.03.2026 18:00:00,7\n"
+	if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"01.03.2026 08:00:00", "7", "Прибыл"}, {"01.03.2026 18:00:00", "7"}}
+	if got, _ := Read(context.Background(), name); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Read = %q, ожидалось %q", got, want)
+	}
+	if got, _ := ReadUnfiltered(context.Background(), name); len(got) != 3 {
+		t.Fatalf("ReadUnfiltered: строк %d, ожидалось 3", len(got))
+	}
+}
+
+func TestWriteAddsHeader(t *testing.T) {
+	name := withTestTable(t)
+	ctx := context.Background()
+	if err := Write(ctx, name, [][]string{{"01.03.2026 08:00:00", "7", "Прибыл"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(ctx, name, []string{"01.03.2026 18:00:00", "7", "Убыл"}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "date,id,action\n01.03.2026 08:00:00,7,Прибыл\n01.03.2026 18:00:00,7,Убыл\n"
+	if string(raw) != want {
+		t.Fatalf("файл:\n%s\nожидалось:\n%s", raw, want)
+	}
+	if got, _ := Read(ctx, name); len(got) != 2 {
+		t.Fatalf("заголовок прочитан как строка: %q", got)
+	}
+}
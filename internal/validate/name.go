@@ -0,0 +1,50 @@
+// Package validate — разбор и проверка того, что вводят пользователи и
+// присылает Telegram: ФИО, длительности и расписания, подписи Mini App и
+// виджета входа. Ничего не читает и не пишет; ошибки — текстом для
+// ответа пользователю.
+package validate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// --- ФИО ---
+
+var (
+	namePartRegex = regexp.MustCompile(`^[А-ЯЁа-яё]+(-[А-ЯЁа-яё]+)*$`)
+	initialsRegex = regexp.MustCompile(`^([А-ЯЁа-яё])\.(?:([А-ЯЁа-яё])\.?)?$`)
+)
+
+// Приводит ФИО к виду «Фамилия И.О.». Принимает «Иванов И.И.», «Иванов И. И.»,
+// «Иванов И.», «Иванов Иван Иванович», двойные фамилии через дефис.
+func Name(name string) (string, bool) {
+	parts := strings.Fields(name)
+	if len(parts) < 2 || len([]rune(parts[0])) < 2 || !namePartRegex.MatchString(parts[0]) {
+		return "", false
+	}
+	var surname []string
+	for _, p := range strings.Split(parts[0], "-") {
+		r := []rune(strings.ToLower(p))
+		surname = append(surname, strings.ToUpper(string(r[0]))+string(r[1:]))
+	}
+	short := strings.Join(surname, "-") + " "
+	if m := initialsRegex.FindStringSubmatch(strings.Join(parts[1:], "")); m != nil {
+		short += strings.ToUpper(m[1]) + "."
+		if m[2] != "" {
+			short += strings.ToUpper(m[2]) + "."
+		}
+		return short, true
+	}
+	// Имя и отчество полностью
+	if len(parts) > 3 {
+		return "", false
+	}
+	for _, p := range parts[1:] {
+		if !namePartRegex.MatchString(p) {
+			return "", false
+		}
+		short += strings.ToUpper(string([]rune(p)[0])) + "."
+	}
+	return short, true
+}
@@ -0,0 +1,88 @@
+package validate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Подписи Telegram ---
+//
+// Mini App и виджет входа подписывают данные токеном бота, каждый своим
+// способом. Подпись старше ttl (по auth_date) не принимается.
+
+// Проверка initData Mini App; возвращает ID пользователя
+func InitData(initData, botToken string, now time.Time, ttl time.Duration) (int, bool) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, false
+	}
+	hash := values.Get("hash")
+	var pairs []string
+	for key := range values {
+		if key != "hash" {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(hash)) {
+		return 0, false
+	}
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > ttl {
+		return 0, false
+	}
+	var user struct {
+		ID int `json:"id"`
+	}
+	if json.Unmarshal([]byte(values.Get("user")), &user) != nil || user.ID == 0 {
+		return 0, false
+	}
+	return user.ID, true
+}
+
+// Проверка данных виджета Telegram Login; возвращает ID пользователя
+func Login(values map[string][]string, botToken string, now time.Time, ttl time.Duration) (int, bool) {
+	var pairs []string
+	hash := ""
+	for key, v := range values {
+		if len(v) == 0 {
+			continue
+		}
+		if key == "hash" {
+			hash = v[0]
+			continue
+		}
+		pairs = append(pairs, key+"="+v[0])
+	}
+	sort.Strings(pairs)
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	if hash == "" || !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(hash)) {
+		return 0, false
+	}
+	authDate, err := strconv.ParseInt(firstValue(values, "auth_date"), 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > ttl {
+		return 0, false
+	}
+	id, err := strconv.Atoi(firstValue(values, "id"))
+	return id, err == nil
+}
+
+func firstValue(values map[string][]string, key string) string {
+	if v := values[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tabel-go/internal/scheduler"
+)
+
+// --- Время и расписания ---
+
+var durationRe = regexp.MustCompile(`^(?:(\d+)\s*ч[а-я]*)?\s*(?:(\d+)\s*м[а-я]*)?$`)
+
+// «1ч», «4 часа», «1ч 30м», «90 мин», «1:30»; просто число — минуты
+func Duration(s string) (time.Duration, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, false
+	}
+	if m, err := strconv.Atoi(s); err == nil && m >= 0 {
+		return time.Duration(m) * time.Minute, true
+	}
+	if h, m, ok := strings.Cut(s, ":"); ok {
+		hh, err1 := strconv.Atoi(h)
+		mm, err2 := strconv.Atoi(m)
+		if err1 != nil || err2 != nil || hh < 0 || mm < 0 || mm > 59 {
+			return 0, false
+		}
+		return time.Duration(hh)*time.Hour + time.Duration(mm)*time.Minute, true
+	}
+	parts := durationRe.FindStringSubmatch(s)
+	if parts == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(parts[1])
+	m, _ := strconv.Atoi(parts[2])
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, true
+}
+
+// Разбор «ЧЧ:ММ-ЧЧ:ММ» в минуты от полуночи
+func QuietHours(s string) (start, end int, ok bool) {
+	parts := strings.Split(strings.ReplaceAll(s, " ", ""), "-")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	from, err1 := time.Parse("15:04", parts[0])
+	to, err2 := time.Parse("15:04", parts[1])
+	if err1 != nil || err2 != nil || parts[0] == parts[1] {
+		return 0, 0, false
+	}
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), true
+}
+
+// Дни недели по-русски для краткой записи времени
+var ruWeekdays = map[string]string{
+	"пн": "1", "вт": "2", "ср": "3", "чт": "4", "пт": "5", "сб": "6", "вс": "7",
+}
+
+// «21:00», «21:00 пн-пт», «09:30 пт,сб» или cron из пяти полей; возвращает
+// cron-выражение
+func Schedule(fields []string) (string, error) {
+	if len(fields) == 5 {
+		spec := strings.Join(fields, " ")
+		_, err := scheduler.ParseCron(spec)
+		return spec, err
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", fmt.Errorf("укажите время ЧЧ:ММ и, по желанию, дни недели")
+	}
+	t, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		return "", fmt.Errorf("время в формате ЧЧ:ММ")
+	}
+	dow := "*"
+	if len(fields) == 2 && fields[1] != "ежедневно" {
+		days := strings.ToLower(fields[1])
+		for ru, n := range ruWeekdays {
+			days = strings.ReplaceAll(days, ru, n)
+		}
+		dow = days
+	}
+	spec := fmt.Sprintf("%d %d * * %s", t.Minute(), t.Hour(), dow)
+	if _, err := scheduler.ParseCron(spec); err != nil {
+		return "", fmt.Errorf("дни недели: пн-пт, сб,вс или ежедневно")
+	}
+	return spec, nil
+}
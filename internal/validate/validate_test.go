@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestName(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"Иванов И.И.", "Иванов И.И."},
+		{"иванов и. и.", "Иванов И.И."},
+		{"Петров И.", "Петров И."},
+		{"сидоров иван петрович", "Сидоров И.П."},
+		{"РИМСКИЙ-КОРСАКОВ Н.А.", "Римский-Корсаков Н.А."},
+	}
+	for _, c := range cases {
+		if got, ok := Name(c.in); !ok || got != c.want {
+			t.Errorf("Name(%q) = %q, %v; ожидалось %q", c.in, got, ok, c.want)
+		}
+	}
+	for _, in := range []string{"", "Иванов", "Ivanov I.I.", "И И.И.", "Иванов Иван Иванович Младший", "Иванов 1.2."} {
+		if got, ok := Name(in); ok {
+			t.Errorf("Name(%q) = %q: должно быть отклонено", in, got)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90":       90 * time.Minute,
+		"1ч":       time.Hour,
+		"4 часа":   4 * time.Hour,
+		"1ч 30м":   90 * time.Minute,
+		"45 мин":   45 * time.Minute,
+		"1:30":     90 * time.Minute,
+		" 2 Часа ": 2 * time.Hour,
+	}
+	for in, want := range cases {
+		if got, ok := Duration(in); !ok || got != want {
+			t.Errorf("Duration(%q) = %s, %v; ожидалось %s", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "-5", "1:75", "час"} {
+		if got, ok := Duration(in); ok {
+			t.Errorf("Duration(%q) = %s: должно быть отклонено", in, got)
+		}
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	start, end, ok := QuietHours("22:00 - 07:30")
+	if !ok || start != 22*60 || end != 7*60+30 {
+		t.Errorf("QuietHours: %d-%d, %v", start, end, ok)
+	}
+	for _, in := range []string{"", "22:00", "22:00-22:00", "25:00-07:00"} {
+		if _, _, ok := QuietHours(in); ok {
+			t.Errorf("QuietHours(%q): должно быть отклонено", in)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"21:00", "0 21 * * *"},
+		{"09:30 пн-пт", "30 9 * * 1-5"},
+		{"09:30 пт,сб", "30 9 * * 5,6"},
+		{"08:00 ежедневно", "0 8 * * *"},
+		{"*/15 8-18 * * 1-5", "*/15 8-18 * * 1-5"},
+	}
+	for _, c := range cases {
+		if got, err := Schedule(strings.Fields(c.in)); err != nil || got != c.want {
+			t.Errorf("Schedule(%q) = %q, %v; ожидалось %q", c.in, got, err, c.want)
+		}
+	}
+	for _, in := range []string{"", "9 утра", "21:00 выходные", "60 * * * *"} {
+		if got, err := Schedule(strings.Fields(in)); err == nil {
+			t.Errorf("Schedule(%q) = %q: ошибки нет", in, got)
+		}
+	}
+}
+
+// initData, подписанные так, как подписывает Telegram
+func signInitData(token string, values url.Values) string {
+	var pairs []string
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(token))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values.Encode()
+}
+
+func TestInitData(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	values := func(authDate time.Time) url.Values {
+		return url.Values{
+			"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+			"user":      {`{"id":42,"first_name":"Иван"}`},
+		}
+	}
+	data := signInitData("token", values(now.Add(-time.Hour)))
+	if id, ok := InitData(data, "token", now, 24*time.Hour); !ok || id != 42 {
+		t.Errorf("верная подпись: %d, %v", id, ok)
+	}
+	if _, ok := InitData(data, "other", now, 24*time.Hour); ok {
+		t.Error("подпись чужим токеном принята")
+	}
+	if _, ok := InitData(strings.Replace(data, "42", "43", 1), "token", now, 24*time.Hour); ok {
+		t.Error("изменённые данные приняты")
+	}
+	old := signInitData("token", values(now.Add(-25*time.Hour)))
+	if _, ok := InitData(old, "token", now, 24*time.Hour); ok {
+		t.Error("просроченная подпись принята")
+	}
+}